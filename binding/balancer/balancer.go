@@ -0,0 +1,235 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package balancer
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// ExchangeProxySwap is an auto generated low-level Go binding around an user-defined struct.
+type ExchangeProxySwap struct {
+	Pool              common.Address
+	TokenIn           common.Address
+	TokenOut          common.Address
+	SwapAmount        *big.Int
+	LimitReturnAmount *big.Int
+	MaxPrice          *big.Int
+}
+
+// BalancerMetaData contains all meta data concerning the Balancer contract.
+var BalancerMetaData = &bind.MetaData{
+	ABI: "[{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"tokenIn\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"tokenOut\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"swapAmount\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"nPools\",\"type\":\"uint256\"}],\"name\":\"viewSplitExactIn\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"pool\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"tokenIn\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"tokenOut\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"swapAmount\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"limitReturnAmount\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"maxPrice\",\"type\":\"uint256\"}],\"internalType\":\"structExchangeProxy.Swap[]\",\"name\":\"swaps\",\"type\":\"tuple[]\"},{\"internalType\":\"uint256\",\"name\":\"totalOutput\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// BalancerABI is the input ABI used to generate the binding from.
+// Deprecated: Use BalancerMetaData.ABI instead.
+var BalancerABI = BalancerMetaData.ABI
+
+// Balancer is an auto generated Go binding around an Ethereum contract.
+type Balancer struct {
+	BalancerCaller     // Read-only binding to the contract
+	BalancerTransactor // Write-only binding to the contract
+	BalancerFilterer   // Log filterer for contract events
+}
+
+// BalancerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type BalancerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BalancerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type BalancerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BalancerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type BalancerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BalancerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type BalancerSession struct {
+	Contract     *Balancer         // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// BalancerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type BalancerCallerSession struct {
+	Contract *BalancerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts   // Call options to use throughout this session
+}
+
+// BalancerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type BalancerTransactorSession struct {
+	Contract     *BalancerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts   // Transaction auth options to use throughout this session
+}
+
+// BalancerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type BalancerRaw struct {
+	Contract *Balancer // Generic contract binding to access the raw methods on
+}
+
+// BalancerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type BalancerCallerRaw struct {
+	Contract *BalancerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// BalancerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type BalancerTransactorRaw struct {
+	Contract *BalancerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewBalancer creates a new instance of Balancer, bound to a specific deployed contract.
+func NewBalancer(address common.Address, backend bind.ContractBackend) (*Balancer, error) {
+	contract, err := bindBalancer(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Balancer{BalancerCaller: BalancerCaller{contract: contract}, BalancerTransactor: BalancerTransactor{contract: contract}, BalancerFilterer: BalancerFilterer{contract: contract}}, nil
+}
+
+// NewBalancerCaller creates a new read-only instance of Balancer, bound to a specific deployed contract.
+func NewBalancerCaller(address common.Address, caller bind.ContractCaller) (*BalancerCaller, error) {
+	contract, err := bindBalancer(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BalancerCaller{contract: contract}, nil
+}
+
+// NewBalancerTransactor creates a new write-only instance of Balancer, bound to a specific deployed contract.
+func NewBalancerTransactor(address common.Address, transactor bind.ContractTransactor) (*BalancerTransactor, error) {
+	contract, err := bindBalancer(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BalancerTransactor{contract: contract}, nil
+}
+
+// NewBalancerFilterer creates a new log filterer instance of Balancer, bound to a specific deployed contract.
+func NewBalancerFilterer(address common.Address, filterer bind.ContractFilterer) (*BalancerFilterer, error) {
+	contract, err := bindBalancer(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &BalancerFilterer{contract: contract}, nil
+}
+
+// bindBalancer binds a generic wrapper to an already deployed contract.
+func bindBalancer(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(BalancerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Balancer *BalancerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Balancer.Contract.BalancerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Balancer *BalancerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Balancer.Contract.BalancerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Balancer *BalancerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Balancer.Contract.BalancerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Balancer *BalancerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Balancer.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Balancer *BalancerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Balancer.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Balancer *BalancerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Balancer.Contract.contract.Transact(opts, method, params...)
+}
+
+// ViewSplitExactIn is a free data retrieval call binding the contract method 0x4b0f93fb.
+//
+// Solidity: function viewSplitExactIn(address tokenIn, address tokenOut, uint256 swapAmount, uint256 nPools) view returns((address,address,address,uint256,uint256,uint256)[] swaps, uint256 totalOutput)
+func (_Balancer *BalancerCaller) ViewSplitExactIn(opts *bind.CallOpts, tokenIn common.Address, tokenOut common.Address, swapAmount *big.Int, nPools *big.Int) (struct {
+	Swaps       []ExchangeProxySwap
+	TotalOutput *big.Int
+}, error) {
+	var out []interface{}
+	err := _Balancer.contract.Call(opts, &out, "viewSplitExactIn", tokenIn, tokenOut, swapAmount, nPools)
+
+	outstruct := new(struct {
+		Swaps       []ExchangeProxySwap
+		TotalOutput *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Swaps = *abi.ConvertType(out[0], new([]ExchangeProxySwap)).(*[]ExchangeProxySwap)
+	outstruct.TotalOutput = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// ViewSplitExactIn is a free data retrieval call binding the contract method 0x4b0f93fb.
+//
+// Solidity: function viewSplitExactIn(address tokenIn, address tokenOut, uint256 swapAmount, uint256 nPools) view returns((address,address,address,uint256,uint256,uint256)[] swaps, uint256 totalOutput)
+func (_Balancer *BalancerSession) ViewSplitExactIn(tokenIn common.Address, tokenOut common.Address, swapAmount *big.Int, nPools *big.Int) (struct {
+	Swaps       []ExchangeProxySwap
+	TotalOutput *big.Int
+}, error) {
+	return _Balancer.Contract.ViewSplitExactIn(&_Balancer.CallOpts, tokenIn, tokenOut, swapAmount, nPools)
+}
+
+// ViewSplitExactIn is a free data retrieval call binding the contract method 0x4b0f93fb.
+//
+// Solidity: function viewSplitExactIn(address tokenIn, address tokenOut, uint256 swapAmount, uint256 nPools) view returns((address,address,address,uint256,uint256,uint256)[] swaps, uint256 totalOutput)
+func (_Balancer *BalancerCallerSession) ViewSplitExactIn(tokenIn common.Address, tokenOut common.Address, swapAmount *big.Int, nPools *big.Int) (struct {
+	Swaps       []ExchangeProxySwap
+	TotalOutput *big.Int
+}, error) {
+	return _Balancer.Contract.ViewSplitExactIn(&_Balancer.CallOpts, tokenIn, tokenOut, swapAmount, nPools)
+}
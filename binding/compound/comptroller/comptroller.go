@@ -0,0 +1,873 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package comptroller
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// ComptrollerMetaData contains all meta data concerning the Comptroller contract.
+var ComptrollerMetaData = &bind.MetaData{
+	ABI: "[{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"contractCToken\",\"name\":\"cToken\",\"type\":\"address\"}],\"name\":\"checkMembership\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"internalType\":\"address\",\"name\":\"holder\",\"type\":\"address\"}],\"name\":\"claimComp\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"internalType\":\"address\",\"name\":\"holder\",\"type\":\"address\"},{\"internalType\":\"contractCToken[]\",\"name\":\"cTokens\",\"type\":\"address[]\"}],\"name\":\"claimComp\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"closeFactorMantissa\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"compAccrued\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"compBorrowState\",\"outputs\":[{\"internalType\":\"uint224\",\"name\":\"index\",\"type\":\"uint224\"},{\"internalType\":\"uint32\",\"name\":\"block\",\"type\":\"uint32\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"compBorrowerIndex\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"compInitialIndex\",\"outputs\":[{\"internalType\":\"uint224\",\"name\":\"\",\"type\":\"uint224\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"compRate\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"compSpeeds\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"compSupplierIndex\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"compSupplyState\",\"outputs\":[{\"internalType\":\"uint224\",\"name\":\"index\",\"type\":\"uint224\"},{\"internalType\":\"uint32\",\"name\":\"block\",\"type\":\"uint32\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"internalType\":\"contractCToken[]\",\"name\":\"cTokens\",\"type\":\"address[]\"}],\"name\":\"enterMarkets\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"internalType\":\"contractCToken\",\"name\":\"cTokenAddress\",\"type\":\"address\"}],\"name\":\"exitMarket\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getAccountLiquidity\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"getAllMarkets\",\"outputs\":[{\"internalType\":\"address[]\",\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getAssetsIn\",\"outputs\":[{\"internalType\":\"address[]\",\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"getCompAddress\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"contractCToken\",\"name\":\"cTokenModify\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"redeemTokens\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"borrowAmount\",\"type\":\"uint256\"}],\"name\":\"getHypotheticalAccountLiquidity\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"liquidationIncentiveMantissa\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"markets\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"isListed\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"collateralFactorMantissa\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"isComped\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"oracle\",\"outputs\":[{\"internalType\":\"contractPriceOracle\",\"name\":\"\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// ComptrollerABI is the input ABI used to generate the binding from.
+// Deprecated: Use ComptrollerMetaData.ABI instead.
+var ComptrollerABI = ComptrollerMetaData.ABI
+
+// Comptroller is an auto generated Go binding around an Ethereum contract.
+type Comptroller struct {
+	ComptrollerCaller     // Read-only binding to the contract
+	ComptrollerTransactor // Write-only binding to the contract
+	ComptrollerFilterer   // Log filterer for contract events
+}
+
+// ComptrollerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type ComptrollerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ComptrollerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ComptrollerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ComptrollerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ComptrollerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ComptrollerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ComptrollerSession struct {
+	Contract     *Comptroller      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ComptrollerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ComptrollerCallerSession struct {
+	Contract *ComptrollerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// ComptrollerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ComptrollerTransactorSession struct {
+	Contract     *ComptrollerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// ComptrollerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ComptrollerRaw struct {
+	Contract *Comptroller // Generic contract binding to access the raw methods on
+}
+
+// ComptrollerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ComptrollerCallerRaw struct {
+	Contract *ComptrollerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ComptrollerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ComptrollerTransactorRaw struct {
+	Contract *ComptrollerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewComptroller creates a new instance of Comptroller, bound to a specific deployed contract.
+func NewComptroller(address common.Address, backend bind.ContractBackend) (*Comptroller, error) {
+	contract, err := bindComptroller(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Comptroller{ComptrollerCaller: ComptrollerCaller{contract: contract}, ComptrollerTransactor: ComptrollerTransactor{contract: contract}, ComptrollerFilterer: ComptrollerFilterer{contract: contract}}, nil
+}
+
+// NewComptrollerCaller creates a new read-only instance of Comptroller, bound to a specific deployed contract.
+func NewComptrollerCaller(address common.Address, caller bind.ContractCaller) (*ComptrollerCaller, error) {
+	contract, err := bindComptroller(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerCaller{contract: contract}, nil
+}
+
+// NewComptrollerTransactor creates a new write-only instance of Comptroller, bound to a specific deployed contract.
+func NewComptrollerTransactor(address common.Address, transactor bind.ContractTransactor) (*ComptrollerTransactor, error) {
+	contract, err := bindComptroller(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerTransactor{contract: contract}, nil
+}
+
+// NewComptrollerFilterer creates a new log filterer instance of Comptroller, bound to a specific deployed contract.
+func NewComptrollerFilterer(address common.Address, filterer bind.ContractFilterer) (*ComptrollerFilterer, error) {
+	contract, err := bindComptroller(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ComptrollerFilterer{contract: contract}, nil
+}
+
+// bindComptroller binds a generic wrapper to an already deployed contract.
+func bindComptroller(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(ComptrollerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Comptroller *ComptrollerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Comptroller.Contract.ComptrollerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Comptroller *ComptrollerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Comptroller.Contract.ComptrollerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Comptroller *ComptrollerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Comptroller.Contract.ComptrollerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Comptroller *ComptrollerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Comptroller.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Comptroller *ComptrollerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Comptroller.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Comptroller *ComptrollerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Comptroller.Contract.contract.Transact(opts, method, params...)
+}
+
+// CheckMembership is a free data retrieval call binding the contract method 0x929fe9a1.
+//
+// Solidity: function checkMembership(address account, address cToken) view returns(bool)
+func (_Comptroller *ComptrollerCaller) CheckMembership(opts *bind.CallOpts, account common.Address, cToken common.Address) (bool, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "checkMembership", account, cToken)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// CheckMembership is a free data retrieval call binding the contract method 0x929fe9a1.
+//
+// Solidity: function checkMembership(address account, address cToken) view returns(bool)
+func (_Comptroller *ComptrollerSession) CheckMembership(account common.Address, cToken common.Address) (bool, error) {
+	return _Comptroller.Contract.CheckMembership(&_Comptroller.CallOpts, account, cToken)
+}
+
+// CheckMembership is a free data retrieval call binding the contract method 0x929fe9a1.
+//
+// Solidity: function checkMembership(address account, address cToken) view returns(bool)
+func (_Comptroller *ComptrollerCallerSession) CheckMembership(account common.Address, cToken common.Address) (bool, error) {
+	return _Comptroller.Contract.CheckMembership(&_Comptroller.CallOpts, account, cToken)
+}
+
+// CloseFactorMantissa is a free data retrieval call binding the contract method 0xe8755446.
+//
+// Solidity: function closeFactorMantissa() view returns(uint256)
+func (_Comptroller *ComptrollerCaller) CloseFactorMantissa(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "closeFactorMantissa")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CloseFactorMantissa is a free data retrieval call binding the contract method 0xe8755446.
+//
+// Solidity: function closeFactorMantissa() view returns(uint256)
+func (_Comptroller *ComptrollerSession) CloseFactorMantissa() (*big.Int, error) {
+	return _Comptroller.Contract.CloseFactorMantissa(&_Comptroller.CallOpts)
+}
+
+// CloseFactorMantissa is a free data retrieval call binding the contract method 0xe8755446.
+//
+// Solidity: function closeFactorMantissa() view returns(uint256)
+func (_Comptroller *ComptrollerCallerSession) CloseFactorMantissa() (*big.Int, error) {
+	return _Comptroller.Contract.CloseFactorMantissa(&_Comptroller.CallOpts)
+}
+
+// CompAccrued is a free data retrieval call binding the contract method 0xcc7ebdc4.
+//
+// Solidity: function compAccrued(address ) view returns(uint256)
+func (_Comptroller *ComptrollerCaller) CompAccrued(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compAccrued", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CompAccrued is a free data retrieval call binding the contract method 0xcc7ebdc4.
+//
+// Solidity: function compAccrued(address ) view returns(uint256)
+func (_Comptroller *ComptrollerSession) CompAccrued(arg0 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompAccrued(&_Comptroller.CallOpts, arg0)
+}
+
+// CompAccrued is a free data retrieval call binding the contract method 0xcc7ebdc4.
+//
+// Solidity: function compAccrued(address ) view returns(uint256)
+func (_Comptroller *ComptrollerCallerSession) CompAccrued(arg0 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompAccrued(&_Comptroller.CallOpts, arg0)
+}
+
+// CompBorrowState is a free data retrieval call binding the contract method 0x8c57804e.
+//
+// Solidity: function compBorrowState(address ) view returns(uint224 index, uint32 block)
+func (_Comptroller *ComptrollerCaller) CompBorrowState(opts *bind.CallOpts, arg0 common.Address) (struct {
+	Index *big.Int
+	Block uint32
+}, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compBorrowState", arg0)
+
+	outstruct := new(struct {
+		Index *big.Int
+		Block uint32
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Index = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.Block = *abi.ConvertType(out[1], new(uint32)).(*uint32)
+
+	return *outstruct, err
+
+}
+
+// CompBorrowState is a free data retrieval call binding the contract method 0x8c57804e.
+//
+// Solidity: function compBorrowState(address ) view returns(uint224 index, uint32 block)
+func (_Comptroller *ComptrollerSession) CompBorrowState(arg0 common.Address) (struct {
+	Index *big.Int
+	Block uint32
+}, error) {
+	return _Comptroller.Contract.CompBorrowState(&_Comptroller.CallOpts, arg0)
+}
+
+// CompBorrowState is a free data retrieval call binding the contract method 0x8c57804e.
+//
+// Solidity: function compBorrowState(address ) view returns(uint224 index, uint32 block)
+func (_Comptroller *ComptrollerCallerSession) CompBorrowState(arg0 common.Address) (struct {
+	Index *big.Int
+	Block uint32
+}, error) {
+	return _Comptroller.Contract.CompBorrowState(&_Comptroller.CallOpts, arg0)
+}
+
+// CompBorrowerIndex is a free data retrieval call binding the contract method 0xca0af043.
+//
+// Solidity: function compBorrowerIndex(address , address ) view returns(uint256)
+func (_Comptroller *ComptrollerCaller) CompBorrowerIndex(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compBorrowerIndex", arg0, arg1)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CompBorrowerIndex is a free data retrieval call binding the contract method 0xca0af043.
+//
+// Solidity: function compBorrowerIndex(address , address ) view returns(uint256)
+func (_Comptroller *ComptrollerSession) CompBorrowerIndex(arg0 common.Address, arg1 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompBorrowerIndex(&_Comptroller.CallOpts, arg0, arg1)
+}
+
+// CompBorrowerIndex is a free data retrieval call binding the contract method 0xca0af043.
+//
+// Solidity: function compBorrowerIndex(address , address ) view returns(uint256)
+func (_Comptroller *ComptrollerCallerSession) CompBorrowerIndex(arg0 common.Address, arg1 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompBorrowerIndex(&_Comptroller.CallOpts, arg0, arg1)
+}
+
+// CompInitialIndex is a free data retrieval call binding the contract method 0xa7f0e231.
+//
+// Solidity: function compInitialIndex() view returns(uint224)
+func (_Comptroller *ComptrollerCaller) CompInitialIndex(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compInitialIndex")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CompInitialIndex is a free data retrieval call binding the contract method 0xa7f0e231.
+//
+// Solidity: function compInitialIndex() view returns(uint224)
+func (_Comptroller *ComptrollerSession) CompInitialIndex() (*big.Int, error) {
+	return _Comptroller.Contract.CompInitialIndex(&_Comptroller.CallOpts)
+}
+
+// CompInitialIndex is a free data retrieval call binding the contract method 0xa7f0e231.
+//
+// Solidity: function compInitialIndex() view returns(uint224)
+func (_Comptroller *ComptrollerCallerSession) CompInitialIndex() (*big.Int, error) {
+	return _Comptroller.Contract.CompInitialIndex(&_Comptroller.CallOpts)
+}
+
+// CompRate is a free data retrieval call binding the contract method 0xaa900754.
+//
+// Solidity: function compRate() view returns(uint256)
+func (_Comptroller *ComptrollerCaller) CompRate(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compRate")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CompRate is a free data retrieval call binding the contract method 0xaa900754.
+//
+// Solidity: function compRate() view returns(uint256)
+func (_Comptroller *ComptrollerSession) CompRate() (*big.Int, error) {
+	return _Comptroller.Contract.CompRate(&_Comptroller.CallOpts)
+}
+
+// CompRate is a free data retrieval call binding the contract method 0xaa900754.
+//
+// Solidity: function compRate() view returns(uint256)
+func (_Comptroller *ComptrollerCallerSession) CompRate() (*big.Int, error) {
+	return _Comptroller.Contract.CompRate(&_Comptroller.CallOpts)
+}
+
+// CompSpeeds is a free data retrieval call binding the contract method 0x1d7b33d7.
+//
+// Solidity: function compSpeeds(address ) view returns(uint256)
+func (_Comptroller *ComptrollerCaller) CompSpeeds(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compSpeeds", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CompSpeeds is a free data retrieval call binding the contract method 0x1d7b33d7.
+//
+// Solidity: function compSpeeds(address ) view returns(uint256)
+func (_Comptroller *ComptrollerSession) CompSpeeds(arg0 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompSpeeds(&_Comptroller.CallOpts, arg0)
+}
+
+// CompSpeeds is a free data retrieval call binding the contract method 0x1d7b33d7.
+//
+// Solidity: function compSpeeds(address ) view returns(uint256)
+func (_Comptroller *ComptrollerCallerSession) CompSpeeds(arg0 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompSpeeds(&_Comptroller.CallOpts, arg0)
+}
+
+// CompSupplierIndex is a free data retrieval call binding the contract method 0xb21be7fd.
+//
+// Solidity: function compSupplierIndex(address , address ) view returns(uint256)
+func (_Comptroller *ComptrollerCaller) CompSupplierIndex(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compSupplierIndex", arg0, arg1)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CompSupplierIndex is a free data retrieval call binding the contract method 0xb21be7fd.
+//
+// Solidity: function compSupplierIndex(address , address ) view returns(uint256)
+func (_Comptroller *ComptrollerSession) CompSupplierIndex(arg0 common.Address, arg1 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompSupplierIndex(&_Comptroller.CallOpts, arg0, arg1)
+}
+
+// CompSupplierIndex is a free data retrieval call binding the contract method 0xb21be7fd.
+//
+// Solidity: function compSupplierIndex(address , address ) view returns(uint256)
+func (_Comptroller *ComptrollerCallerSession) CompSupplierIndex(arg0 common.Address, arg1 common.Address) (*big.Int, error) {
+	return _Comptroller.Contract.CompSupplierIndex(&_Comptroller.CallOpts, arg0, arg1)
+}
+
+// CompSupplyState is a free data retrieval call binding the contract method 0x6b79c38d.
+//
+// Solidity: function compSupplyState(address ) view returns(uint224 index, uint32 block)
+func (_Comptroller *ComptrollerCaller) CompSupplyState(opts *bind.CallOpts, arg0 common.Address) (struct {
+	Index *big.Int
+	Block uint32
+}, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "compSupplyState", arg0)
+
+	outstruct := new(struct {
+		Index *big.Int
+		Block uint32
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Index = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.Block = *abi.ConvertType(out[1], new(uint32)).(*uint32)
+
+	return *outstruct, err
+
+}
+
+// CompSupplyState is a free data retrieval call binding the contract method 0x6b79c38d.
+//
+// Solidity: function compSupplyState(address ) view returns(uint224 index, uint32 block)
+func (_Comptroller *ComptrollerSession) CompSupplyState(arg0 common.Address) (struct {
+	Index *big.Int
+	Block uint32
+}, error) {
+	return _Comptroller.Contract.CompSupplyState(&_Comptroller.CallOpts, arg0)
+}
+
+// CompSupplyState is a free data retrieval call binding the contract method 0x6b79c38d.
+//
+// Solidity: function compSupplyState(address ) view returns(uint224 index, uint32 block)
+func (_Comptroller *ComptrollerCallerSession) CompSupplyState(arg0 common.Address) (struct {
+	Index *big.Int
+	Block uint32
+}, error) {
+	return _Comptroller.Contract.CompSupplyState(&_Comptroller.CallOpts, arg0)
+}
+
+// GetAccountLiquidity is a free data retrieval call binding the contract method 0x5ec88c79.
+//
+// Solidity: function getAccountLiquidity(address account) view returns(uint256, uint256, uint256)
+func (_Comptroller *ComptrollerCaller) GetAccountLiquidity(opts *bind.CallOpts, account common.Address) (*big.Int, *big.Int, *big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "getAccountLiquidity", account)
+
+	if err != nil {
+		return *new(*big.Int), *new(*big.Int), *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	out1 := *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	out2 := *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+
+	return out0, out1, out2, err
+
+}
+
+// GetAccountLiquidity is a free data retrieval call binding the contract method 0x5ec88c79.
+//
+// Solidity: function getAccountLiquidity(address account) view returns(uint256, uint256, uint256)
+func (_Comptroller *ComptrollerSession) GetAccountLiquidity(account common.Address) (*big.Int, *big.Int, *big.Int, error) {
+	return _Comptroller.Contract.GetAccountLiquidity(&_Comptroller.CallOpts, account)
+}
+
+// GetAccountLiquidity is a free data retrieval call binding the contract method 0x5ec88c79.
+//
+// Solidity: function getAccountLiquidity(address account) view returns(uint256, uint256, uint256)
+func (_Comptroller *ComptrollerCallerSession) GetAccountLiquidity(account common.Address) (*big.Int, *big.Int, *big.Int, error) {
+	return _Comptroller.Contract.GetAccountLiquidity(&_Comptroller.CallOpts, account)
+}
+
+// GetAllMarkets is a free data retrieval call binding the contract method 0xb0772d0b.
+//
+// Solidity: function getAllMarkets() view returns(address[])
+func (_Comptroller *ComptrollerCaller) GetAllMarkets(opts *bind.CallOpts) ([]common.Address, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "getAllMarkets")
+
+	if err != nil {
+		return *new([]common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]common.Address)).(*[]common.Address)
+
+	return out0, err
+
+}
+
+// GetAllMarkets is a free data retrieval call binding the contract method 0xb0772d0b.
+//
+// Solidity: function getAllMarkets() view returns(address[])
+func (_Comptroller *ComptrollerSession) GetAllMarkets() ([]common.Address, error) {
+	return _Comptroller.Contract.GetAllMarkets(&_Comptroller.CallOpts)
+}
+
+// GetAllMarkets is a free data retrieval call binding the contract method 0xb0772d0b.
+//
+// Solidity: function getAllMarkets() view returns(address[])
+func (_Comptroller *ComptrollerCallerSession) GetAllMarkets() ([]common.Address, error) {
+	return _Comptroller.Contract.GetAllMarkets(&_Comptroller.CallOpts)
+}
+
+// GetAssetsIn is a free data retrieval call binding the contract method 0xabfceffc.
+//
+// Solidity: function getAssetsIn(address account) view returns(address[])
+func (_Comptroller *ComptrollerCaller) GetAssetsIn(opts *bind.CallOpts, account common.Address) ([]common.Address, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "getAssetsIn", account)
+
+	if err != nil {
+		return *new([]common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]common.Address)).(*[]common.Address)
+
+	return out0, err
+
+}
+
+// GetAssetsIn is a free data retrieval call binding the contract method 0xabfceffc.
+//
+// Solidity: function getAssetsIn(address account) view returns(address[])
+func (_Comptroller *ComptrollerSession) GetAssetsIn(account common.Address) ([]common.Address, error) {
+	return _Comptroller.Contract.GetAssetsIn(&_Comptroller.CallOpts, account)
+}
+
+// GetAssetsIn is a free data retrieval call binding the contract method 0xabfceffc.
+//
+// Solidity: function getAssetsIn(address account) view returns(address[])
+func (_Comptroller *ComptrollerCallerSession) GetAssetsIn(account common.Address) ([]common.Address, error) {
+	return _Comptroller.Contract.GetAssetsIn(&_Comptroller.CallOpts, account)
+}
+
+// GetCompAddress is a free data retrieval call binding the contract method 0x9d1b5a0a.
+//
+// Solidity: function getCompAddress() view returns(address)
+func (_Comptroller *ComptrollerCaller) GetCompAddress(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "getCompAddress")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// GetCompAddress is a free data retrieval call binding the contract method 0x9d1b5a0a.
+//
+// Solidity: function getCompAddress() view returns(address)
+func (_Comptroller *ComptrollerSession) GetCompAddress() (common.Address, error) {
+	return _Comptroller.Contract.GetCompAddress(&_Comptroller.CallOpts)
+}
+
+// GetCompAddress is a free data retrieval call binding the contract method 0x9d1b5a0a.
+//
+// Solidity: function getCompAddress() view returns(address)
+func (_Comptroller *ComptrollerCallerSession) GetCompAddress() (common.Address, error) {
+	return _Comptroller.Contract.GetCompAddress(&_Comptroller.CallOpts)
+}
+
+// GetHypotheticalAccountLiquidity is a free data retrieval call binding the contract method 0x4e79238f.
+//
+// Solidity: function getHypotheticalAccountLiquidity(address account, address cTokenModify, uint256 redeemTokens, uint256 borrowAmount) view returns(uint256, uint256, uint256)
+func (_Comptroller *ComptrollerCaller) GetHypotheticalAccountLiquidity(opts *bind.CallOpts, account common.Address, cTokenModify common.Address, redeemTokens *big.Int, borrowAmount *big.Int) (*big.Int, *big.Int, *big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "getHypotheticalAccountLiquidity", account, cTokenModify, redeemTokens, borrowAmount)
+
+	if err != nil {
+		return *new(*big.Int), *new(*big.Int), *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	out1 := *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	out2 := *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+
+	return out0, out1, out2, err
+
+}
+
+// GetHypotheticalAccountLiquidity is a free data retrieval call binding the contract method 0x4e79238f.
+//
+// Solidity: function getHypotheticalAccountLiquidity(address account, address cTokenModify, uint256 redeemTokens, uint256 borrowAmount) view returns(uint256, uint256, uint256)
+func (_Comptroller *ComptrollerSession) GetHypotheticalAccountLiquidity(account common.Address, cTokenModify common.Address, redeemTokens *big.Int, borrowAmount *big.Int) (*big.Int, *big.Int, *big.Int, error) {
+	return _Comptroller.Contract.GetHypotheticalAccountLiquidity(&_Comptroller.CallOpts, account, cTokenModify, redeemTokens, borrowAmount)
+}
+
+// GetHypotheticalAccountLiquidity is a free data retrieval call binding the contract method 0x4e79238f.
+//
+// Solidity: function getHypotheticalAccountLiquidity(address account, address cTokenModify, uint256 redeemTokens, uint256 borrowAmount) view returns(uint256, uint256, uint256)
+func (_Comptroller *ComptrollerCallerSession) GetHypotheticalAccountLiquidity(account common.Address, cTokenModify common.Address, redeemTokens *big.Int, borrowAmount *big.Int) (*big.Int, *big.Int, *big.Int, error) {
+	return _Comptroller.Contract.GetHypotheticalAccountLiquidity(&_Comptroller.CallOpts, account, cTokenModify, redeemTokens, borrowAmount)
+}
+
+// LiquidationIncentiveMantissa is a free data retrieval call binding the contract method 0x4ada90af.
+//
+// Solidity: function liquidationIncentiveMantissa() view returns(uint256)
+func (_Comptroller *ComptrollerCaller) LiquidationIncentiveMantissa(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "liquidationIncentiveMantissa")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// LiquidationIncentiveMantissa is a free data retrieval call binding the contract method 0x4ada90af.
+//
+// Solidity: function liquidationIncentiveMantissa() view returns(uint256)
+func (_Comptroller *ComptrollerSession) LiquidationIncentiveMantissa() (*big.Int, error) {
+	return _Comptroller.Contract.LiquidationIncentiveMantissa(&_Comptroller.CallOpts)
+}
+
+// LiquidationIncentiveMantissa is a free data retrieval call binding the contract method 0x4ada90af.
+//
+// Solidity: function liquidationIncentiveMantissa() view returns(uint256)
+func (_Comptroller *ComptrollerCallerSession) LiquidationIncentiveMantissa() (*big.Int, error) {
+	return _Comptroller.Contract.LiquidationIncentiveMantissa(&_Comptroller.CallOpts)
+}
+
+// Markets is a free data retrieval call binding the contract method 0x8e8f294b.
+//
+// Solidity: function markets(address ) view returns(bool isListed, uint256 collateralFactorMantissa, bool isComped)
+func (_Comptroller *ComptrollerCaller) Markets(opts *bind.CallOpts, arg0 common.Address) (struct {
+	IsListed                 bool
+	CollateralFactorMantissa *big.Int
+	IsComped                 bool
+}, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "markets", arg0)
+
+	outstruct := new(struct {
+		IsListed                 bool
+		CollateralFactorMantissa *big.Int
+		IsComped                 bool
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.IsListed = *abi.ConvertType(out[0], new(bool)).(*bool)
+	outstruct.CollateralFactorMantissa = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.IsComped = *abi.ConvertType(out[2], new(bool)).(*bool)
+
+	return *outstruct, err
+
+}
+
+// Markets is a free data retrieval call binding the contract method 0x8e8f294b.
+//
+// Solidity: function markets(address ) view returns(bool isListed, uint256 collateralFactorMantissa, bool isComped)
+func (_Comptroller *ComptrollerSession) Markets(arg0 common.Address) (struct {
+	IsListed                 bool
+	CollateralFactorMantissa *big.Int
+	IsComped                 bool
+}, error) {
+	return _Comptroller.Contract.Markets(&_Comptroller.CallOpts, arg0)
+}
+
+// Markets is a free data retrieval call binding the contract method 0x8e8f294b.
+//
+// Solidity: function markets(address ) view returns(bool isListed, uint256 collateralFactorMantissa, bool isComped)
+func (_Comptroller *ComptrollerCallerSession) Markets(arg0 common.Address) (struct {
+	IsListed                 bool
+	CollateralFactorMantissa *big.Int
+	IsComped                 bool
+}, error) {
+	return _Comptroller.Contract.Markets(&_Comptroller.CallOpts, arg0)
+}
+
+// Oracle is a free data retrieval call binding the contract method 0x7dc0d1d0.
+//
+// Solidity: function oracle() view returns(address)
+func (_Comptroller *ComptrollerCaller) Oracle(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Comptroller.contract.Call(opts, &out, "oracle")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Oracle is a free data retrieval call binding the contract method 0x7dc0d1d0.
+//
+// Solidity: function oracle() view returns(address)
+func (_Comptroller *ComptrollerSession) Oracle() (common.Address, error) {
+	return _Comptroller.Contract.Oracle(&_Comptroller.CallOpts)
+}
+
+// Oracle is a free data retrieval call binding the contract method 0x7dc0d1d0.
+//
+// Solidity: function oracle() view returns(address)
+func (_Comptroller *ComptrollerCallerSession) Oracle() (common.Address, error) {
+	return _Comptroller.Contract.Oracle(&_Comptroller.CallOpts)
+}
+
+// ClaimComp is a paid mutator transaction binding the contract method 0xe9af0292.
+//
+// Solidity: function claimComp(address holder) returns()
+func (_Comptroller *ComptrollerTransactor) ClaimComp(opts *bind.TransactOpts, holder common.Address) (*types.Transaction, error) {
+	return _Comptroller.contract.Transact(opts, "claimComp", holder)
+}
+
+// ClaimComp is a paid mutator transaction binding the contract method 0xe9af0292.
+//
+// Solidity: function claimComp(address holder) returns()
+func (_Comptroller *ComptrollerSession) ClaimComp(holder common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.ClaimComp(&_Comptroller.TransactOpts, holder)
+}
+
+// ClaimComp is a paid mutator transaction binding the contract method 0xe9af0292.
+//
+// Solidity: function claimComp(address holder) returns()
+func (_Comptroller *ComptrollerTransactorSession) ClaimComp(holder common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.ClaimComp(&_Comptroller.TransactOpts, holder)
+}
+
+// ClaimComp0 is a paid mutator transaction binding the contract method 0x1c3db2e0.
+//
+// Solidity: function claimComp(address holder, address[] cTokens) returns()
+func (_Comptroller *ComptrollerTransactor) ClaimComp0(opts *bind.TransactOpts, holder common.Address, cTokens []common.Address) (*types.Transaction, error) {
+	return _Comptroller.contract.Transact(opts, "claimComp0", holder, cTokens)
+}
+
+// ClaimComp0 is a paid mutator transaction binding the contract method 0x1c3db2e0.
+//
+// Solidity: function claimComp(address holder, address[] cTokens) returns()
+func (_Comptroller *ComptrollerSession) ClaimComp0(holder common.Address, cTokens []common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.ClaimComp0(&_Comptroller.TransactOpts, holder, cTokens)
+}
+
+// ClaimComp0 is a paid mutator transaction binding the contract method 0x1c3db2e0.
+//
+// Solidity: function claimComp(address holder, address[] cTokens) returns()
+func (_Comptroller *ComptrollerTransactorSession) ClaimComp0(holder common.Address, cTokens []common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.ClaimComp0(&_Comptroller.TransactOpts, holder, cTokens)
+}
+
+// EnterMarkets is a paid mutator transaction binding the contract method 0xc2998238.
+//
+// Solidity: function enterMarkets(address[] cTokens) returns(uint256[])
+func (_Comptroller *ComptrollerTransactor) EnterMarkets(opts *bind.TransactOpts, cTokens []common.Address) (*types.Transaction, error) {
+	return _Comptroller.contract.Transact(opts, "enterMarkets", cTokens)
+}
+
+// EnterMarkets is a paid mutator transaction binding the contract method 0xc2998238.
+//
+// Solidity: function enterMarkets(address[] cTokens) returns(uint256[])
+func (_Comptroller *ComptrollerSession) EnterMarkets(cTokens []common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.EnterMarkets(&_Comptroller.TransactOpts, cTokens)
+}
+
+// EnterMarkets is a paid mutator transaction binding the contract method 0xc2998238.
+//
+// Solidity: function enterMarkets(address[] cTokens) returns(uint256[])
+func (_Comptroller *ComptrollerTransactorSession) EnterMarkets(cTokens []common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.EnterMarkets(&_Comptroller.TransactOpts, cTokens)
+}
+
+// ExitMarket is a paid mutator transaction binding the contract method 0xede4edd0.
+//
+// Solidity: function exitMarket(address cTokenAddress) returns(uint256)
+func (_Comptroller *ComptrollerTransactor) ExitMarket(opts *bind.TransactOpts, cTokenAddress common.Address) (*types.Transaction, error) {
+	return _Comptroller.contract.Transact(opts, "exitMarket", cTokenAddress)
+}
+
+// ExitMarket is a paid mutator transaction binding the contract method 0xede4edd0.
+//
+// Solidity: function exitMarket(address cTokenAddress) returns(uint256)
+func (_Comptroller *ComptrollerSession) ExitMarket(cTokenAddress common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.ExitMarket(&_Comptroller.TransactOpts, cTokenAddress)
+}
+
+// ExitMarket is a paid mutator transaction binding the contract method 0xede4edd0.
+//
+// Solidity: function exitMarket(address cTokenAddress) returns(uint256)
+func (_Comptroller *ComptrollerTransactorSession) ExitMarket(cTokenAddress common.Address) (*types.Transaction, error) {
+	return _Comptroller.Contract.ExitMarket(&_Comptroller.TransactOpts, cTokenAddress)
+}
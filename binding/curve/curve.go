@@ -0,0 +1,273 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package curve
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// CurveMetaData contains all meta data concerning the Curve contract.
+var CurveMetaData = &bind.MetaData{
+	ABI: "[{\"name\":\"get_dy\",\"outputs\":[{\"type\":\"uint256\",\"name\":\"\"}],\"inputs\":[{\"type\":\"int128\",\"name\":\"i\"},{\"type\":\"int128\",\"name\":\"j\"},{\"type\":\"uint256\",\"name\":\"dx\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"name\":\"get_dy_underlying\",\"outputs\":[{\"type\":\"uint256\",\"name\":\"\"}],\"inputs\":[{\"type\":\"int128\",\"name\":\"i\"},{\"type\":\"int128\",\"name\":\"j\"},{\"type\":\"uint256\",\"name\":\"dx\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"name\":\"coins\",\"outputs\":[{\"type\":\"address\",\"name\":\"\"}],\"inputs\":[{\"type\":\"int128\",\"name\":\"arg0\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// CurveABI is the input ABI used to generate the binding from.
+// Deprecated: Use CurveMetaData.ABI instead.
+var CurveABI = CurveMetaData.ABI
+
+// Curve is an auto generated Go binding around an Ethereum contract.
+type Curve struct {
+	CurveCaller     // Read-only binding to the contract
+	CurveTransactor // Write-only binding to the contract
+	CurveFilterer   // Log filterer for contract events
+}
+
+// CurveCaller is an auto generated read-only Go binding around an Ethereum contract.
+type CurveCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CurveTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type CurveTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CurveFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type CurveFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CurveSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type CurveSession struct {
+	Contract     *Curve            // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// CurveCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type CurveCallerSession struct {
+	Contract *CurveCaller  // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// CurveTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type CurveTransactorSession struct {
+	Contract     *CurveTransactor  // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// CurveRaw is an auto generated low-level Go binding around an Ethereum contract.
+type CurveRaw struct {
+	Contract *Curve // Generic contract binding to access the raw methods on
+}
+
+// CurveCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type CurveCallerRaw struct {
+	Contract *CurveCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// CurveTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type CurveTransactorRaw struct {
+	Contract *CurveTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewCurve creates a new instance of Curve, bound to a specific deployed contract.
+func NewCurve(address common.Address, backend bind.ContractBackend) (*Curve, error) {
+	contract, err := bindCurve(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Curve{CurveCaller: CurveCaller{contract: contract}, CurveTransactor: CurveTransactor{contract: contract}, CurveFilterer: CurveFilterer{contract: contract}}, nil
+}
+
+// NewCurveCaller creates a new read-only instance of Curve, bound to a specific deployed contract.
+func NewCurveCaller(address common.Address, caller bind.ContractCaller) (*CurveCaller, error) {
+	contract, err := bindCurve(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CurveCaller{contract: contract}, nil
+}
+
+// NewCurveTransactor creates a new write-only instance of Curve, bound to a specific deployed contract.
+func NewCurveTransactor(address common.Address, transactor bind.ContractTransactor) (*CurveTransactor, error) {
+	contract, err := bindCurve(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CurveTransactor{contract: contract}, nil
+}
+
+// NewCurveFilterer creates a new log filterer instance of Curve, bound to a specific deployed contract.
+func NewCurveFilterer(address common.Address, filterer bind.ContractFilterer) (*CurveFilterer, error) {
+	contract, err := bindCurve(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &CurveFilterer{contract: contract}, nil
+}
+
+// bindCurve binds a generic wrapper to an already deployed contract.
+func bindCurve(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(CurveABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Curve *CurveRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Curve.Contract.CurveCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Curve *CurveRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Curve.Contract.CurveTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Curve *CurveRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Curve.Contract.CurveTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Curve *CurveCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Curve.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Curve *CurveTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Curve.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Curve *CurveTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Curve.Contract.contract.Transact(opts, method, params...)
+}
+
+// Coins is a free data retrieval call binding the contract method 0x23746eb8.
+//
+// Solidity: function coins(int128 arg0) view returns(address)
+func (_Curve *CurveCaller) Coins(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _Curve.contract.Call(opts, &out, "coins", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Coins is a free data retrieval call binding the contract method 0x23746eb8.
+//
+// Solidity: function coins(int128 arg0) view returns(address)
+func (_Curve *CurveSession) Coins(arg0 *big.Int) (common.Address, error) {
+	return _Curve.Contract.Coins(&_Curve.CallOpts, arg0)
+}
+
+// Coins is a free data retrieval call binding the contract method 0x23746eb8.
+//
+// Solidity: function coins(int128 arg0) view returns(address)
+func (_Curve *CurveCallerSession) Coins(arg0 *big.Int) (common.Address, error) {
+	return _Curve.Contract.Coins(&_Curve.CallOpts, arg0)
+}
+
+// GetDy is a free data retrieval call binding the contract method 0x5e0d443f.
+//
+// Solidity: function get_dy(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (_Curve *CurveCaller) GetDy(opts *bind.CallOpts, i *big.Int, j *big.Int, dx *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _Curve.contract.Call(opts, &out, "get_dy", i, j, dx)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetDy is a free data retrieval call binding the contract method 0x5e0d443f.
+//
+// Solidity: function get_dy(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (_Curve *CurveSession) GetDy(i *big.Int, j *big.Int, dx *big.Int) (*big.Int, error) {
+	return _Curve.Contract.GetDy(&_Curve.CallOpts, i, j, dx)
+}
+
+// GetDy is a free data retrieval call binding the contract method 0x5e0d443f.
+//
+// Solidity: function get_dy(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (_Curve *CurveCallerSession) GetDy(i *big.Int, j *big.Int, dx *big.Int) (*big.Int, error) {
+	return _Curve.Contract.GetDy(&_Curve.CallOpts, i, j, dx)
+}
+
+// GetDyUnderlying is a free data retrieval call binding the contract method 0x07211ef7.
+//
+// Solidity: function get_dy_underlying(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (_Curve *CurveCaller) GetDyUnderlying(opts *bind.CallOpts, i *big.Int, j *big.Int, dx *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _Curve.contract.Call(opts, &out, "get_dy_underlying", i, j, dx)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetDyUnderlying is a free data retrieval call binding the contract method 0x07211ef7.
+//
+// Solidity: function get_dy_underlying(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (_Curve *CurveSession) GetDyUnderlying(i *big.Int, j *big.Int, dx *big.Int) (*big.Int, error) {
+	return _Curve.Contract.GetDyUnderlying(&_Curve.CallOpts, i, j, dx)
+}
+
+// GetDyUnderlying is a free data retrieval call binding the contract method 0x07211ef7.
+//
+// Solidity: function get_dy_underlying(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (_Curve *CurveCallerSession) GetDyUnderlying(i *big.Int, j *big.Int, dx *big.Int) (*big.Int, error) {
+	return _Curve.Contract.GetDyUnderlying(&_Curve.CallOpts, i, j, dx)
+}
@@ -0,0 +1,243 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package hcomptroller
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// HcomptrollerMetaData contains all meta data concerning the Hcomptroller contract.
+var HcomptrollerMetaData = &bind.MetaData{
+	ABI: "[{\"constant\":false,\"inputs\":[],\"name\":\"claimComp\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"internalType\":\"address\",\"name\":\"holder\",\"type\":\"address\"}],\"name\":\"claimComp\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[],\"name\":\"postProcess\",\"outputs\":[],\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"function\"}]",
+}
+
+// HcomptrollerABI is the input ABI used to generate the binding from.
+// Deprecated: Use HcomptrollerMetaData.ABI instead.
+var HcomptrollerABI = HcomptrollerMetaData.ABI
+
+// Hcomptroller is an auto generated Go binding around an Ethereum contract.
+type Hcomptroller struct {
+	HcomptrollerCaller     // Read-only binding to the contract
+	HcomptrollerTransactor // Write-only binding to the contract
+	HcomptrollerFilterer   // Log filterer for contract events
+}
+
+// HcomptrollerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type HcomptrollerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// HcomptrollerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type HcomptrollerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// HcomptrollerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type HcomptrollerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// HcomptrollerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type HcomptrollerSession struct {
+	Contract     *Hcomptroller     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// HcomptrollerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type HcomptrollerCallerSession struct {
+	Contract *HcomptrollerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// HcomptrollerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type HcomptrollerTransactorSession struct {
+	Contract     *HcomptrollerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// HcomptrollerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type HcomptrollerRaw struct {
+	Contract *Hcomptroller // Generic contract binding to access the raw methods on
+}
+
+// HcomptrollerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type HcomptrollerCallerRaw struct {
+	Contract *HcomptrollerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// HcomptrollerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type HcomptrollerTransactorRaw struct {
+	Contract *HcomptrollerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewHcomptroller creates a new instance of Hcomptroller, bound to a specific deployed contract.
+func NewHcomptroller(address common.Address, backend bind.ContractBackend) (*Hcomptroller, error) {
+	contract, err := bindHcomptroller(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Hcomptroller{HcomptrollerCaller: HcomptrollerCaller{contract: contract}, HcomptrollerTransactor: HcomptrollerTransactor{contract: contract}, HcomptrollerFilterer: HcomptrollerFilterer{contract: contract}}, nil
+}
+
+// NewHcomptrollerCaller creates a new read-only instance of Hcomptroller, bound to a specific deployed contract.
+func NewHcomptrollerCaller(address common.Address, caller bind.ContractCaller) (*HcomptrollerCaller, error) {
+	contract, err := bindHcomptroller(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &HcomptrollerCaller{contract: contract}, nil
+}
+
+// NewHcomptrollerTransactor creates a new write-only instance of Hcomptroller, bound to a specific deployed contract.
+func NewHcomptrollerTransactor(address common.Address, transactor bind.ContractTransactor) (*HcomptrollerTransactor, error) {
+	contract, err := bindHcomptroller(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &HcomptrollerTransactor{contract: contract}, nil
+}
+
+// NewHcomptrollerFilterer creates a new log filterer instance of Hcomptroller, bound to a specific deployed contract.
+func NewHcomptrollerFilterer(address common.Address, filterer bind.ContractFilterer) (*HcomptrollerFilterer, error) {
+	contract, err := bindHcomptroller(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &HcomptrollerFilterer{contract: contract}, nil
+}
+
+// bindHcomptroller binds a generic wrapper to an already deployed contract.
+func bindHcomptroller(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(HcomptrollerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Hcomptroller *HcomptrollerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Hcomptroller.Contract.HcomptrollerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Hcomptroller *HcomptrollerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Hcomptroller.Contract.HcomptrollerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Hcomptroller *HcomptrollerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Hcomptroller.Contract.HcomptrollerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Hcomptroller *HcomptrollerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Hcomptroller.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Hcomptroller *HcomptrollerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Hcomptroller.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Hcomptroller *HcomptrollerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Hcomptroller.Contract.contract.Transact(opts, method, params...)
+}
+
+// ClaimComp is a paid mutator transaction binding the contract method 0x1bd85bdb.
+//
+// Solidity: function claimComp() payable returns(uint256)
+func (_Hcomptroller *HcomptrollerTransactor) ClaimComp(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Hcomptroller.contract.Transact(opts, "claimComp")
+}
+
+// ClaimComp is a paid mutator transaction binding the contract method 0x1bd85bdb.
+//
+// Solidity: function claimComp() payable returns(uint256)
+func (_Hcomptroller *HcomptrollerSession) ClaimComp() (*types.Transaction, error) {
+	return _Hcomptroller.Contract.ClaimComp(&_Hcomptroller.TransactOpts)
+}
+
+// ClaimComp is a paid mutator transaction binding the contract method 0x1bd85bdb.
+//
+// Solidity: function claimComp() payable returns(uint256)
+func (_Hcomptroller *HcomptrollerTransactorSession) ClaimComp() (*types.Transaction, error) {
+	return _Hcomptroller.Contract.ClaimComp(&_Hcomptroller.TransactOpts)
+}
+
+// ClaimComp0 is a paid mutator transaction binding the contract method 0xe9af0292.
+//
+// Solidity: function claimComp(address holder) payable returns(uint256)
+func (_Hcomptroller *HcomptrollerTransactor) ClaimComp0(opts *bind.TransactOpts, holder common.Address) (*types.Transaction, error) {
+	return _Hcomptroller.contract.Transact(opts, "claimComp0", holder)
+}
+
+// ClaimComp0 is a paid mutator transaction binding the contract method 0xe9af0292.
+//
+// Solidity: function claimComp(address holder) payable returns(uint256)
+func (_Hcomptroller *HcomptrollerSession) ClaimComp0(holder common.Address) (*types.Transaction, error) {
+	return _Hcomptroller.Contract.ClaimComp0(&_Hcomptroller.TransactOpts, holder)
+}
+
+// ClaimComp0 is a paid mutator transaction binding the contract method 0xe9af0292.
+//
+// Solidity: function claimComp(address holder) payable returns(uint256)
+func (_Hcomptroller *HcomptrollerTransactorSession) ClaimComp0(holder common.Address) (*types.Transaction, error) {
+	return _Hcomptroller.Contract.ClaimComp0(&_Hcomptroller.TransactOpts, holder)
+}
+
+// PostProcess is a paid mutator transaction binding the contract method 0xc2722916.
+//
+// Solidity: function postProcess() payable returns()
+func (_Hcomptroller *HcomptrollerTransactor) PostProcess(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Hcomptroller.contract.Transact(opts, "postProcess")
+}
+
+// PostProcess is a paid mutator transaction binding the contract method 0xc2722916.
+//
+// Solidity: function postProcess() payable returns()
+func (_Hcomptroller *HcomptrollerSession) PostProcess() (*types.Transaction, error) {
+	return _Hcomptroller.Contract.PostProcess(&_Hcomptroller.TransactOpts)
+}
+
+// PostProcess is a paid mutator transaction binding the contract method 0xc2722916.
+//
+// Solidity: function postProcess() payable returns()
+func (_Hcomptroller *HcomptrollerTransactorSession) PostProcess() (*types.Transaction, error) {
+	return _Hcomptroller.Contract.PostProcess(&_Hcomptroller.TransactOpts)
+}
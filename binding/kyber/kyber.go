@@ -0,0 +1,225 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package kyber
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// KyberMetaData contains all meta data concerning the Kyber contract.
+var KyberMetaData = &bind.MetaData{
+	ABI: "[{\"constant\":true,\"inputs\":[{\"internalType\":\"contractERC20\",\"name\":\"src\",\"type\":\"address\"},{\"internalType\":\"contractERC20\",\"name\":\"dest\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"srcQty\",\"type\":\"uint256\"}],\"name\":\"getExpectedRate\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"expectedRate\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"worstRate\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// KyberABI is the input ABI used to generate the binding from.
+// Deprecated: Use KyberMetaData.ABI instead.
+var KyberABI = KyberMetaData.ABI
+
+// Kyber is an auto generated Go binding around an Ethereum contract.
+type Kyber struct {
+	KyberCaller     // Read-only binding to the contract
+	KyberTransactor // Write-only binding to the contract
+	KyberFilterer   // Log filterer for contract events
+}
+
+// KyberCaller is an auto generated read-only Go binding around an Ethereum contract.
+type KyberCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// KyberTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type KyberTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// KyberFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type KyberFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// KyberSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type KyberSession struct {
+	Contract     *Kyber            // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// KyberCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type KyberCallerSession struct {
+	Contract *KyberCaller  // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// KyberTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type KyberTransactorSession struct {
+	Contract     *KyberTransactor  // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// KyberRaw is an auto generated low-level Go binding around an Ethereum contract.
+type KyberRaw struct {
+	Contract *Kyber // Generic contract binding to access the raw methods on
+}
+
+// KyberCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type KyberCallerRaw struct {
+	Contract *KyberCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// KyberTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type KyberTransactorRaw struct {
+	Contract *KyberTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewKyber creates a new instance of Kyber, bound to a specific deployed contract.
+func NewKyber(address common.Address, backend bind.ContractBackend) (*Kyber, error) {
+	contract, err := bindKyber(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Kyber{KyberCaller: KyberCaller{contract: contract}, KyberTransactor: KyberTransactor{contract: contract}, KyberFilterer: KyberFilterer{contract: contract}}, nil
+}
+
+// NewKyberCaller creates a new read-only instance of Kyber, bound to a specific deployed contract.
+func NewKyberCaller(address common.Address, caller bind.ContractCaller) (*KyberCaller, error) {
+	contract, err := bindKyber(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &KyberCaller{contract: contract}, nil
+}
+
+// NewKyberTransactor creates a new write-only instance of Kyber, bound to a specific deployed contract.
+func NewKyberTransactor(address common.Address, transactor bind.ContractTransactor) (*KyberTransactor, error) {
+	contract, err := bindKyber(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &KyberTransactor{contract: contract}, nil
+}
+
+// NewKyberFilterer creates a new log filterer instance of Kyber, bound to a specific deployed contract.
+func NewKyberFilterer(address common.Address, filterer bind.ContractFilterer) (*KyberFilterer, error) {
+	contract, err := bindKyber(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &KyberFilterer{contract: contract}, nil
+}
+
+// bindKyber binds a generic wrapper to an already deployed contract.
+func bindKyber(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(KyberABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Kyber *KyberRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Kyber.Contract.KyberCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Kyber *KyberRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Kyber.Contract.KyberTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Kyber *KyberRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Kyber.Contract.KyberTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Kyber *KyberCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Kyber.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Kyber *KyberTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Kyber.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Kyber *KyberTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Kyber.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetExpectedRate is a free data retrieval call binding the contract method 0x809a9e55.
+//
+// Solidity: function getExpectedRate(address src, address dest, uint256 srcQty) view returns(uint256 expectedRate, uint256 worstRate)
+func (_Kyber *KyberCaller) GetExpectedRate(opts *bind.CallOpts, src common.Address, dest common.Address, srcQty *big.Int) (struct {
+	ExpectedRate *big.Int
+	WorstRate    *big.Int
+}, error) {
+	var out []interface{}
+	err := _Kyber.contract.Call(opts, &out, "getExpectedRate", src, dest, srcQty)
+
+	outstruct := new(struct {
+		ExpectedRate *big.Int
+		WorstRate    *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.ExpectedRate = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.WorstRate = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// GetExpectedRate is a free data retrieval call binding the contract method 0x809a9e55.
+//
+// Solidity: function getExpectedRate(address src, address dest, uint256 srcQty) view returns(uint256 expectedRate, uint256 worstRate)
+func (_Kyber *KyberSession) GetExpectedRate(src common.Address, dest common.Address, srcQty *big.Int) (struct {
+	ExpectedRate *big.Int
+	WorstRate    *big.Int
+}, error) {
+	return _Kyber.Contract.GetExpectedRate(&_Kyber.CallOpts, src, dest, srcQty)
+}
+
+// GetExpectedRate is a free data retrieval call binding the contract method 0x809a9e55.
+//
+// Solidity: function getExpectedRate(address src, address dest, uint256 srcQty) view returns(uint256 expectedRate, uint256 worstRate)
+func (_Kyber *KyberCallerSession) GetExpectedRate(src common.Address, dest common.Address, srcQty *big.Int) (struct {
+	ExpectedRate *big.Int
+	WorstRate    *big.Int
+}, error) {
+	return _Kyber.Contract.GetExpectedRate(&_Kyber.CallOpts, src, dest, srcQty)
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/cToken"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/priceoracle"
+)
+
+// CompoundPosition is the position of an account in a Compound market.
+type CompoundPosition struct {
+	Market  common.Address
+	CTokens *big.Int
+	// Supplied and Borrowed are in the underlying token, with the interest accrued up to the last
+	// interaction with the market.
+	Supplied Amount
+	Borrowed Amount
+	// Price is the oracle price of the underlying token, in USD scaled by 1e(36 - decimals).
+	Price *big.Int
+	// CollateralFactor is the part of the supply the account can borrow against, scaled by 1e18.
+	CollateralFactor *big.Int
+	// Collateral tells if the account entered the market, so its supply counts in the borrow limit.
+	Collateral bool
+	// SuppliedValue and BorrowedValue are in USD scaled by 1e18.
+	SuppliedValue *big.Int
+	BorrowedValue *big.Int
+}
+
+// CollateralValue returns the part of the supplied value in the borrow limit, in USD scaled by 1e18.
+func (p *CompoundPosition) CollateralValue() *big.Int {
+	if !p.Collateral {
+		return big.NewInt(0)
+	}
+	return share(p.SuppliedValue, p.CollateralFactor, pow10(18))
+}
+
+func newCompoundPosition(market common.Address, token Token, cTokens *big.Int, borrowed *big.Int, exchangeRate *big.Int, price *big.Int, collateralFactor *big.Int, collateral bool) CompoundPosition {
+	supplied := share(cTokens, exchangeRate, pow10(18))
+	return CompoundPosition{
+		Market:           market,
+		CTokens:          cTokens,
+		Supplied:         RawAmount(supplied, token),
+		Borrowed:         RawAmount(borrowed, token),
+		Price:            price,
+		CollateralFactor: collateralFactor,
+		Collateral:       collateral,
+		SuppliedValue:    share(supplied, price, pow10(18)),
+		BorrowedValue:    share(borrowed, price, pow10(18)),
+	}
+}
+
+// CompoundSnapshot is the Compound account of an address at a block. The values are in USD
+// scaled by 1e18, at the prices of the Compound oracle.
+type CompoundSnapshot struct {
+	Account       common.Address
+	Block         *big.Int
+	SuppliedValue *big.Int
+	BorrowedValue *big.Int
+	// BorrowLimit is the sum of the collateral values of the markets.
+	BorrowLimit *big.Int
+	// Liquidity is what is left to borrow, and Shortfall what the borrows exceed the limit by, as
+	// computed by the Comptroller. An account with a shortfall can be liquidated.
+	Liquidity *big.Int
+	Shortfall *big.Int
+	// Markets are the positions of the markets the account supplies to or borrows from.
+	Markets []CompoundPosition
+}
+
+func newCompoundSnapshot(account common.Address, block *big.Int) *CompoundSnapshot {
+	return &CompoundSnapshot{
+		Account:       account,
+		Block:         block,
+		SuppliedValue: big.NewInt(0),
+		BorrowedValue: big.NewInt(0),
+		BorrowLimit:   big.NewInt(0),
+		Liquidity:     big.NewInt(0),
+		Shortfall:     big.NewInt(0),
+	}
+}
+
+func (s *CompoundSnapshot) add(position CompoundPosition) {
+	s.SuppliedValue.Add(s.SuppliedValue, position.SuppliedValue)
+	s.BorrowedValue.Add(s.BorrowedValue, position.BorrowedValue)
+	s.BorrowLimit.Add(s.BorrowLimit, position.CollateralValue())
+	s.Markets = append(s.Markets, position)
+}
+
+// LimitUsed returns the borrowed value over the borrow limit. The account can be liquidated
+// above 1; an account without borrow limit is at 0 without borrows, and at +Inf with borrows.
+func (s *CompoundSnapshot) LimitUsed() float64 {
+	if s.BorrowLimit.Sign() == 0 {
+		if s.BorrowedValue.Sign() == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	used, _ := new(big.Rat).SetFrac(s.BorrowedValue, s.BorrowLimit).Float64()
+	return used
+}
+
+func (s *CompoundSnapshot) String() string {
+	return fmt.Sprintf("%s at block %v: supplied $%s, borrowed $%s, limit $%s, liquidity $%s, shortfall $%s",
+		s.Account.Hex(), s.Block, FormatUnits(s.SuppliedValue, 18), FormatUnits(s.BorrowedValue, 18),
+		FormatUnits(s.BorrowLimit, 18), FormatUnits(s.Liquidity, 18), FormatUnits(s.Shortfall, 18))
+}
+
+// AccountSnapshot reads the Compound account of `account` at the latest block: its position in
+// each market of the Comptroller, valued at the oracle prices, and its liquidity and shortfall.
+func (c *CompoundClient) AccountSnapshot(ctx context.Context, account common.Address) (*CompoundSnapshot, error) {
+	header, err := c.client.conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting latest block: %w", err)
+	}
+	// All the calls read the same block, so the snapshot is consistent.
+	opts := c.client.callOpts(ctx, header.Number)
+
+	comptrollerContract, err := c.comptroller()
+	if err != nil {
+		return nil, err
+	}
+	markets, err := comptrollerContract.GetAllMarkets(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Compound markets: %w", err)
+	}
+	assetsIn, err := comptrollerContract.GetAssetsIn(opts, account)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Compound markets of %s: %w", account.Hex(), err)
+	}
+	entered := make(map[common.Address]bool, len(assetsIn))
+	for _, market := range assetsIn {
+		entered[market] = true
+	}
+	oracleAddr, err := comptrollerContract.Oracle(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Compound oracle: %w", err)
+	}
+	oracle, err := priceoracle.NewPriceOracle(oracleAddr, c.client.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := newCompoundSnapshot(account, header.Number)
+	for _, market := range markets {
+		cTokenContract, err := cToken.NewCToken(market, c.client.conn)
+		if err != nil {
+			return nil, err
+		}
+		code, cTokens, borrowed, exchangeRate, err := cTokenContract.GetAccountSnapshot(opts, account)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting account snapshot of market %s: %w", market.Hex(), err)
+		}
+		if code.Sign() != 0 {
+			return nil, compoundError("getAccountSnapshot", code, cTokenErrors)
+		}
+		if cTokens.Sign() == 0 && borrowed.Sign() == 0 {
+			continue
+		}
+		token, err := c.marketToken(ctx, market)
+		if err != nil {
+			return nil, err
+		}
+		price, err := oracle.GetUnderlyingPrice(opts, market)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting price of market %s: %w", market.Hex(), err)
+		}
+		params, err := comptrollerContract.Markets(opts, market)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting collateral factor of market %s: %w", market.Hex(), err)
+		}
+		snapshot.add(newCompoundPosition(market, token, cTokens, borrowed, exchangeRate, price, params.CollateralFactorMantissa, entered[market]))
+	}
+
+	code, liquidity, shortfall, err := comptrollerContract.GetAccountLiquidity(opts, account)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting liquidity of %s: %w", account.Hex(), err)
+	}
+	if code.Sign() != 0 {
+		return nil, compoundError("getAccountLiquidity", code, comptrollerErrors)
+	}
+	snapshot.Liquidity, snapshot.Shortfall = liquidity, shortfall
+	return snapshot, nil
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/erc20"
+)
+
+var (
+	// ErrBadAmount is returned when an amount can't be parsed, e.g. it has more decimals than its token.
+	ErrBadAmount = errors.New("bad amount")
+	// ErrTokenMismatch is returned when an amount of a token is given for another token.
+	ErrTokenMismatch = errors.New("amount of another token")
+)
+
+// Size is the size taken by the action builders: either a raw *big.Int, in the smallest unit of the
+// token, or an Amount, which is checked against the token of the action.
+type Size interface {
+	Sign() int
+}
+
+// Amount is a quantity of a token, e.g. 1.5 USDC, kept in the smallest unit of the token.
+type Amount struct {
+	Token Token
+	Raw   *big.Int
+}
+
+// NewAmount creates an amount from a decimal string in the units of the token, e.g. "1.5",
+// using the decimals of `token`.
+func NewAmount(value string, token Token) (Amount, error) {
+	raw, err := ParseUnits(value, token.Decimals)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%w: %s %s", err, value, token)
+	}
+	return Amount{Token: token, Raw: raw}, nil
+}
+
+// RawAmount creates an amount from a quantity in the smallest unit of the token.
+func RawAmount(raw *big.Int, token Token) Amount {
+	return Amount{Token: token, Raw: raw}
+}
+
+// Int returns the amount in the smallest unit of the token.
+func (a Amount) Int() *big.Int {
+	return a.Raw
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	if a.Raw == nil {
+		return 0
+	}
+	return a.Raw.Sign()
+}
+
+// Decimal returns the amount in the units of the token, e.g. "1.5".
+func (a Amount) Decimal() string {
+	return FormatUnits(a.Raw, a.Token.Decimals)
+}
+
+// String returns the amount and the symbol of the token, e.g. "1.5 USDC".
+func (a Amount) String() string {
+	return a.Decimal() + " " + a.Token.Symbol
+}
+
+// ParseUnits converts a decimal string to an integer in the smallest unit of a token with `decimals`
+// decimals, e.g. "1.5" with 6 decimals is 1500000. More decimals than the token has is an error.
+func ParseUnits(value string, decimals uint8) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	whole, frac := value, ""
+	if i := strings.Index(value, "."); i >= 0 {
+		whole, frac = value[:i], value[i+1:]
+	}
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("%w: %q isn't a decimal number", ErrBadAmount, value)
+	}
+	if len(frac) > int(decimals) {
+		return nil, fmt.Errorf("%w: %q has more than %d decimals", ErrBadAmount, value, decimals)
+	}
+	raw, _ := new(big.Int).SetString(whole+frac+strings.Repeat("0", int(decimals)-len(frac)), 10)
+	return raw, nil
+}
+
+// FormatUnits converts an integer in the smallest unit of a token with `decimals` decimals to a
+// decimal string without trailing zeros, e.g. 1500000 with 6 decimals is "1.5".
+func FormatUnits(raw *big.Int, decimals uint8) string {
+	if raw == nil {
+		return "0"
+	}
+	sign := ""
+	digits := new(big.Int).Abs(raw).String()
+	if raw.Sign() < 0 {
+		sign = "-"
+	}
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// sizeOf returns the raw size of an action on the token at `token`, ETH and the WETH of the
+// network being the same. A nil size is returned as nil so that `packAction` reports it.
+func (c *DefiClient) sizeOf(size Size, token common.Address) (*big.Int, error) {
+	switch s := size.(type) {
+	case nil:
+		return nil, nil
+	case *big.Int:
+		return s, nil
+	case Amount:
+		if c.erc20Address(s.Token) != token && s.Token.Address != token {
+			return nil, fmt.Errorf("%w: %v for %s", ErrTokenMismatch, s, token.Hex())
+		}
+		return s.Raw, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported size type %T", ErrBadAmount, size)
+	}
+}
+
+// decimalsCache caches the decimals of the tokens read on chain.
+type decimalsCache struct {
+	mu       sync.RWMutex
+	decimals map[common.Address]uint8
+}
+
+// Decimals returns the decimals of a token as read with `decimals()` on chain, cached by the client.
+// Ether has 18 decimals.
+func (c *DefiClient) Decimals(ctx context.Context, token Token) (uint8, error) {
+	if token.IsETH() {
+		return 18, nil
+	}
+	c.decimals.mu.RLock()
+	decimals, ok := c.decimals.decimals[token.Address]
+	c.decimals.mu.RUnlock()
+	if ok {
+		return decimals, nil
+	}
+
+	contract, err := erc20.NewErc20(token.Address, c.conn)
+	if err != nil {
+		return 0, err
+	}
+	decimals, err = contract.Decimals(c.callOpts(ctx, nil))
+	if err != nil {
+		return 0, fmt.Errorf("Error getting decimals of %s: %w", token, err)
+	}
+	c.decimals.mu.Lock()
+	defer c.decimals.mu.Unlock()
+	if c.decimals.decimals == nil {
+		c.decimals.decimals = make(map[common.Address]uint8)
+	}
+	c.decimals.decimals[token.Address] = decimals
+	return decimals, nil
+}
+
+// NewAmount creates an amount of `token` from a decimal string, using the decimals of the token on chain.
+func (c *DefiClient) NewAmount(ctx context.Context, value string, token Token) (Amount, error) {
+	decimals, err := c.Decimals(ctx, token)
+	if err != nil {
+		return Amount{}, err
+	}
+	token.Decimals = decimals
+	return NewAmount(value, token)
+}
+
+// ParseAmount parses an amount and its token, e.g. "1.5 USDC", the token being a symbol or an
+// address resolved with `Token`.
+func (c *DefiClient) ParseAmount(ctx context.Context, amount string) (Amount, error) {
+	fields := strings.Fields(amount)
+	if len(fields) != 2 {
+		return Amount{}, fmt.Errorf("%w: %q isn't a value and a token", ErrBadAmount, amount)
+	}
+	token, err := c.Token(ctx, fields[1])
+	if err != nil {
+		return Amount{}, err
+	}
+	return c.NewAmount(ctx, fields[0], token)
+}
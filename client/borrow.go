@@ -0,0 +1,367 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ceth_binding "github.com/rafaelescrich/go-defi-1/binding/compound/cETH"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/cToken"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/comptroller"
+	"github.com/rafaelescrich/go-defi-1/binding/hcether"
+	"github.com/rafaelescrich/go-defi-1/binding/hctoken"
+)
+
+// ErrNoBorrowHandler is returned by `CompoundClient.BorrowActions` when the network config has no
+// Compound borrow handler, the Furucombo deployments have none.
+var ErrNoBorrowHandler = fmt.Errorf("%w: compoundBorrow", ErrNoHandler)
+
+// hCompoundBorrowABI is the interface of the Compound borrow handler, which borrows from the
+// market `cToken` into the proxy.
+const hCompoundBorrowABI = `[{"inputs":[{"internalType":"address","name":"cToken","type":"address"},{"internalType":"uint256","name":"borrowAmount","type":"uint256"}],"name":"borrow","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"payable","type":"function"}]`
+
+// CompoundError is the error code a Compound contract returns instead of reverting, e.g. on a
+// borrow beyond the liquidity of the account. It is found by simulating the call before sending it.
+type CompoundError struct {
+	Method string
+	Code   uint64
+	// Reason is the name of the code in the error reporter of the contract.
+	Reason string
+}
+
+func (e *CompoundError) Error() string {
+	return fmt.Sprintf("Compound %s failed with error %d (%s)", e.Method, e.Code, e.Reason)
+}
+
+// comptrollerErrors and cTokenErrors name the codes of the ComptrollerErrorReporter and of the
+// TokenErrorReporter, see https://compound.finance/docs/comptroller#error-codes
+var (
+	comptrollerErrors = []string{
+		"NO_ERROR", "UNAUTHORIZED", "COMPTROLLER_MISMATCH", "INSUFFICIENT_SHORTFALL", "INSUFFICIENT_LIQUIDITY",
+		"INVALID_CLOSE_FACTOR", "INVALID_COLLATERAL_FACTOR", "INVALID_LIQUIDATION_INCENTIVE", "MARKET_NOT_ENTERED",
+		"MARKET_NOT_LISTED", "MARKET_ALREADY_LISTED", "MATH_ERROR", "NONZERO_BORROW_BALANCE", "PRICE_ERROR",
+		"REJECTION", "SNAPSHOT_ERROR", "TOO_MANY_ASSETS", "TOO_MUCH_REPAY",
+	}
+	cTokenErrors = []string{
+		"NO_ERROR", "UNAUTHORIZED", "BAD_INPUT", "COMPTROLLER_REJECTION", "COMPTROLLER_CALCULATION_ERROR",
+		"INTEREST_RATE_MODEL_ERROR", "INVALID_ACCOUNT_PAIR", "INVALID_CLOSE_AMOUNT_REQUESTED",
+		"INVALID_COLLATERAL_FACTOR", "MATH_ERROR", "MARKET_NOT_FRESH", "MARKET_NOT_LISTED",
+		"TOKEN_INSUFFICIENT_ALLOWANCE", "TOKEN_INSUFFICIENT_BALANCE", "TOKEN_INSUFFICIENT_CASH",
+		"TOKEN_TRANSFER_IN_FAILED", "TOKEN_TRANSFER_OUT_FAILED",
+	}
+)
+
+func compoundError(method string, code *big.Int, reasons []string) *CompoundError {
+	reason := "UNKNOWN"
+	if code.IsUint64() && code.Uint64() < uint64(len(reasons)) {
+		reason = reasons[code.Uint64()]
+	}
+	return &CompoundError{Method: method, Code: code.Uint64(), Reason: reason}
+}
+
+// compoundCaller is the raw caller of a Compound binding.
+type compoundCaller interface {
+	Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error
+}
+
+// checkCompound simulates `method` from the account of the client and returns a *CompoundError
+// for the first nonzero error code it returns, so a failure doesn't cost a transaction.
+func (c *CompoundClient) checkCompound(ctx context.Context, caller compoundCaller, reasons []string, method string, params ...interface{}) error {
+	var out []interface{}
+	if err := caller.Call(c.client.callOpts(ctx, nil), &out, method, params...); err != nil {
+		return err
+	}
+	for _, result := range out {
+		codes, ok := result.([]*big.Int)
+		if !ok {
+			code, ok := result.(*big.Int)
+			if !ok {
+				continue
+			}
+			codes = []*big.Int{code}
+		}
+		for _, code := range codes {
+			if code.Sign() != 0 {
+				return compoundError(method, code, reasons)
+			}
+		}
+	}
+	return nil
+}
+
+// Borrow borrows `amount` of `coin` from its Compound market. The account must have entered
+// markets with enough collateral, see `EnterMarkets`.
+func (c *CompoundClient) Borrow(ctx context.Context, amount Size, coin Token) error {
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return err
+	}
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return err
+	}
+	if size == nil {
+		return ErrNilAmount
+	}
+	// cETH has the borrow of the other cTokens.
+	cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
+	if err != nil {
+		return fmt.Errorf("Error getting cToken contract: %v", err)
+	}
+	caller := &cToken.CTokenCallerRaw{Contract: &cTokenContract.CTokenCaller}
+	if err := c.checkCompound(ctx, caller, cTokenErrors, "borrow", size); err != nil {
+		return err
+	}
+	opts, err := c.client.transactOpts(ctx, nil)
+	if err != nil {
+		return err
+	}
+	tx, err := cTokenContract.Borrow(opts, size)
+	if err != nil {
+		c.client.releaseNonce(opts)
+		return err
+	}
+
+	_, err = c.client.waitMined(ctx, tx)
+	return err
+}
+
+// Repay repays `amount` of the borrow of `coin` of the account.
+func (c *CompoundClient) Repay(ctx context.Context, amount Size, coin Token) error {
+	return c.RepayBehalf(ctx, amount, coin, c.client.opts.From)
+}
+
+// RepayBehalf repays `amount` of the borrow of `coin` of `borrower`. The cETH market reverts when
+// the amount exceeds the borrow balance.
+func (c *CompoundClient) RepayBehalf(ctx context.Context, amount Size, coin Token, borrower common.Address) error {
+	var tx *types.Transaction
+
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return err
+	}
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return err
+	}
+	if size == nil {
+		return ErrNilAmount
+	}
+
+	switch {
+	case coin.IsETH():
+		cETHContract, err := ceth_binding.NewCETH(cTokenAddr, c.client.conn)
+		if err != nil {
+			return fmt.Errorf("Error getting cETH contract: %v", err)
+		}
+		opts, err := c.client.transactOpts(ctx, size)
+		if err != nil {
+			return err
+		}
+		tx, err = cETHContract.RepayBorrowBehalf(opts, borrower)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
+	default:
+		err = Approve(ctx, c.client, coin, cTokenAddr, size)
+		if err != nil {
+			return err
+		}
+		cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
+		if err != nil {
+			return fmt.Errorf("Error getting cToken contract: %v", err)
+		}
+		caller := &cToken.CTokenCallerRaw{Contract: &cTokenContract.CTokenCaller}
+		if err := c.checkCompound(ctx, caller, cTokenErrors, "repayBorrowBehalf", borrower, size); err != nil {
+			return err
+		}
+		opts, err := c.client.transactOpts(ctx, nil)
+		if err != nil {
+			return err
+		}
+		tx, err = cTokenContract.RepayBorrowBehalf(opts, borrower, size)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
+	}
+
+	_, err = c.client.waitMined(ctx, tx)
+	return err
+}
+
+// EnterMarkets enters the markets of `coins` in one transaction, so the supplies of the account
+// in these markets count as collateral for its borrows.
+func (c *CompoundClient) EnterMarkets(ctx context.Context, coins ...Token) error {
+	markets := make([]common.Address, 0, len(coins))
+	for _, coin := range coins {
+		cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+		if err != nil {
+			return err
+		}
+		markets = append(markets, cTokenAddr)
+	}
+	comptrollerContract, err := c.comptroller()
+	if err != nil {
+		return err
+	}
+	caller := &comptroller.ComptrollerCallerRaw{Contract: &comptrollerContract.ComptrollerCaller}
+	if err := c.checkCompound(ctx, caller, comptrollerErrors, "enterMarkets", markets); err != nil {
+		return err
+	}
+	opts, err := c.client.transactOpts(ctx, nil)
+	if err != nil {
+		return err
+	}
+	tx, err := comptrollerContract.EnterMarkets(opts, markets)
+	if err != nil {
+		c.client.releaseNonce(opts)
+		return err
+	}
+
+	_, err = c.client.waitMined(ctx, tx)
+	return err
+}
+
+// ExitMarkets exits the markets of `coins`, one transaction per market as the Comptroller exits
+// a single market at a time. It stops at the first market the account can't exit, e.g. because
+// of a borrow in the market or a shortfall without its collateral, and returns a *CompoundError.
+func (c *CompoundClient) ExitMarkets(ctx context.Context, coins ...Token) error {
+	comptrollerContract, err := c.comptroller()
+	if err != nil {
+		return err
+	}
+	caller := &comptroller.ComptrollerCallerRaw{Contract: &comptrollerContract.ComptrollerCaller}
+	for _, coin := range coins {
+		cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+		if err != nil {
+			return err
+		}
+		if err := c.checkCompound(ctx, caller, comptrollerErrors, "exitMarket", cTokenAddr); err != nil {
+			return err
+		}
+		opts, err := c.client.transactOpts(ctx, nil)
+		if err != nil {
+			return err
+		}
+		tx, err := comptrollerContract.ExitMarket(opts, cTokenAddr)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
+		if _, err := c.client.waitMined(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InMarket tells if the account has entered the market of `coin`.
+func (c *CompoundClient) InMarket(ctx context.Context, coin Token) (bool, error) {
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return false, err
+	}
+	comptrollerContract, err := c.comptroller()
+	if err != nil {
+		return false, err
+	}
+	return comptrollerContract.CheckMembership(c.client.callOpts(ctx, nil), c.client.opts.From, cTokenAddr)
+}
+
+func (c *CompoundClient) comptroller() (*comptroller.Comptroller, error) {
+	comptrollerContract, err := comptroller.NewComptroller(c.client.network.Contracts.CompoundComptroller, c.client.conn)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Comptroller contract: %v", err)
+	}
+	return comptrollerContract, nil
+}
+
+// BorrowActions creates an action borrowing `amount` of `coin` with the Compound borrow handler of
+// the network config. The borrow is a debt of the proxy, against the collateral it has in the
+// markets, and can be repaid for it with `RepayBehalfActions`. Without the handler the actions
+// fail with `ErrNoBorrowHandler`, borrow directly with `Borrow` before running the combo instead.
+func (c *CompoundClient) BorrowActions(amount Size, coin Token) *Actions {
+	handlerAddr := c.client.network.Handlers.CompoundBorrow
+	if handlerAddr == (common.Address{}) {
+		return failedActions("Compound", "borrow", "", ErrNoBorrowHandler)
+	}
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return failedActions("Compound", "borrow", "coin", err)
+	}
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return failedActions("Compound", "borrow", "size", err)
+	}
+	data, err := packAction("Compound", hCompoundBorrowABI, "borrow", cTokenAddr, size)
+	if err != nil {
+		return failedActions("Compound", "borrow", "", err)
+	}
+	return &Actions{
+		Actions: []action{
+			{
+				handlerAddr:  handlerAddr,
+				data:         data,
+				ethersNeeded: big.NewInt(0),
+			},
+		},
+	}
+}
+
+// RepayActions creates an action to repay `amount` of the borrow of `coin` of the user.
+func (c *CompoundClient) RepayActions(amount Size, coin Token) *Actions {
+	return c.RepayBehalfActions(amount, coin, c.client.opts.From)
+}
+
+// RepayBehalfActions creates an action to repay `amount` of the borrow of `coin` of `borrower`.
+// The tokens are injected from the user, the ethers are sent along.
+func (c *CompoundClient) RepayBehalfActions(amount Size, coin Token, borrower common.Address) *Actions {
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return failedActions("Compound", "repayBorrowBehalf", "coin", err)
+	}
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return failedActions("Compound", "repayBorrowBehalf", "size", err)
+	}
+	if coin.IsETH() {
+		data, err := packAction("Compound", hcether.HcetherABI, "repayBorrowBehalf", size, borrower)
+		if err != nil {
+			return failedActions("Compound", "repayBorrowBehalf", "", err)
+		}
+		return &Actions{
+			Actions: []action{
+				{
+					handlerAddr:  c.client.network.Handlers.CEther,
+					data:         data,
+					ethersNeeded: size,
+				},
+			},
+		}
+	}
+
+	tokenAddr, err := c.client.coinAddr(coin)
+	if err != nil {
+		return failedActions("Compound", "repayBorrowBehalf", "coin", err)
+	}
+	data, err := packAction("Compound", hctoken.HctokenABI, "repayBorrowBehalf", cTokenAddr, borrower, size)
+	if err != nil {
+		return failedActions("Compound", "repayBorrowBehalf", "", err)
+	}
+	return &Actions{
+		Actions: []action{
+			{
+				handlerAddr:          c.client.network.Handlers.CToken,
+				data:                 data,
+				ethersNeeded:         big.NewInt(0),
+				approvalTokens:       []common.Address{tokenAddr},
+				approvalTokenAmounts: []*big.Int{size},
+			},
+		},
+	}
+}
@@ -2,10 +2,10 @@ package client
 
 import (
 	"context"
-	"encoding/hex"
 	"fmt"
 	"math/big"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/rafaelescrich/go-defi-1/binding/haave"
 	"github.com/rafaelescrich/go-defi-1/binding/hbalancer_exchange"
@@ -14,11 +14,9 @@ import (
 	"github.com/rafaelescrich/go-defi-1/binding/hcurve"
 	"github.com/rafaelescrich/go-defi-1/binding/hkyber"
 	"github.com/rafaelescrich/go-defi-1/binding/hmaker"
-	"github.com/rafaelescrich/go-defi-1/binding/huniswap"
 	"github.com/rafaelescrich/go-defi-1/binding/hyearn"
 
 	"github.com/rafaelescrich/go-defi-1/binding/herc20tokenin"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 
 	"github.com/rafaelescrich/go-defi-1/binding/aave/lendingpool"
 	ceth_binding "github.com/rafaelescrich/go-defi-1/binding/compound/cETH"
@@ -32,8 +30,10 @@ import (
 	"github.com/rafaelescrich/go-defi-1/binding/yearn/yweth"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type rateModel int64
@@ -45,155 +45,14 @@ const (
 	VariableRate rateModel = 2
 )
 
-type coinType int
-
-const (
-	// ETH is ether.
-	ETH coinType = iota
-	// BAT is basic attention token.
-	BAT coinType = iota
-	// COMP is the governance token for Compound.
-	COMP coinType = iota
-	// DAI is the stable coin.
-	DAI coinType = iota
-	// REP is Augur reputation token.
-	REP coinType = iota
-	// SAI is Single Collateral DAI.
-	SAI coinType = iota
-	// UNI is the governance token for Uniswap.
-	UNI coinType = iota
-	// USDC is the stable coin by Circle.
-	USDC coinType = iota
-	// USDT is the stable coin.
-	USDT coinType = iota
-	// WBTC is wrapped BTC.
-	WBTC coinType = iota
-	// ZRX is the utility token for 0x.
-	ZRX coinType = iota
-	// BUSD is the Binance USD token.
-	BUSD coinType = iota
-	// YFI is the yearn governance token.
-	YFI coinType = iota
-	// AAVE is the Aave governance token.
-	AAVE coinType = iota
-
-	// cToken is the token that user receive after deposit into Yearn
-	cETH = iota
-
-	cDAI = iota
-
-	cUSDC = iota
-
-	yWETH = iota
-)
-
 const (
-	// uniswapAddr is UniswapV2Router, see here: https://uniswap.org/docs/v2/smart-contracts/router02/#address
-	uniswapAddr             string = "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"
-	yRegistryAddr           string = "0x3eE41C098f9666ed2eA246f4D2558010e59d63A0"
-	yETHVaultAddr           string = "0xe1237aA7f535b0CC33Fd973D66cBf830354D16c7"
-	aaveLendingPoolAddr     string = "0x398eC7346DcD622eDc5ae82352F02bE94C62d119"
-	aaveLendingPoolCoreAddr string = "0x3dfd23A6c5E8BbcFc9581d2E864a68feb6a076d3"
-	FurucomboAddr           string = "0xfFffFffF2ba8F66D4e51811C5190992176930278"
-	// Proxy and Handler related addresses
-
-	// ProxyAddr is the address of the proxy contract.
-	ProxyAddr             string = "0x57805e5a227937bac2b0fdacaa30413ddac6b8e1"
-	hCEtherAddr           string = "0x9A1049f7f87Dbb0468C745d9B3952e23d5d6CE5e"
-	hErcInAddr            string = "0x914490a362f4507058403a99e28bdf685c5c767f"
-	hCTokenAddr           string = "0x8973D623d883c5641Dd3906625Aac31cdC8790c5"
-	hMakerDaoAddr         string = "0x294fbca49c8a855e04d7d82b28256b086d39afea"
-	hUniswapAddr          string = "0x58a21cfcee675d65d577b251668f7dc46ea9c3a0"
-	hCurveAddr            string = "0xa36dfb057010c419c5917f3d68b4520db3671cdb"
-	hYearnAddr            string = "0xC50C8F34c9955217a6b3e385a069184DCE17fD2A"
-	hAaveAddr             string = "0xf579b009748a62b1978639d6b54259f8dc915229"
-	hOneInch              string = "0x783f5c56e3c8b23d90e4a271d7acbe914bfcd319"
-	hFunds                string = "0xf9b03e9ea64b2311b0221b2854edd6df97669c09"
-	hKyberAddr            string = "0xe2a3431508cd8e72d53a0e4b57c24af2899322a0"
-	hBalancerExchangeAddr string = "0x892dD6ebd2e3E1c0D6592309bA82a0095830D6d6"
-
-	// TODO: The following is not on mainnet yet
-	hSushiswapAddr string = "0xB6F469a8930dd5111c0EA76571c7E86298A171f7"
-	hSwapper       string = "0x017F3f2EB0c55DDF49B95ad38Cd2737ACf64AB4d"
-
-	// Curve pool addresses
-	cCompound string = "0xA2B47E3D5c44877cca798226B7B8118F9BFb7A56"
-	cUsdt     string = "0x52EA46506B9CC5Ef470C5bf89f17Dc28bB35D85C"
-	cY        string = "0x45F783CCE6B7FF23B2ab2D70e416cdb7D6055f51"
-	cBusd     string = "0x79a8C46DeA5aDa233ABaFFD40F3A0A2B1e5A4F27"
-	cSusd     string = "0xA5407eAE9Ba41422680e2e00537571bcC53efBfD"
-	cRen      string = "0x93054188d876f558f4a66B2EF1d97d16eDf0895B"
-	cSbtc     string = "0x7fC77b5c7614E1533320Ea6DDc2Eb61fa00A9714"
-	cHbtc     string = "0x4ca9b3063ec5866a4b82e437059d2c43d1be596f"
-	c3Pool    string = "0xbebc44782c7db0a1a60cb6fe97d0b483032ff1c7"
-	cGusd     string = "0x4f062658eaaf2c1ccf8c8e36d6824cdf41167956"
-	cHusd     string = "0x3eF6A01A0f81D6046290f3e2A8c5b843e738E604"
-	cUsdk     string = "0x3e01dd8a5e1fb3481f0f589056b428fc308af0fb"
-	cUsdn     string = "0x0f9cb53Ebe405d49A0bbdBD291A65Ff571bC83e1"
-
-	// Curve token addresses
-	compCrv      string = "0x845838DF265Dcd2c412A1Dc9e959c7d08537f8a2"
-	usdtCrv      string = "0x9fC689CCaDa600B6DF723D9E47D84d76664a1F23"
-	yCrv         string = "0xdF5e0e81Dff6FAF3A7e52BA697820c5e32D806A8"
-	busdCrv      string = "0x3B3Ac5386837Dc563660FB6a0937DFAa5924333B"
-	susdCrv      string = "0xC25a3A3b969415c80451098fa907EC722572917F"
-	renCrv       string = "0x49849C98ae39Fff122806C06791Fa73784FB3675"
-	sbtcCrv      string = "0x075b1bb99792c9E1041bA13afEf80C91a1e70fB3"
-	hbtcCrv      string = "0xb19059ebb43466C323583928285a49f558E572Fd"
-	threePoolCrv string = "0x6c3F90f043a72FA612cbac8115EE7e52BDe6E490"
-	gusdCrv      string = "0xD2967f45c4f384DEEa880F807Be904762a3DeA07"
-	husdCrv      string = "0x5B5CFE992AdAC0C9D48E05854B2d91C73a003858"
-	usdkCrv      string = "0x97E2768e8E73511cA874545DC5Ff8067eB19B787"
-	usdnCrv      string = "0x4f3E8F405CF5aFC05D68142F3783bDfE13811522"
+	// FurucomboAddr is the address of the Furucombo contract on mainnet.
+	FurucomboAddr string = "0xfFffFffF2ba8F66D4e51811C5190992176930278"
+	// ProxyAddr is the address of the proxy contract on mainnet, see `NetworkConfig` for the
+	// addresses of the other networks.
+	ProxyAddr string = "0x57805e5a227937bac2b0fdacaa30413ddac6b8e1"
 )
 
-// CoinToAddressMap returns a mapping from coin to address
-var CoinToAddressMap = map[coinType]common.Address{
-	ETH:   common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"),
-	BAT:   common.HexToAddress("0x0d8775f648430679a709e98d2b0cb6250d2887ef"),
-	COMP:  common.HexToAddress("0xc00e94cb662c3520282e6f5717214004a7f26888"),
-	DAI:   common.HexToAddress("0x6b175474e89094c44da98b954eedeac495271d0f"),
-	USDC:  common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"),
-	USDT:  common.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7"),
-	cETH:  common.HexToAddress("0x4ddc2d193948926d02f9b1fe9e1daa0718270ed5"),
-	cDAI:  common.HexToAddress("0x5d3a536e4d6dbd6114cc1ead35777bab948e3643"),
-	cUSDC: common.HexToAddress("0x39aa39c021dfbae8fac545936693ac917d5e7563"),
-	BUSD:  common.HexToAddress("0x4Fabb145d64652a948d72533023f6E7A623C7C53"),
-	yWETH: common.HexToAddress("0xe1237aA7f535b0CC33Fd973D66cBf830354D16c7"),
-}
-
-// CoinToCompoundMap returns a mapping from coin to compound address
-var CoinToCompoundMap = map[coinType]common.Address{
-	ETH:  common.HexToAddress("0x4ddc2d193948926d02f9b1fe9e1daa0718270ed5"),
-	DAI:  common.HexToAddress("0x5d3a536e4d6dbd6114cc1ead35777bab948e3643"),
-	USDC: common.HexToAddress("0x39aa39c021dfbae8fac545936693ac917d5e7563"),
-}
-
-// CoinToJoinMap maps the coin type to its corresponding Join which is a MakerDao terminology meaning an adapter to
-// deposit and withdraw unlocked collateral.
-var CoinToJoinMap = map[coinType]common.Address{
-	DAI:  common.HexToAddress("0x9759A6Ac90977b93B58547b4A71c78317f391A28"),
-	ETH:  common.HexToAddress("0x2F0b23f53734252Bda2277357e97e1517d6B042A"),
-	USDC: common.HexToAddress("0x2600004fd1585f7270756DDc88aD9cfA10dD0428"),
-	YFI:  common.HexToAddress("0x3ff33d9162aD47660083D7DC4bC02Fb231c81677"),
-	USDT: common.HexToAddress("0x0Ac6A1D74E84C2dF9063bDDc31699FF2a2BB22A2"),
-	UNI:  common.HexToAddress("0x2502F65D77cA13f183850b5f9272270454094A08"),
-	AAVE: common.HexToAddress("0x24e459F61cEAa7b1cE70Dbaea938940A7c5aD46e"),
-}
-
-// CoinToIlkMap maps the coin type to the corresponding Ilk as found in here:
-// https://etherscan.io/address/0x8b4ce5DCbb01e0e1f0521cd8dCfb31B308E52c24
-// Ilk is a MakerDao collateral type, each Ilk correspond to a type of collateral and
-// user can query it's name, symbol, dec, gem, pip, join and flip.
-var CoinToIlkMap = map[coinType][32]byte{
-	ETH:  byte32PutString("4554482d41000000000000000000000000000000000000000000000000000000"),
-	YFI:  byte32PutString("5946492d41000000000000000000000000000000000000000000000000000000"),
-	USDC: byte32PutString("555344432d420000000000000000000000000000000000000000000000000000"),
-	USDT: byte32PutString("555344542d410000000000000000000000000000000000000000000000000000"),
-	UNI:  byte32PutString("554e4956324441494554482d4100000000000000000000000000000000000000"),
-	AAVE: byte32PutString("414156452d410000000000000000000000000000000000000000000000000000"),
-}
-
 // Client is the new interface
 type Client interface {
 	Uniswap() UniswapClient
@@ -202,30 +61,78 @@ type Client interface {
 // NewClient Create a new client
 // opts can be created using your private key
 // ethclient can be created when you dial an ETH end point
-func NewClient(opts *bind.TransactOpts, ethClient *ethclient.Client) *DefiClient {
+// options can be used to change the defaults, e.g. `WithGasLimit` or `WithGasOracle`.
+// Without `WithNetwork`, the network is the one registered for the chain of ethclient, and an
+// unregistered chain is an error.
+func NewClient(opts *bind.TransactOpts, ethClient *ethclient.Client, options ...Option) (*DefiClient, error) {
 	c := new(DefiClient)
 	c.conn = ethClient
 	c.opts = opts
-	return c
+	c.gasMultiplier = DefaultGasMultiplier
+	c.gasCap = DefaultGasCap
+	c.gasOracle = NewPercentileOracle(ethClient, DefaultOracleBlocks, DefaultOraclePercentile)
+	c.miningTimeout = DefaultMiningTimeout
+	c.confirmations = DefaultConfirmations
+	c.swapDeadline = DefaultSwapDeadline
+	c.nonces = NewNonceManager(ethClient, opts.From)
+	for _, option := range options {
+		option(c)
+	}
+	if c.network == nil {
+		network, err := networkOf(c)
+		if err != nil {
+			return nil, err
+		}
+		c.network = network
+	}
+	if err := c.network.resolve(); err != nil {
+		return nil, err
+	}
+	if c.tokens == nil {
+		c.tokens = NewTokenRegistry(c.network.ChainID, c.network.Tokens...)
+	}
+	return c, nil
 }
 
+// Option configures a DefiClient.
+type Option func(*DefiClient)
+
 // DefiClient is the struct that stores the information.
 type DefiClient struct {
-	opts *bind.TransactOpts
-	conn *ethclient.Client
-}
-
-// BalanceOf returns the balance of a given coin.
-func (c *DefiClient) BalanceOf(coin coinType) (*big.Int, error) {
-	return c.balanceOf(CoinToAddressMap[coin])
+	opts      *bind.TransactOpts
+	conn      *ethclient.Client
+	rpcClient *rpc.Client
+
+	gasMultiplier float64
+	gasCap        uint64
+	gasOracle     GasOracle
+	miningTimeout time.Duration
+	confirmations uint64
+	nonces        *NonceManager
+	tokens        *TokenRegistry
+	network       *NetworkConfig
+	decimals      decimalsCache
+	swapDeadline  time.Duration
+	// compoundMarkets are the Compound markets discovered with `CompoundClient.Markets`.
+	compoundMarkets compoundMarketsCache
+	yearnVaults     yearnVaultsCache
+}
+
+// BalanceOf returns the balance of a given coin at `blockNum`, nil means the latest block.
+func (c *DefiClient) BalanceOf(ctx context.Context, coin Token, blockNum *big.Int) (*big.Int, error) {
+	addr, err := c.coinAddr(coin)
+	if err != nil {
+		return nil, err
+	}
+	return c.balanceOf(ctx, addr, blockNum)
 }
 
-func (c *DefiClient) balanceOf(addr common.Address) (*big.Int, error) {
+func (c *DefiClient) balanceOf(ctx context.Context, addr common.Address, blockNum *big.Int) (*big.Int, error) {
 	erc20, err := erc20.NewErc20(addr, c.conn)
 	if err != nil {
 		return nil, err
 	}
-	balance, err := erc20.BalanceOf(nil, c.opts.From)
+	balance, err := erc20.BalanceOf(c.callOpts(ctx, blockNum), c.opts.From)
 	if err != nil {
 		return nil, err
 	}
@@ -233,89 +140,76 @@ func (c *DefiClient) balanceOf(addr common.Address) (*big.Int, error) {
 	return balance, nil
 }
 
-// ExecuteActions sends one transaction for all the Defi interactions.
-func (c *DefiClient) ExecuteActions(actions *Actions) error {
-	gasPrice, err := c.SuggestGasPrice(nil)
+// ExecuteActions sends one transaction for all the Defi interactions, priced with `SuggestFees`,
+// and returns the decoded result once it is mined.
+func (c *DefiClient) ExecuteActions(ctx context.Context, actions *Actions) (*ExecutionResult, error) {
+	fees, err := c.SuggestFees(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return c.ExecuteActionsWithGasPrice(actions, gasPrice)
+	return c.ExecuteActionsWithFees(ctx, actions, fees)
 }
 
-// SuggestGasPrice provides an estimation of the gas price based on the `blockNum`.
+// SuggestGasPrice provides an estimation of the gas price based on the `blockNum`, using the
+// gas oracle of the client, see `WithGasOracle`.
 // If the blockNum is `nil`, it will automatically use the latest block data.
 // The user can also specify a specific `blockNum` so that block will be used for the prediction.
-func (c *DefiClient) SuggestGasPrice(blockNum *big.Int) (*big.Int, error) {
-	if blockNum == nil {
-		header, err := c.conn.HeaderByNumber(context.Background(), nil)
-		if err != nil {
-			return nil, err
-		}
-		blockNum = header.Number
-	}
+func (c *DefiClient) SuggestGasPrice(ctx context.Context, blockNum *big.Int) (*big.Int, error) {
+	return c.gasOracle.SuggestGasPrice(ctx, blockNum)
+}
 
-	block, err := c.conn.BlockByNumber(context.Background(), blockNum)
+// ExecuteActionsWithGasPrice sends one legacy transaction for all the Defi interactions with given gasPrice.
+func (c *DefiClient) ExecuteActionsWithGasPrice(ctx context.Context, actions *Actions, gasPrice *big.Int) (*ExecutionResult, error) {
+	return c.ExecuteActionsWithFees(ctx, actions, &Fees{GasPrice: gasPrice})
+}
+
+// ExecuteActionsWithFees sends one transaction for all the Defi interactions with given fees,
+// it is a dynamic fee transaction if `fees` sets the fee caps, otherwise a legacy one.
+// The gas limit is estimated, scaled by the safety multiplier and capped, see `WithGasLimit`.
+// If the estimation reverts, the combo isn't sent and an *ExecutionError is returned.
+// The wait for the transaction to be mined is bounded by the mining timeout, see `WithMiningTimeout`.
+// If the mined transaction reverted, the result is returned along with the *ExecutionError.
+func (c *DefiClient) ExecuteActionsWithFees(ctx context.Context, actions *Actions, fees *Fees) (*ExecutionResult, error) {
+	submission, err := c.SubmitWithFees(ctx, actions, fees)
 	if err != nil {
 		return nil, err
 	}
-
-	// If there is no transaction in the current block we fail back to the previous block.
-	if block.Transactions().Len() == 0 {
-		prvBlock := big.NewInt(0)
-		prvBlock.Sub(blockNum, big.NewInt(1))
-		return c.SuggestGasPrice(prvBlock)
-	}
-
-	sum := big.NewInt(0)
-	for _, transaction := range block.Transactions() {
-		sum.Add(sum, transaction.GasPrice())
-	}
-
-	size := big.NewInt(int64(len(block.Transactions())))
-	average := big.NewInt(0)
-
-	average.Div(sum, size)
-	return average, nil
+	defer submission.Stop()
+	return submission.Wait(ctx)
 }
 
-// ExecuteActionsWithGasPrice sends one transaction for all the Defi interactions with given gasPrice.
-func (c *DefiClient) ExecuteActionsWithGasPrice(actions *Actions, gasPrice *big.Int) error {
-	handlers, datas, totalEthers, err := c.CombineActions(actions)
-
+// CombineActions takes in an `Actions` and returns a slice of handler address and a slice of call data
+// if the combine is not successful, it will return the error.
+// If any of the actions failed to build, its *ActionError is returned and nothing is combined.
+func (c *DefiClient) CombineActions(ctx context.Context, actions *Actions) ([]common.Address, [][]byte, *big.Int, error) {
+	combined, err := c.combine(ctx, actions, nil)
 	if err != nil {
-		return err
+		return nil, nil, nil, err
 	}
+	return combined.handlers, combined.datas, combined.totalEthers, nil
+}
 
-	proxy, err := furucombo.NewFurucombo(common.HexToAddress(ProxyAddr), c.conn)
-	if err != nil {
-		return nil
-	}
+// combo is the combined form of an `Actions` as it is sent to the proxy.
+type combo struct {
+	handlers    []common.Address
+	datas       [][]byte
+	totalEthers *big.Int
+	// approvalTokens and approvalAmounts are the funds injected into the proxy from the sender.
+	approvalTokens  []common.Address
+	approvalAmounts []*big.Int
+}
 
-	opts := &bind.TransactOpts{
-		Value:    totalEthers,
-		Signer:   c.opts.Signer,
-		From:     c.opts.From,
-		GasLimit: 5000000,
-		GasPrice: gasPrice,
-	}
-	tx, err := proxy.BatchExec(opts, handlers, datas)
-	if err != nil {
-		return nil
-	}
-	receipt, err := bind.WaitMined(context.Background(), c.conn, tx)
-	if err != nil {
-		return err
+// combine combines `actions`, the funds injected are capped by the balances of the sender at
+// `blockNum`, nil means the latest block.
+func (c *DefiClient) combine(ctx context.Context, actions *Actions, blockNum *big.Int) (*combo, error) {
+	if actions == nil {
+		return nil, ErrNilActions
 	}
-	if receipt.Status != 1 {
-		return fmt.Errorf("tx receipt status is not 1, indicating a failure occurred")
+	if actions.err != nil {
+		return nil, actions.err
 	}
-	return nil
-}
 
-// CombineActions takes in an `Actions` and returns a slice of handler address and a slice of call data
-// if the combine is not successful, it will return the error.
-func (c *DefiClient) CombineActions(actions *Actions) ([]common.Address, [][]byte, *big.Int, error) {
 	handlers := []common.Address{}
 	datas := make([][]byte, 0)
 	totalEthers := big.NewInt(0)
@@ -331,9 +225,9 @@ func (c *DefiClient) CombineActions(actions *Actions) ([]common.Address, [][]byt
 				tokenAddr := actions.Actions[i].approvalTokens[j]
 				tokenAmount := actions.Actions[i].approvalTokenAmounts[j]
 				approvalTokens = append(approvalTokens, tokenAddr)
-				balance, err := c.balanceOf(tokenAddr)
+				balance, err := c.balanceOf(ctx, tokenAddr, blockNum)
 				if err != nil {
-					return nil, nil, nil, err
+					return nil, err
 				}
 				if balance.Cmp(tokenAmount) == 1 {
 					approvalAmounts = append(approvalAmounts, tokenAmount)
@@ -346,40 +240,47 @@ func (c *DefiClient) CombineActions(actions *Actions) ([]common.Address, [][]byt
 	}
 
 	if len(approvalTokens) > 0 {
-		parsed, err := abi.JSON(strings.NewReader(herc20tokenin.Herc20tokeninABI))
-		if err != nil {
-			return nil, nil, nil, err
-		}
-		injectData, err := parsed.Pack("inject", approvalTokens, approvalAmounts)
-
+		injectData, err := packAction("Funds", herc20tokenin.Herc20tokeninABI, "inject", approvalTokens, approvalAmounts)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, err
 		}
 
-		handlers = append([]common.Address{common.HexToAddress(hFunds)}, handlers...)
+		handlers = append([]common.Address{c.network.Handlers.Funds}, handlers...)
 		datas = append([][]byte{injectData}, datas...)
 	}
 
-	return handlers, datas, totalEthers, nil
+	return &combo{
+		handlers:        handlers,
+		datas:           datas,
+		totalEthers:     totalEthers,
+		approvalTokens:  approvalTokens,
+		approvalAmounts: approvalAmounts,
+	}, nil
 }
 
 // SupplyFundActions transfer a certain amount of fund to the proxy
-func (c *DefiClient) SupplyFundActions(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(herc20tokenin.Herc20tokeninABI))
+func (c *DefiClient) SupplyFundActions(amount Size, coin Token) *Actions {
+	coinAddress, err := c.coinAddr(coin)
 	if err != nil {
-		return nil
+		return failedActions("Funds", "inject", "coin", err)
 	}
-	injectData, err := parsed.Pack(
-		"inject", []common.Address{CoinToAddressMap[coin]}, []*big.Int{size})
-
+	size, err := c.sizeOf(amount, coinAddress)
 	if err != nil {
-		return nil
+		return failedActions("Funds", "inject", "amounts", err)
+	}
+	if size == nil {
+		return failedActions("Funds", "inject", "amounts", ErrNilAmount)
+	}
+	injectData, err := packAction(
+		"Funds", herc20tokenin.Herc20tokeninABI, "inject", []common.Address{coinAddress}, []*big.Int{size})
+	if err != nil {
+		return failedActions("Funds", "inject", "", err)
 	}
 
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hFunds),
+				handlerAddr:  c.network.Handlers.Funds,
 				data:         injectData,
 				ethersNeeded: big.NewInt(0),
 			},
@@ -395,23 +296,43 @@ type action struct {
 	// There could be multiple tokens that we need to approve in the case of say Curve add liquidity or Flash loan
 	approvalTokens       []common.Address
 	approvalTokenAmounts []*big.Int
+	// nested are the actions run inside a flash loan or flash swap.
+	nested *Actions
 }
 
 // Actions represents a list of Action.
+// A builder that fails returns an `Actions` carrying the error, the error is kept when
+// the `Actions` is added to another one and surfaced by `CombineActions`.
 type Actions struct {
 	Actions []action
+	err     error
 }
 
 // Add adds actions together
 // This is a variadic function so user can pass in any number of actions.
+// It returns the first build error carried by any of the actions.
 func (actions *Actions) Add(newActionss ...*Actions) error {
-	if newActionss == nil {
-		return fmt.Errorf("new action is nil")
+	if newActionss == nil && actions.err == nil {
+		actions.err = ErrNilActions
 	}
 	for _, newActions := range newActionss {
+		if newActions == nil {
+			if actions.err == nil {
+				actions.err = ErrNilActions
+			}
+			continue
+		}
+		if newActions.err != nil && actions.err == nil {
+			actions.err = newActions.err
+		}
 		actions.Actions = append(actions.Actions, newActions.Actions...)
 	}
-	return nil
+	return actions.err
+}
+
+// Err returns the first error that occurred while building the actions.
+func (actions *Actions) Err() error {
+	return actions.err
 }
 
 // Uniswap---------------------------------------------------------------------
@@ -420,16 +341,18 @@ func (actions *Actions) Add(newActionss ...*Actions) error {
 type UniswapClient struct {
 	client  *DefiClient
 	uniswap *uniswap.Uniswap
+	err     error
 }
 
 // Uniswap returns a uniswap client.
 func (c *DefiClient) Uniswap() *UniswapClient {
 	uniClient := new(UniswapClient)
 	uniClient.client = c
-	uniswap, err := uniswap.NewUniswap(common.HexToAddress(uniswapAddr), c.conn)
+	uniswap, err := uniswap.NewUniswap(c.network.Contracts.UniswapRouter, c.conn)
 
 	if err != nil {
-		return nil
+		uniClient.err = err
+		return uniClient
 	}
 
 	uniClient.uniswap = uniswap
@@ -439,142 +362,125 @@ func (c *DefiClient) Uniswap() *UniswapClient {
 // TxHash represents a transaction hash.
 type TxHash string
 
-// Swap in the Uniswap Exchange.
-func (c *UniswapClient) Swap(size int64, baseCurrency coinType, quoteCurrency coinType, receipient common.Address) error {
-	if quoteCurrency == ETH {
-		return c.swapETHToToken(size, baseCurrency, receipient)
+// Swap in the Uniswap Exchange, `options` protect the swap against slippage.
+func (c *UniswapClient) Swap(ctx context.Context, amount Size, baseCurrency Token, quoteCurrency Token, receipient common.Address, options ...SwapOption) error {
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(quoteCurrency))
+	if err != nil {
+		return err
+	}
+	if size == nil {
+		return ErrNilAmount
+	}
+	o := newSwapOptions(options)
+	minOut, err := c.client.minOutput(ctx, o,
+		c.client.erc20Address(baseCurrency),
+		c.client.routerQuote(c.client.network.Contracts.UniswapRouter, size, c.client.pathAddrs(c.client.swapPath(baseCurrency, quoteCurrency))))
+	if err != nil {
+		return err
+	}
+	deadline, err := c.client.deadlineOf(ctx, o.deadline)
+	if err != nil {
+		return err
+	}
+	if quoteCurrency.IsETH() {
+		return c.swapETHToToken(ctx, size, minOut, deadline, baseCurrency, receipient)
 	} else {
-		err := Approve(c.client, quoteCurrency, common.HexToAddress(uniswapAddr), big.NewInt(size))
+		err := Approve(ctx, c.client, quoteCurrency, c.client.network.Contracts.UniswapRouter, size)
 		if err != nil {
 			return err
 		}
-		if baseCurrency == ETH {
-			return c.swapTokenToETH(size, quoteCurrency, receipient)
+		if baseCurrency.IsETH() {
+			return c.swapTokenToETH(ctx, size, minOut, deadline, quoteCurrency, receipient)
 		} else {
-			return c.swapTokenToToken(size, baseCurrency, quoteCurrency, receipient)
+			return c.swapTokenToToken(ctx, size, minOut, deadline, baseCurrency, quoteCurrency, receipient)
 		}
 	}
 }
 
-func (c *UniswapClient) swapETHToToken(size int64, baseCurrency coinType, receipient common.Address) error {
-	path := []common.Address{CoinToAddressMap[ETH], CoinToAddressMap[baseCurrency]}
+func (c *UniswapClient) swapETHToToken(ctx context.Context, size *big.Int, minOut *big.Int, deadline *big.Int, baseCurrency Token, receipient common.Address) error {
+	path := []common.Address{c.client.weth().Address, baseCurrency.Address}
+	opts, err := c.client.transactOpts(ctx, size)
+	if err != nil {
+		return err
+	}
 	tx, err := c.uniswap.SwapExactETHForTokens(
-		// TODO: there is basically no minimum output amount set, so this could cause huge slippage, need to fix.
-		// Also the time stamp is set to 2038 January 1, it's better to set it dynamically.
-		&bind.TransactOpts{
-			Value:    big.NewInt(size),
-			Signer:   c.client.opts.Signer,
-			From:     c.client.opts.From,
-			GasLimit: 500000,
-			GasPrice: big.NewInt(20000000000),
-		},
-		big.NewInt(0),
+		opts,
+		minOut,
 		path, receipient,
-		big.NewInt(2145916800),
+		deadline,
 	)
 	if err != nil {
-		return err
+		c.client.releaseNonce(opts)
+		return routerError(err)
 	}
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-	return nil
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
-func (c *UniswapClient) swapTokenToToken(size int64, baseCurrency coinType, quoteCurrency coinType, receipient common.Address) error {
-	path := []common.Address{CoinToAddressMap[quoteCurrency], CoinToAddressMap[ETH], CoinToAddressMap[baseCurrency]}
-
-	tx, err := c.uniswap.SwapExactTokensForTokens(
-		// TODO: there is basically no minimum output amount set, so this could cause huge slippage, need to fix.
-		// Also the time stamp is set to 2038 January 1, it's better to set it dynamically.
-		c.client.opts, big.NewInt(size), big.NewInt(0), path, receipient, big.NewInt(2145916800))
+func (c *UniswapClient) swapTokenToToken(ctx context.Context, size *big.Int, minOut *big.Int, deadline *big.Int, baseCurrency Token, quoteCurrency Token, receipient common.Address) error {
+	path := []common.Address{quoteCurrency.Address, c.client.weth().Address, baseCurrency.Address}
+	opts, err := c.client.transactOpts(ctx, nil)
 	if err != nil {
 		return err
 	}
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-	return nil
-}
-
-func (c *UniswapClient) swapTokenToETH(size int64, quoteCurrency coinType, receipient common.Address) error {
-	path := []common.Address{CoinToAddressMap[quoteCurrency], CoinToAddressMap[ETH]}
-
-	tx, err := c.uniswap.SwapExactTokensForETH(
-		// TODO: there is basically no minimum output amount set, so this could cause huge slippage, need to fix.
-		// Also the time stamp is set to 2038 January 1, it's better to set it dynamically.
-		c.client.opts, big.NewInt(size), big.NewInt(0), path, receipient, big.NewInt(2145916800))
+	tx, err := c.uniswap.SwapExactTokensForTokens(
+		opts, size, minOut, path, receipient, deadline)
 	if err != nil {
-		return err
+		c.client.releaseNonce(opts)
+		return routerError(err)
 	}
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-	return nil
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
-// SwapActions create a new swap action.
-func (c *UniswapClient) SwapActions(size *big.Int, baseCurrency coinType, quoteCurrency coinType) *Actions {
-	var callData []byte
-	var ethersNeeded = big.NewInt(0)
-	if quoteCurrency == ETH {
-		ethersNeeded = size
-		callData = swapETHToTokenData(size, baseCurrency)
-	} else {
-		if baseCurrency == ETH {
-			callData = swapTokenToETHData(size, quoteCurrency)
-		} else {
-			callData = swapTokenToTokenData(size, baseCurrency, quoteCurrency)
-		}
+func (c *UniswapClient) swapTokenToETH(ctx context.Context, size *big.Int, minOut *big.Int, deadline *big.Int, quoteCurrency Token, receipient common.Address) error {
+	path := []common.Address{quoteCurrency.Address, c.client.weth().Address}
+	opts, err := c.client.transactOpts(ctx, nil)
+	if err != nil {
+		return err
 	}
-
-	return &Actions{
-		Actions: []action{
-			{
-				handlerAddr:  common.HexToAddress(hUniswapAddr),
-				data:         callData,
-				ethersNeeded: ethersNeeded,
-			},
-		},
+	tx, err := c.uniswap.SwapExactTokensForETH(
+		opts, size, minOut, path, receipient, deadline)
+	if err != nil {
+		c.client.releaseNonce(opts)
+		return routerError(err)
 	}
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
-func swapETHToTokenData(size *big.Int, baseCurrency coinType) []byte {
-	parsed, err := abi.JSON(strings.NewReader(huniswap.HuniswapABI))
-	if err != nil {
-		return nil
+// SwapActions create a new swap action, `options` protect it against slippage.
+func (c *UniswapClient) SwapActions(ctx context.Context, amount Size, baseCurrency Token, quoteCurrency Token, options ...SwapOption) *Actions {
+	if _, err := c.client.coinAddr(baseCurrency); err != nil {
+		return failedActions("Uniswap", "swap", "baseCurrency", err)
 	}
-	data, err := parsed.Pack(
-		"swapExactETHForTokens", size, big.NewInt(0), []common.Address{CoinToAddressMap[ETH], CoinToAddressMap[baseCurrency]})
-	if err != nil {
-		return nil
+	if _, err := c.client.coinAddr(quoteCurrency); err != nil {
+		return failedActions("Uniswap", "swap", "quoteCurrency", err)
 	}
-	return data
+	return c.SwapPathActions(ctx, amount, c.client.swapPath(baseCurrency, quoteCurrency), options...)
 }
 
-func swapTokenToETHData(size *big.Int, quoteCurrency coinType) []byte {
-	parsed, err := abi.JSON(strings.NewReader(huniswap.HuniswapABI))
-	if err != nil {
-		return nil
+// FlashSwapActions create an action to perform flash swap on Uniswap.
+func (c *UniswapClient) FlashSwapActions(amount Size, coinBorrow Token, coinRepay Token, actions *Actions) *Actions {
+	if actions == nil {
+		return failedActions("Uniswap", "startSwap", "actions", ErrNilActions)
+	}
+	if actions.err != nil {
+		return failedActions("Uniswap", "startSwap", "actions", actions.err)
 	}
-	data, err := parsed.Pack(
-		"swapExactTokensForETH", size, big.NewInt(0), []common.Address{CoinToAddressMap[quoteCurrency], CoinToAddressMap[ETH]})
+	borrowAddr, err := c.client.coinAddr(coinBorrow)
 	if err != nil {
-		return nil
+		return failedActions("Uniswap", "startSwap", "coinBorrow", err)
 	}
-	return data
-}
-
-func swapTokenToTokenData(size *big.Int, baseCurrency coinType, quoteCurrency coinType) []byte {
-	parsed, err := abi.JSON(strings.NewReader(huniswap.HuniswapABI))
+	repayAddr, err := c.client.coinAddr(coinRepay)
 	if err != nil {
-		return nil
+		return failedActions("Uniswap", "startSwap", "coinRepay", err)
 	}
-	data, err := parsed.Pack(
-		"swapExactTokensForTokens", size, big.NewInt(0), []common.Address{
-			CoinToAddressMap[quoteCurrency], CoinToAddressMap[ETH], CoinToAddressMap[baseCurrency]})
+	size, err := c.client.sizeOf(amount, borrowAddr)
 	if err != nil {
-		return nil
+		return failedActions("Uniswap", "startSwap", "size", err)
 	}
-	return data
-}
 
-// FlashSwapActions create an action to perform flash swap on Uniswap.
-func (c *UniswapClient) FlashSwapActions(size *big.Int, coinBorrow coinType, coinRepay coinType, actions *Actions) *Actions {
 	handlers := []common.Address{}
 	datas := make([][]byte, 0)
 	totalEthers := big.NewInt(0)
@@ -584,30 +490,23 @@ func (c *UniswapClient) FlashSwapActions(size *big.Int, coinBorrow coinType, coi
 		totalEthers.Add(totalEthers, actions.Actions[i].ethersNeeded)
 	}
 
-	proxy, err := abi.JSON(strings.NewReader(furucombo.FurucomboABI))
-	if err != nil {
-		return nil
-	}
-	payloadData, err := proxy.Pack("execs", handlers, datas)
+	payloadData, err := packAction("Furucombo", furucombo.FurucomboABI, "execs", handlers, datas)
 	if err != nil {
-		return nil
-	}
-	swapperAbi, err := abi.JSON(strings.NewReader(swapper.SwapperABI))
-	if err != nil {
-		return nil
+		return failedActions("Furucombo", "execs", "", err)
 	}
 	// skip the first 4 bytes to omit the function selector
-	flashSwapData, err := swapperAbi.Pack("startSwap", CoinToAddressMap[coinBorrow], size, CoinToAddressMap[coinRepay], payloadData[4:])
+	flashSwapData, err := packAction("Uniswap", swapper.SwapperABI, "startSwap", borrowAddr, size, repayAddr, payloadData[4:])
 	if err != nil {
-		return nil
+		return failedActions("Uniswap", "startSwap", "", err)
 	}
 
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hSwapper),
+				handlerAddr:  c.client.network.Handlers.Swapper,
 				data:         flashSwapData,
 				ethersNeeded: totalEthers,
+				nested:       actions,
 			},
 		},
 	}
@@ -629,55 +528,67 @@ func (c *DefiClient) Compound() *CompoundClient {
 }
 
 // Supply supplies token to compound.
-func (c *CompoundClient) Supply(amount int64, coin coinType) error {
+func (c *CompoundClient) Supply(ctx context.Context, amount Size, coin Token) error {
 	var (
 		tx  *types.Transaction
 		err error
 	)
 
 	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
-	opts := &bind.TransactOpts{
-		From:     c.client.opts.From,
-		Signer:   c.client.opts.Signer,
-		GasLimit: 500000,
-		GasPrice: big.NewInt(20000000000),
+	if err != nil {
+		return err
+	}
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return err
+	}
+	if size == nil {
+		return ErrNilAmount
+	}
+	if !coin.IsETH() {
+		err = Approve(ctx, c.client, coin, cTokenAddr, size)
+		if err != nil {
+			return err
+		}
+	}
+	opts, err := c.client.transactOpts(ctx, nil)
+	if err != nil {
+		return err
 	}
 
-	switch coin {
-	case ETH:
-		opts.Value = big.NewInt(amount)
+	switch {
+	case coin.IsETH():
+		opts.Value = size
 		cETHContract, err := ceth_binding.NewCETH(cTokenAddr, c.client.conn)
 		if err != nil {
+			c.client.releaseNonce(opts)
 			return err
 		}
 
 		tx, err = cETHContract.Mint(opts)
-	case BAT, COMP, DAI, REP, SAI, UNI, USDC, USDT, WBTC, ZRX:
-		err = Approve(c.client, coin, cTokenAddr, big.NewInt(amount))
 		if err != nil {
+			c.client.releaseNonce(opts)
 			return err
 		}
+	default:
 		cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
 		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
+		tx, err = cTokenContract.Mint(opts, size)
+		if err != nil {
+			c.client.releaseNonce(opts)
 			return err
 		}
-		tx, err = cTokenContract.Mint(opts, big.NewInt(amount))
-	default:
-		return fmt.Errorf("Not supported")
-	}
-
-	if err != nil {
-		fmt.Printf("Error mint ctoken: %v", err)
-		return err
 	}
 
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-
-	return nil
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
-// Redeem supplies token to compound.
-func (c *CompoundClient) Redeem(amount int64, coin coinType) error {
+// Redeem redeems cTokens of the market of `coin`, the size is in cTokens.
+func (c *CompoundClient) Redeem(ctx context.Context, amount Size, coin Token) error {
 	var (
 		tx  *types.Transaction
 		err error
@@ -687,42 +598,52 @@ func (c *CompoundClient) Redeem(amount int64, coin coinType) error {
 	if err != nil {
 		return err
 	}
+	size, err := c.client.sizeOf(amount, cTokenAddr)
+	if err != nil {
+		return err
+	}
+	if size == nil {
+		return ErrNilAmount
+	}
 
-	opts := &bind.TransactOpts{
-		From:     c.client.opts.From,
-		Signer:   c.client.opts.Signer,
-		GasLimit: 500000,
-		GasPrice: big.NewInt(20000000000),
+	opts, err := c.client.transactOpts(ctx, nil)
+	if err != nil {
+		return err
 	}
 
-	switch coin {
-	case ETH:
+	switch {
+	case coin.IsETH():
 		cETHContract, err := ceth_binding.NewCETH(cTokenAddr, c.client.conn)
 		if err != nil {
+			c.client.releaseNonce(opts)
 			return fmt.Errorf("Error getting cETH contract: %v", err)
 		}
 
-		tx, err = cETHContract.Redeem(opts, big.NewInt(amount))
-	case BAT, COMP, DAI, REP, SAI, UNI, USDC, USDT, WBTC, ZRX:
+		tx, err = cETHContract.Redeem(opts, size)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
+	default:
 		cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
 		if err != nil {
+			c.client.releaseNonce(opts)
 			return fmt.Errorf("Error getting cToken contract: %v", err)
 		}
 
-		tx, err = cTokenContract.Redeem(opts, big.NewInt(amount))
-	}
-
-	if err != nil {
-		return err
+		tx, err = cTokenContract.Redeem(opts, size)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
 	}
 
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-
-	return nil
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
-// BalanceOf return the balance of given cToken.
-func (c *CompoundClient) BalanceOf(coin coinType) (*big.Int, error) {
+// BalanceOf return the balance of given cToken at `blockNum`, nil means the latest block.
+func (c *CompoundClient) BalanceOf(ctx context.Context, coin Token, blockNum *big.Int) (*big.Int, error) {
 	var (
 		val *big.Int
 		err error
@@ -733,89 +654,75 @@ func (c *CompoundClient) BalanceOf(coin coinType) (*big.Int, error) {
 		return nil, err
 	}
 
-	switch coin {
-	case ETH:
-		cETHContract, err := ceth_binding.NewCETH(cTokenAddr, c.client.conn)
+	switch {
+	case coin.IsETH():
+		var cETHContract *ceth_binding.CETH
+		cETHContract, err = ceth_binding.NewCETH(cTokenAddr, c.client.conn)
 		if err != nil {
 			return nil, fmt.Errorf("Error getting cETH contract")
 		}
 
-		val, err = cETHContract.BalanceOf(nil, c.client.opts.From)
-	case BAT, COMP, DAI, REP, SAI, UNI, USDC, USDT, WBTC, ZRX:
-		cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
+		val, err = cETHContract.BalanceOf(c.client.callOpts(ctx, blockNum), c.client.opts.From)
+	default:
+		var cTokenContract *cToken.CToken
+		cTokenContract, err = cToken.NewCToken(cTokenAddr, c.client.conn)
 		if err != nil {
 			return nil, fmt.Errorf("Error getting cDai contract")
 		}
 
-		val, err = cTokenContract.BalanceOf(nil, c.client.opts.From)
-	default:
-		return nil, fmt.Errorf("Not support token in balanceOf: %v", coin)
+		val, err = cTokenContract.BalanceOf(c.client.callOpts(ctx, blockNum), c.client.opts.From)
 	}
 
 	if err != nil {
-		return big.NewInt(0), fmt.Errorf("Error getting balance of cToken: %v", err)
+		return nil, fmt.Errorf("Error getting balance of cToken: %w", err)
 	}
 	return val, nil
 }
 
-// BalanceOfUnderlying return the balance of given cToken
-func (c *CompoundClient) BalanceOfUnderlying(coin coinType) (*types.Transaction, error) {
-	var (
-		tx  *types.Transaction
-		err error
-	)
-
+// BalanceOfUnderlying returns the underlying tokens the cTokens of the account redeem for at
+// `blockNum`, nil means the latest block. The balanceOfUnderlying of the market is simulated, so
+// the interest is accrued up to the block without sending a transaction.
+func (c *CompoundClient) BalanceOfUnderlying(ctx context.Context, coin Token, blockNum *big.Int) (*big.Int, error) {
 	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
 	if err != nil {
 		return nil, err
 	}
-
-	switch coin {
-	case ETH:
-		cETHContract, err := ceth_binding.NewCETH(cTokenAddr, c.client.conn)
-		if err != nil {
-			fmt.Printf("Error getting cETH contract")
-		}
-
-		tx, err = cETHContract.BalanceOfUnderlying(nil, c.client.opts.From)
-	case BAT, COMP, DAI, REP, SAI, UNI, USDC, USDT, WBTC, ZRX:
-		cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
-		if err != nil {
-			return nil, fmt.Errorf("Error getting cDai contract")
-		}
-
-		tx, err = cTokenContract.BalanceOfUnderlying(nil, c.client.opts.From)
+	// cETH has the balanceOfUnderlying of the other cTokens.
+	cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting cToken contract: %v", err)
 	}
-
+	caller := &cToken.CTokenCallerRaw{Contract: &cTokenContract.CTokenCaller}
+	var out []interface{}
+	err = caller.Call(c.client.callOpts(ctx, blockNum), &out, "balanceOfUnderlying", c.client.opts.From)
 	if err != nil {
-		fmt.Printf("Error getting balance of cToken: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("Error getting underlying balance of cToken: %v", err)
 	}
-	return tx, nil
+	return out[0].(*big.Int), nil
 }
 
 // SupplyActions create a supply action to supply asset to Compound.
-func (c *CompoundClient) SupplyActions(size *big.Int, coin coinType) *Actions {
-	if coin == ETH {
+func (c *CompoundClient) SupplyActions(amount Size, coin Token) *Actions {
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return failedActions("Compound", "mint", "size", err)
+	}
+	if coin.IsETH() {
 		return c.supplyActionsETH(size, coin)
 	} else {
 		return c.supplyActionsERC20(size, coin)
 	}
 }
 
-func (c *CompoundClient) supplyActionsETH(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hcether.HcetherABI))
+func (c *CompoundClient) supplyActionsETH(size *big.Int, coin Token) *Actions {
+	data, err := packAction("Compound", hcether.HcetherABI, "mint", size)
 	if err != nil {
-		return nil
-	}
-	data, err := parsed.Pack("mint", size)
-	if err != nil {
-		return nil
+		return failedActions("Compound", "mint", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hCEtherAddr),
+				handlerAddr:  c.client.network.Handlers.CEther,
 				data:         data,
 				ethersNeeded: size,
 			},
@@ -823,22 +730,26 @@ func (c *CompoundClient) supplyActionsETH(size *big.Int, coin coinType) *Actions
 	}
 }
 
-func (c *CompoundClient) supplyActionsERC20(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hctoken.HctokenABI))
+func (c *CompoundClient) supplyActionsERC20(size *big.Int, coin Token) *Actions {
+	tokenAddr, err := c.client.coinAddr(coin)
 	if err != nil {
-		return nil
+		return failedActions("Compound", "mint", "coin", err)
 	}
-	mintData, err := parsed.Pack("mint", CoinToCompoundMap[DAI], size)
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
 	if err != nil {
-		return nil
+		return failedActions("Compound", "mint", "coin", err)
+	}
+	mintData, err := packAction("Compound", hctoken.HctokenABI, "mint", cTokenAddr, size)
+	if err != nil {
+		return failedActions("Compound", "mint", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hCTokenAddr),
+				handlerAddr:          c.client.network.Handlers.CToken,
 				data:                 mintData,
 				ethersNeeded:         big.NewInt(0),
-				approvalTokens:       []common.Address{CoinToAddressMap[coin]},
+				approvalTokens:       []common.Address{tokenAddr},
 				approvalTokenAmounts: []*big.Int{size},
 			},
 		},
@@ -846,58 +757,124 @@ func (c *CompoundClient) supplyActionsERC20(size *big.Int, coin coinType) *Actio
 }
 
 // RedeemActions create a Compound redeem action to be executed.
-func (c *CompoundClient) RedeemActions(size *big.Int, coin coinType) *Actions {
-	if coin == ETH {
+// The size is in cTokens.
+func (c *CompoundClient) RedeemActions(amount Size, coin Token) *Actions {
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return failedActions("Compound", "redeem", "coin", err)
+	}
+	size, err := c.client.sizeOf(amount, cTokenAddr)
+	if err != nil {
+		return failedActions("Compound", "redeem", "size", err)
+	}
+	if coin.IsETH() {
 		return c.redeemActionsETH(size, coin)
 	} else {
 		return c.redeemActionsERC20(size, coin)
 	}
 }
 
-func (c *CompoundClient) redeemActionsETH(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hcether.HcetherABI))
+func (c *CompoundClient) redeemActionsETH(size *big.Int, coin Token) *Actions {
+	cETHAddr, err := c.getPoolAddrFromCoin(coin)
 	if err != nil {
-		return nil
+		return failedActions("Compound", "redeem", "coin", err)
 	}
-	data, err := parsed.Pack("redeem", size)
+	data, err := packAction("Compound", hcether.HcetherABI, "redeem", size)
 	if err != nil {
-		return nil
+		return failedActions("Compound", "redeem", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hCEtherAddr),
-				data:         data,
-				ethersNeeded: size,
+				handlerAddr:          c.client.network.Handlers.CEther,
+				data:                 data,
+				ethersNeeded:         big.NewInt(0),
+				approvalTokens:       []common.Address{cETHAddr},
+				approvalTokenAmounts: []*big.Int{size},
 			},
 		},
 	}
 }
 
-func (c *CompoundClient) redeemActionsERC20(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hctoken.HctokenABI))
+func (c *CompoundClient) redeemActionsERC20(size *big.Int, coin Token) *Actions {
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
 	if err != nil {
-		return nil
+		return failedActions("Compound", "redeem", "coin", err)
 	}
-	redeemData, err := parsed.Pack("redeem", CoinToCompoundMap[coin], size)
+	redeemData, err := packAction("Compound", hctoken.HctokenABI, "redeem", cTokenAddr, size)
 	if err != nil {
-		return nil
+		return failedActions("Compound", "redeem", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hCTokenAddr),
+				handlerAddr:          c.client.network.Handlers.CToken,
 				data:                 redeemData,
 				ethersNeeded:         big.NewInt(0),
-				approvalTokens:       []common.Address{CoinToCompoundMap[coin]},
+				approvalTokens:       []common.Address{cTokenAddr},
 				approvalTokenAmounts: []*big.Int{size},
 			},
 		},
 	}
 }
 
+// RedeemUnderlyingActions create a Compound action redeeming `amount` of the underlying `coin`.
+// The cTokens it takes are only known on execution, so all the cTokens of the user are injected,
+// the proxy must be approved for them, and those left are returned at the end of the combo.
+func (c *CompoundClient) RedeemUnderlyingActions(amount Size, coin Token) *Actions {
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return failedActions("Compound", "redeemUnderlying", "coin", err)
+	}
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return failedActions("Compound", "redeemUnderlying", "size", err)
+	}
+
+	handlerAddr := c.client.network.Handlers.CToken
+	var data []byte
+	if coin.IsETH() {
+		handlerAddr = c.client.network.Handlers.CEther
+		data, err = packAction("Compound", hcether.HcetherABI, "redeemUnderlying", size)
+	} else {
+		data, err = packAction("Compound", hctoken.HctokenABI, "redeemUnderlying", cTokenAddr, size)
+	}
+	if err != nil {
+		return failedActions("Compound", "redeemUnderlying", "", err)
+	}
+	return &Actions{
+		Actions: []action{
+			{
+				handlerAddr:          handlerAddr,
+				data:                 data,
+				ethersNeeded:         big.NewInt(0),
+				approvalTokens:       []common.Address{cTokenAddr},
+				approvalTokenAmounts: []*big.Int{math.MaxBig256},
+			},
+		},
+	}
+}
+
 // FlashLoanActions create an action to perform Uniswap flashloan.
-func (c *AaveClient) FlashLoanActions(size *big.Int, coin coinType, actions *Actions) *Actions {
+func (c *AaveClient) FlashLoanActions(amount Size, coin Token, actions *Actions) *Actions {
+	if c.err != nil {
+		return failedActions("Aave", "flashLoan", "", c.err)
+	}
+	if actions == nil {
+		return failedActions("Aave", "flashLoan", "actions", ErrNilActions)
+	}
+	if actions.err != nil {
+		return failedActions("Aave", "flashLoan", "actions", actions.err)
+	}
+	reserve, err := c.client.coinAddr(coin)
+	if err != nil {
+		return failedActions("Aave", "flashLoan", "coin", err)
+	}
+	size, err := c.client.sizeOf(amount, reserve)
+	if err != nil {
+		return failedActions("Aave", "flashLoan", "size", err)
+	}
+
 	handlers := []common.Address{}
 	datas := make([][]byte, 0)
 	totalEthers := big.NewInt(0)
@@ -907,33 +884,32 @@ func (c *AaveClient) FlashLoanActions(size *big.Int, coin coinType, actions *Act
 		totalEthers.Add(totalEthers, actions.Actions[i].ethersNeeded)
 	}
 
-	proxy, err := abi.JSON(strings.NewReader(furucombo.FurucomboABI))
+	payloadData, err := packAction("Furucombo", furucombo.FurucomboABI, "execs", handlers, datas)
 	if err != nil {
-		return nil
+		return failedActions("Furucombo", "execs", "", err)
 	}
-	payloadData, err := proxy.Pack("execs", handlers, datas)
-	if err != nil {
-		return nil
-	}
-	haave, err := abi.JSON(strings.NewReader(haave.HaaveABI))
+	// skip the first 4 bytes to omit the function selector
+	flashLoanData, err := packAction("Aave", haave.HaaveABI, "flashLoan", reserve, size, payloadData[4:])
 	if err != nil {
-		return nil
+		return failedActions("Aave", "flashLoan", "", err)
 	}
-	// skip the first 4 bytes to omit the function selector
-	flashLoanData, err := haave.Pack("flashLoan", CoinToAddressMap[coin], size, payloadData[4:])
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hAaveAddr),
+				handlerAddr:  c.client.network.Handlers.Aave,
 				data:         flashLoanData,
 				ethersNeeded: totalEthers,
+				nested:       actions,
 			},
 		},
 	}
 }
 
-func (c *CompoundClient) getPoolAddrFromCoin(coin coinType) (common.Address, error) {
-	if val, ok := CoinToCompoundMap[coin]; ok {
+func (c *CompoundClient) getPoolAddrFromCoin(coin Token) (common.Address, error) {
+	if val, ok := c.client.network.CompoundMarket(coin); ok {
+		return val, nil
+	}
+	if val, ok := c.client.compoundMarkets.get(coin.Address); ok {
 		return val, nil
 	}
 	return common.Address{}, fmt.Errorf("No corresponding compound pool for token: %v", coin)
@@ -943,146 +919,184 @@ func (c *CompoundClient) getPoolAddrFromCoin(coin coinType) (common.Address, err
 
 // YearnClient is an instance of Compound protocol.
 type YearnClient struct {
-	client       *DefiClient
-	tokenToVault map[common.Address]common.Address
+	client *DefiClient
 }
 
 // Yearn returns a Yearn client.
 func (c *DefiClient) Yearn() *YearnClient {
 	yearnClient := new(YearnClient)
 	yearnClient.client = c
+	return yearnClient
+}
 
-	yregistry, err := yregistry.NewYregistry(common.HexToAddress(yRegistryAddr), c.conn)
-	if err != nil {
-		return nil
+// yearnVaultsCache keeps the vaults of the Yearn registry by token, read on first use.
+type yearnVaultsCache struct {
+	mu     sync.Mutex
+	vaults map[common.Address]common.Address
+}
+
+// vaults returns the vaults of the Yearn registry by token. They are read once per client, the
+// vaults added to the registry later aren't seen.
+func (c *YearnClient) vaults(ctx context.Context) (map[common.Address]common.Address, error) {
+	cache := &c.client.yearnVaults
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.vaults != nil {
+		return cache.vaults, nil
 	}
 
-	vaults, err := yregistry.GetVaults(nil)
+	yregistry, err := yregistry.NewYregistry(c.client.network.Contracts.YearnRegistry, c.client.conn)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-
-	vaultInfos, err := yregistry.GetVaultsInfo(nil)
+	opts := c.client.callOpts(ctx, nil)
+	vaults, err := yregistry.GetVaults(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Yearn vaults: %w", err)
+	}
+	vaultInfos, err := yregistry.GetVaultsInfo(opts)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("Error getting Yearn vaults info: %w", err)
 	}
 
-	yearnClient.tokenToVault = make(map[common.Address]common.Address)
-	for i := 0; i < len(vaults); i++ {
-		yearnClient.tokenToVault[vaultInfos.TokenArray[i]] = vaults[i]
+	tokenToVault := make(map[common.Address]common.Address)
+	for i := 0; i < len(vaults) && i < len(vaultInfos.TokenArray); i++ {
+		tokenToVault[vaultInfos.TokenArray[i]] = vaults[i]
 	}
+	cache.vaults = tokenToVault
+	return tokenToVault, nil
+}
 
-	return yearnClient
+// vaultOf returns the vault accepting the given coin.
+func (c *YearnClient) vaultOf(ctx context.Context, coin Token) (common.Address, error) {
+	tokenAddr, err := c.client.coinAddr(coin)
+	if err != nil {
+		return common.Address{}, err
+	}
+	vaults, err := c.vaults(ctx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	vaultAddr, ok := vaults[tokenAddr]
+	if !ok {
+		return common.Address{}, fmt.Errorf("No corresponding vault found for: %v ", coin)
+	}
+	return vaultAddr, nil
 }
 
-func (c *YearnClient) addLiquidity(size *big.Int, coin coinType) error {
+func (c *YearnClient) addLiquidity(ctx context.Context, size *big.Int, coin Token) error {
 	var (
 		tx  *types.Transaction
 		err error
 	)
-	opts := &bind.TransactOpts{
-		From:     c.client.opts.From,
-		Signer:   c.client.opts.Signer,
-		GasLimit: 500000,
-		GasPrice: big.NewInt(20000000000),
-	}
 
-	if coin == ETH {
-		weth, err := yweth.NewYweth(common.HexToAddress(yETHVaultAddr), c.client.conn)
+	if coin.IsETH() {
+		weth, err := yweth.NewYweth(c.client.network.Contracts.YearnETHVault, c.client.conn)
 		if err != nil {
 			return fmt.Errorf("Error getting weth contract")
 		}
-		opts.Value = size
+		opts, err := c.client.transactOpts(ctx, size)
+		if err != nil {
+			return err
+		}
 		tx, err = weth.DepositETH(opts)
-	} else if coin != ETH {
-		tokenAddr := CoinToAddressMap[coin]
-		vaultAddr, ok := c.tokenToVault[tokenAddr]
-		if !ok {
-			return fmt.Errorf("No corresponding vault found for: %v ", coin)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
+	} else {
+		vaultAddr, err := c.vaultOf(ctx, coin)
+		if err != nil {
+			return err
 		}
-		err = Approve(c.client, coin, vaultAddr, size)
 		yvault, err := yvault.NewYvault(vaultAddr, c.client.conn)
 		if err != nil {
-			return fmt.Errorf("Error getting weth contract")
+			return fmt.Errorf("Error getting vault contract")
+		}
+		if err = Approve(ctx, c.client, coin, vaultAddr, size); err != nil {
+			return err
+		}
+		opts, err := c.client.transactOpts(ctx, nil)
+		if err != nil {
+			return err
 		}
-		opts.Value = size
 		tx, err = yvault.Deposit(opts, size)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
 	}
 
-	if err != nil {
-		fmt.Printf("Error deposit into vault: %v", err)
-		return err
-	}
-
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-
-	return nil
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
-func (c *YearnClient) removeLiquidity(size *big.Int, coin coinType) error {
+func (c *YearnClient) removeLiquidity(ctx context.Context, size *big.Int, coin Token) error {
 	var (
 		tx  *types.Transaction
 		err error
 	)
-	opts := &bind.TransactOpts{
-		From:     c.client.opts.From,
-		Signer:   c.client.opts.Signer,
-		GasLimit: 500000,
-		GasPrice: big.NewInt(20000000000),
-	}
 
-	if coin == ETH {
-		weth, err := yweth.NewYweth(common.HexToAddress(yETHVaultAddr), c.client.conn)
+	if coin.IsETH() {
+		weth, err := yweth.NewYweth(c.client.network.Contracts.YearnETHVault, c.client.conn)
 		if err != nil {
 			return fmt.Errorf("Error getting weth contract")
 		}
+		opts, err := c.client.transactOpts(ctx, nil)
+		if err != nil {
+			return err
+		}
 		tx, err = weth.WithdrawETH(opts, size)
-	} else if coin != ETH {
-		tokenAddr := CoinToAddressMap[coin]
-		vaultAddr, ok := c.tokenToVault[tokenAddr]
-		if !ok {
-			return fmt.Errorf("No corresponding vault found for: %v ", coin)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
+	} else {
+		vaultAddr, err := c.vaultOf(ctx, coin)
+		if err != nil {
+			return err
 		}
 		yvault, err := yvault.NewYvault(vaultAddr, c.client.conn)
 		if err != nil {
-			return fmt.Errorf("Error getting weth contract")
+			return fmt.Errorf("Error getting vault contract")
+		}
+		opts, err := c.client.transactOpts(ctx, nil)
+		if err != nil {
+			return err
 		}
-		opts.Value = size
 		tx, err = yvault.Withdraw(opts, size)
+		if err != nil {
+			c.client.releaseNonce(opts)
+			return err
+		}
 	}
 
-	if err != nil {
-		fmt.Printf("Error withdraw from vault: %v", err)
-		return err
-	}
-
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-
-	return nil
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
 // AddLiquidityActions creates an add liquidity action to Yearn.
-func (c *YearnClient) AddLiquidityActions(size *big.Int, coin coinType) *Actions {
-	if coin == ETH {
+func (c *YearnClient) AddLiquidityActions(ctx context.Context, amount Size, coin Token) *Actions {
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return failedActions("Yearn", "deposit", "size", err)
+	}
+	if coin.IsETH() {
 		return c.addLiquidityActionsETH(size, coin)
 	} else {
-		return c.addLiquidityActionsERC20(size, coin)
+		return c.addLiquidityActionsERC20(ctx, size, coin)
 	}
 }
 
-func (c *YearnClient) addLiquidityActionsETH(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hyearn.HyearnABI))
-	if err != nil {
-		return nil
-	}
-	data, err := parsed.Pack("depositETH", size, common.HexToAddress(yETHVaultAddr))
+func (c *YearnClient) addLiquidityActionsETH(size *big.Int, coin Token) *Actions {
+	data, err := packAction("Yearn", hyearn.HyearnABI, "depositETH", size, c.client.network.Contracts.YearnETHVault)
 	if err != nil {
-		return nil
+		return failedActions("Yearn", "depositETH", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hYearnAddr),
+				handlerAddr:  c.client.network.Handlers.Yearn,
 				data:         data,
 				ethersNeeded: size,
 			},
@@ -1090,27 +1104,22 @@ func (c *YearnClient) addLiquidityActionsETH(size *big.Int, coin coinType) *Acti
 	}
 }
 
-func (c *YearnClient) addLiquidityActionsERC20(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hyearn.HyearnABI))
+func (c *YearnClient) addLiquidityActionsERC20(ctx context.Context, size *big.Int, coin Token) *Actions {
+	vaultAddr, err := c.vaultOf(ctx, coin)
 	if err != nil {
-		return nil
+		return failedActions("Yearn", "deposit", "coin", err)
 	}
-	tokenAddr := CoinToAddressMap[coin]
-	vaultAddr, ok := c.tokenToVault[tokenAddr]
-	if !ok {
-		return nil
-	}
-	data, err := parsed.Pack("deposit", vaultAddr, size)
+	data, err := packAction("Yearn", hyearn.HyearnABI, "deposit", vaultAddr, size)
 	if err != nil {
-		return nil
+		return failedActions("Yearn", "deposit", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hYearnAddr),
+				handlerAddr:          c.client.network.Handlers.Yearn,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
-				approvalTokens:       []common.Address{CoinToAddressMap[coin]},
+				approvalTokens:       []common.Address{c.client.erc20Address(coin)},
 				approvalTokenAmounts: []*big.Int{size},
 			},
 		},
@@ -1118,51 +1127,57 @@ func (c *YearnClient) addLiquidityActionsERC20(size *big.Int, coin coinType) *Ac
 }
 
 // RemoveLiquidityActions creates a remove liquidity action to Yearn.
-func (c *YearnClient) RemoveLiquidityActions(size *big.Int, coin coinType) *Actions {
-	if coin == ETH {
+// The size is in vault shares.
+func (c *YearnClient) RemoveLiquidityActions(ctx context.Context, amount Size, coin Token) *Actions {
+	vaultAddr := c.client.network.Contracts.YearnETHVault
+	if !coin.IsETH() {
+		var err error
+		if vaultAddr, err = c.vaultOf(ctx, coin); err != nil {
+			return failedActions("Yearn", "withdraw", "coin", err)
+		}
+	}
+	size, err := c.client.sizeOf(amount, vaultAddr)
+	if err != nil {
+		return failedActions("Yearn", "withdraw", "size", err)
+	}
+	if coin.IsETH() {
 		return c.removeLiquidityActionsETH(size, coin)
 	} else {
-		return c.removeLiquidityActionsERC20(size, coin)
+		return c.removeLiquidityActionsERC20(size, vaultAddr)
 	}
 }
 
-func (c *YearnClient) removeLiquidityActionsETH(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hyearn.HyearnABI))
+func (c *YearnClient) removeLiquidityActionsETH(size *big.Int, coin Token) *Actions {
+	data, err := packAction("Yearn", hyearn.HyearnABI, "withdrawETH", c.client.network.Contracts.YearnETHVault, size)
 	if err != nil {
-		return nil
-	}
-	data, err := parsed.Pack("withdrawETH", common.HexToAddress(yETHVaultAddr), size)
-	if err != nil {
-		return nil
+		return failedActions("Yearn", "withdrawETH", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hYearnAddr),
+				handlerAddr:          c.client.network.Handlers.Yearn,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
-				approvalTokens:       []common.Address{common.HexToAddress(yETHVaultAddr)},
+				approvalTokens:       []common.Address{c.client.network.Contracts.YearnETHVault},
 				approvalTokenAmounts: []*big.Int{size},
 			},
 		},
 	}
 }
 
-func (c *YearnClient) removeLiquidityActionsERC20(size *big.Int, coin coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hyearn.HyearnABI))
+func (c *YearnClient) removeLiquidityActionsERC20(size *big.Int, vaultAddr common.Address) *Actions {
+	data, err := packAction("Yearn", hyearn.HyearnABI, "withdraw", vaultAddr, size)
 	if err != nil {
-		return nil
-	}
-	data, err := parsed.Pack("withdraw", common.HexToAddress(yETHVaultAddr), size)
-	if err != nil {
-		return nil
+		return failedActions("Yearn", "withdraw", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hYearnAddr),
-				data:         data,
-				ethersNeeded: big.NewInt(0),
+				handlerAddr:          c.client.network.Handlers.Yearn,
+				data:                 data,
+				ethersNeeded:         big.NewInt(0),
+				approvalTokens:       []common.Address{vaultAddr},
+				approvalTokenAmounts: []*big.Int{size},
 			},
 		},
 	}
@@ -1174,6 +1189,7 @@ func (c *YearnClient) removeLiquidityActionsERC20(size *big.Int, coin coinType)
 type AaveClient struct {
 	client      *DefiClient
 	lendingPool *lendingpool.Lendingpool
+	err         error
 }
 
 // Aave returns an Aave client which contains functions that you can use to interact with Aave.
@@ -1181,37 +1197,46 @@ func (c *DefiClient) Aave() *AaveClient {
 	aaveClient := new(AaveClient)
 	aaveClient.client = c
 
-	lendingpool, err := lendingpool.NewLendingpool(common.HexToAddress(aaveLendingPoolAddr), c.conn)
+	lendingpool, err := lendingpool.NewLendingpool(c.network.Contracts.AaveLendingPool, c.conn)
 	if err != nil {
-		return nil
+		aaveClient.err = err
+		return aaveClient
 	}
 	aaveClient.lendingPool = lendingpool
 	return aaveClient
 }
 
 // Lend lend to the Aave lending pool.
-func (c *AaveClient) Lend(size *big.Int, coin coinType) error {
-	opts := &bind.TransactOpts{
-		From:     c.client.opts.From,
-		Signer:   c.client.opts.Signer,
-		GasLimit: 500000,
-		GasPrice: big.NewInt(20000000000),
+func (c *AaveClient) Lend(ctx context.Context, amount Size, coin Token) error {
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(coin))
+	if err != nil {
+		return err
 	}
-
-	if coin != ETH {
-		Approve(c.client, coin, common.HexToAddress(aaveLendingPoolCoreAddr), size)
+	if size == nil {
+		return ErrNilAmount
+	}
+	if !coin.IsETH() {
+		err = Approve(ctx, c.client, coin, c.client.network.Contracts.AaveLendingPoolCore, size)
+		if err != nil {
+			return err
+		}
+	}
+	opts, err := c.client.transactOpts(ctx, nil)
+	if err != nil {
+		return err
 	}
 
-	tx, err := c.lendingPool.Deposit(opts, CoinToAddressMap[coin], size, 0)
+	tx, err := c.lendingPool.Deposit(opts, c.client.erc20Address(coin), size, 0)
 	if err != nil {
+		c.client.releaseNonce(opts)
 		return err
 	}
-	bind.WaitMined(context.Background(), c.client.conn, tx)
-	return nil
+	_, err = c.client.waitMined(ctx, tx)
+	return err
 }
 
 // Borrow borrow money from lending pool.
-func (c *AaveClient) Borrow(size *big.Int, coin coinType, interestRate rateModel) error {
+func (c *AaveClient) Borrow(ctx context.Context, amount Size, coin Token, interestRate rateModel) error {
 	return nil
 }
 
@@ -1229,9 +1254,9 @@ type ReserveData struct {
 	UsageAsCollateralEnabled bool
 }
 
-// GetUserReserveData get the reserve data.
-func (c *AaveClient) GetUserReserveData(addr common.Address, user common.Address) (ReserveData, error) {
-	data, err := c.lendingPool.GetUserReserveData(nil, addr, user)
+// GetUserReserveData get the reserve data at `blockNum`, nil means the latest block.
+func (c *AaveClient) GetUserReserveData(ctx context.Context, addr common.Address, user common.Address, blockNum *big.Int) (ReserveData, error) {
+	data, err := c.lendingPool.GetUserReserveData(c.client.callOpts(ctx, blockNum), addr, user)
 	if err != nil {
 		return ReserveData{}, err
 	}
@@ -1252,43 +1277,57 @@ func (c *DefiClient) Kyberswap() *KyberswapClient {
 	return kyberClient
 }
 
-// SwapActions creates a swap action.
-func (c *KyberswapClient) SwapActions(size *big.Int, baseCurrency coinType, quoteCurrency coinType) *Actions {
+// SwapActions creates a swap action, `options` protect it against slippage.
+func (c *KyberswapClient) SwapActions(ctx context.Context, amount Size, baseCurrency Token, quoteCurrency Token, options ...SwapOption) *Actions {
 	var (
 		data         []byte
 		err          error
 		ethersNeeded *big.Int = big.NewInt(0)
 	)
+	size, err := c.client.sizeOf(amount, c.client.erc20Address(quoteCurrency))
+	if err != nil {
+		return failedActions("Kyberswap", "swap", "size", err)
+	}
 
-	parsed, err := abi.JSON(strings.NewReader(hkyber.HkyberABI))
+	baseAddr, err := c.client.coinAddr(baseCurrency)
+	if err != nil {
+		return failedActions("Kyberswap", "swap", "baseCurrency", err)
+	}
+	quoteAddr, err := c.client.coinAddr(quoteCurrency)
 	if err != nil {
-		return nil
+		return failedActions("Kyberswap", "swap", "quoteCurrency", err)
+	}
+	minRate, err := c.kyberMinRate(ctx, newSwapOptions(options), size, quoteCurrency, baseCurrency)
+	if err != nil {
+		return failedActions("Kyberswap", "swap", "minRate", err)
 	}
 
-	if quoteCurrency == ETH {
+	// The quote currency is sold for the base currency.
+	if quoteCurrency.IsETH() {
 		ethersNeeded = size
-		data, err = parsed.Pack("swapEtherToToken", size, CoinToAddressMap[baseCurrency], big.NewInt(0))
+		data, err = packAction("Kyberswap", hkyber.HkyberABI, "swapEtherToToken", size, baseAddr, minRate)
 	} else {
-		if baseCurrency == ETH {
-			data, err = parsed.Pack("swapTokenToEther", CoinToAddressMap[baseCurrency], size, big.NewInt(0))
+		if baseCurrency.IsETH() {
+			data, err = packAction("Kyberswap", hkyber.HkyberABI, "swapTokenToEther", quoteAddr, size, minRate)
 		} else {
-			data, err = parsed.Pack("swapTokenToToken", CoinToAddressMap[baseCurrency], size, CoinToAddressMap[quoteCurrency], big.NewInt(0))
+			data, err = packAction("Kyberswap", hkyber.HkyberABI, "swapTokenToToken", quoteAddr, size, baseAddr, minRate)
 		}
 	}
 
 	if err != nil {
-		return nil
+		return failedActions("Kyberswap", "swap", "", err)
 	}
 
-	return &Actions{
-		Actions: []action{
-			{
-				handlerAddr:  common.HexToAddress(hKyberAddr),
-				data:         data,
-				ethersNeeded: ethersNeeded,
-			},
-		},
+	swap := action{
+		handlerAddr:  c.client.network.Handlers.Kyber,
+		data:         data,
+		ethersNeeded: ethersNeeded,
 	}
+	if !quoteCurrency.IsETH() {
+		swap.approvalTokens = []common.Address{quoteAddr}
+		swap.approvalTokenAmounts = []*big.Int{size}
+	}
+	return &Actions{Actions: []action{swap}}
 
 }
 
@@ -1306,39 +1345,15 @@ func (c *DefiClient) Sushiswap() *SushiswapClient {
 	return sushiswapClient
 }
 
-// SwapActions create a new swap action.
-func (c *SushiswapClient) SwapActions(size *big.Int, baseCurrency coinType, quoteCurrency coinType) *Actions {
-	var callData []byte
-	var ethersNeeded = big.NewInt(0)
-	var approvalTokens []common.Address = nil
-	var approvalTokenAmounts []*big.Int = nil
-
-	if quoteCurrency == ETH {
-		ethersNeeded = size
-		callData = swapETHToTokenData(size, baseCurrency)
-	} else {
-		if baseCurrency == ETH {
-			approvalTokens = []common.Address{CoinToAddressMap[quoteCurrency]}
-			approvalTokenAmounts = []*big.Int{size}
-			callData = swapTokenToETHData(size, quoteCurrency)
-		} else {
-			approvalTokens = []common.Address{CoinToAddressMap[quoteCurrency]}
-			approvalTokenAmounts = []*big.Int{size}
-			callData = swapTokenToTokenData(size, baseCurrency, quoteCurrency)
-		}
+// SwapActions create a new swap action, `options` protect it against slippage.
+func (c *SushiswapClient) SwapActions(ctx context.Context, amount Size, baseCurrency Token, quoteCurrency Token, options ...SwapOption) *Actions {
+	if _, err := c.client.coinAddr(baseCurrency); err != nil {
+		return failedActions("Sushiswap", "swap", "baseCurrency", err)
 	}
-
-	return &Actions{
-		Actions: []action{
-			{
-				handlerAddr:          common.HexToAddress(hSushiswapAddr),
-				data:                 callData,
-				ethersNeeded:         ethersNeeded,
-				approvalTokens:       approvalTokens,
-				approvalTokenAmounts: approvalTokenAmounts,
-			},
-		},
+	if _, err := c.client.coinAddr(quoteCurrency); err != nil {
+		return failedActions("Sushiswap", "swap", "quoteCurrency", err)
 	}
+	return c.SwapPathActions(ctx, amount, c.client.swapPath(baseCurrency, quoteCurrency), options...)
 }
 
 // Curve-------------------------------------------------------------------------
@@ -1358,22 +1373,24 @@ func (c *DefiClient) Curve() *CurveClient {
 // ExchangeActions creates a Curve exchange action to swap from one stable coin to another.
 func (c *CurveClient) ExchangeActions(
 	handler common.Address, token1Addr common.Address, token2Addr common.Address,
-	i *big.Int, j *big.Int, dx *big.Int, minDy *big.Int) *Actions {
+	i *big.Int, j *big.Int, dxSize Size, minDySize Size) *Actions {
 
-	parsed, err := abi.JSON(strings.NewReader(hcurve.HcurveABI))
+	dx, err := c.client.sizeOf(dxSize, token1Addr)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "exchange", "dx", err)
 	}
-
-	data, err := parsed.Pack("exchange", handler, token1Addr, token2Addr, i, j, dx, minDy)
-
+	minDy, err := c.client.sizeOf(minDySize, token2Addr)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "exchange", "minDy", err)
+	}
+	data, err := packAction("Curve", hcurve.HcurveABI, "exchange", handler, token1Addr, token2Addr, i, j, dx, minDy)
+	if err != nil {
+		return failedActions("Curve", "exchange", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hCurveAddr),
+				handlerAddr:          c.client.network.Handlers.Curve,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
 				approvalTokens:       []common.Address{token1Addr},
@@ -1391,21 +1408,24 @@ func (c *CurveClient) ExchangeActions(
 // `j` is the index of the output token in the pool.
 // `dx` is the amount of the input token that you want to swap
 // `minDy` is the minimum amount of the output token that you want to receive.
-func (c *CurveClient) ExchangeUnderlyingActions(handler common.Address, token1Addr common.Address, token2Addr common.Address, i *big.Int, j *big.Int, dx *big.Int, minDy *big.Int) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hcurve.HcurveABI))
+func (c *CurveClient) ExchangeUnderlyingActions(handler common.Address, token1Addr common.Address, token2Addr common.Address, i *big.Int, j *big.Int, dxSize Size, minDySize Size) *Actions {
+	dx, err := c.client.sizeOf(dxSize, token1Addr)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "exchangeUnderlying", "dx", err)
 	}
-
-	data, err := parsed.Pack("exchangeUnderlying", handler, token1Addr, token2Addr, i, j, dx, minDy)
+	minDy, err := c.client.sizeOf(minDySize, token2Addr)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "exchangeUnderlying", "minDy", err)
+	}
+	data, err := packAction("Curve", hcurve.HcurveABI, "exchangeUnderlying", handler, token1Addr, token2Addr, i, j, dx, minDy)
+	if err != nil {
+		return failedActions("Curve", "exchangeUnderlying", "", err)
 	}
 
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hCurveAddr),
+				handlerAddr:          c.client.network.Handlers.Curve,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
 				approvalTokens:       []common.Address{token1Addr},
@@ -1420,25 +1440,39 @@ func (c *CurveClient) ExchangeUnderlyingActions(handler common.Address, token1Ad
 // `pool` is the address of the pool token, e.g. bCRV token or 3CRV token.
 // `tokens` is the addresses of the tokens that is in the pool.
 // `amounts` is how much amount of each tokens you want to deposit.
-// `minAmount` is the minimum amount of pool token that you want to get back as a result.
+// `minSize` is the minimum amount of pool token that you want to get back as a result.
 func (c *CurveClient) AddLiquidityActions(
 	handler common.Address, pool common.Address, tokens []common.Address,
-	amounts []*big.Int, minAmount *big.Int) *Actions {
+	amountSizes []Size, minSize Size) *Actions {
 
-	parsed, err := abi.JSON(strings.NewReader(hcurve.HcurveABI))
+	if len(tokens) != len(amountSizes) {
+		return failedActions("Curve", "addLiquidity", "amounts",
+			fmt.Errorf("got %d amounts for %d tokens", len(amountSizes), len(tokens)))
+	}
+	amounts := make([]*big.Int, len(amountSizes))
+	for i, amountSize := range amountSizes {
+		amount, err := c.client.sizeOf(amountSize, tokens[i])
+		if err != nil {
+			return failedActions("Curve", "addLiquidity", "amounts", err)
+		}
+		if amount == nil {
+			return failedActions("Curve", "addLiquidity", "amounts", ErrNilAmount)
+		}
+		amounts[i] = amount
+	}
+	minAmount, err := c.client.sizeOf(minSize, pool)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "addLiquidity", "minAmount", err)
 	}
 
-	data, err := parsed.Pack("addLiquidity", handler, pool, tokens, amounts, minAmount)
-
+	data, err := packAction("Curve", hcurve.HcurveABI, "addLiquidity", handler, pool, tokens, amounts, minAmount)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "addLiquidity", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hCurveAddr),
+				handlerAddr:          c.client.network.Handlers.Curve,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
 				approvalTokens:       tokens,
@@ -1456,22 +1490,24 @@ func (c *CurveClient) AddLiquidityActions(
 // `i` is the index of the token in the given pool.
 // `minAmount` is the minimum amount of the underlying token that you want to get back as a result.
 func (c *CurveClient) RemoveLiquidityActions(
-	handler common.Address, pool common.Address, tokenI common.Address, tokenAmount *big.Int, i *big.Int, minAmount *big.Int,
+	handler common.Address, pool common.Address, tokenI common.Address, tokenSize Size, i *big.Int, minSize Size,
 ) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hcurve.HcurveABI))
+	tokenAmount, err := c.client.sizeOf(tokenSize, pool)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "removeLiquidityOneCoin", "tokenAmount", err)
 	}
-
-	data, err := parsed.Pack("removeLiquidityOneCoin", handler, pool, tokenI, tokenAmount, i, minAmount)
-
+	minAmount, err := c.client.sizeOf(minSize, tokenI)
 	if err != nil {
-		return nil
+		return failedActions("Curve", "removeLiquidityOneCoin", "minAmount", err)
+	}
+	data, err := packAction("Curve", hcurve.HcurveABI, "removeLiquidityOneCoin", handler, pool, tokenI, tokenAmount, i, minAmount)
+	if err != nil {
+		return failedActions("Curve", "removeLiquidityOneCoin", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hCurveAddr),
+				handlerAddr:          c.client.network.Handlers.Curve,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
 				approvalTokens:       []common.Address{pool},
@@ -1496,8 +1532,16 @@ func (c *DefiClient) Maker() *MakerClient {
 }
 
 // GenerateDaiAction generate an action to create a vault and get some DAI
-func (c *MakerClient) GenerateDaiAction(collateralAmount *big.Int, daiAmount *big.Int, collateralType coinType) *Actions {
-	if collateralType == ETH {
+func (c *MakerClient) GenerateDaiAction(collateralSize Size, daiSize Size, collateralType Token) *Actions {
+	collateralAmount, err := c.client.sizeOf(collateralSize, c.client.erc20Address(collateralType))
+	if err != nil {
+		return failedActions("Maker", "openLockGemAndDraw", "collateralAmount", err)
+	}
+	daiAmount, err := c.client.sizeOf(daiSize, c.dai().Address)
+	if err != nil {
+		return failedActions("Maker", "openLockGemAndDraw", "daiAmount", err)
+	}
+	if collateralType.IsETH() {
 		return c.generateDaiActionETH(collateralAmount, daiAmount)
 	} else {
 		return c.generateDaiActionErc20(collateralAmount, daiAmount, collateralType)
@@ -1505,21 +1549,25 @@ func (c *MakerClient) GenerateDaiAction(collateralAmount *big.Int, daiAmount *bi
 }
 
 func (c *MakerClient) generateDaiActionETH(collateralAmount *big.Int, daiAmount *big.Int) *Actions {
-
-	parsed, err := abi.JSON(strings.NewReader(hmaker.HmakerABI))
+	ethJoin, ilk, err := c.makerCollateral(ETH)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "openLockETHAndDraw", "collateralType", err)
+	}
+	daiJoin, err := c.daiJoin()
+	if err != nil {
+		return failedActions("Maker", "openLockETHAndDraw", "", err)
 	}
 
-	data, err := parsed.Pack("openLockETHAndDraw", collateralAmount, CoinToJoinMap[ETH], CoinToJoinMap[DAI], CoinToIlkMap[ETH], daiAmount)
-
+	data, err := packAction(
+		"Maker", hmaker.HmakerABI, "openLockETHAndDraw",
+		collateralAmount, ethJoin, daiJoin, ilk, daiAmount)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "openLockETHAndDraw", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hMakerDaoAddr),
+				handlerAddr:  c.client.network.Handlers.Maker,
 				data:         data,
 				ethersNeeded: collateralAmount,
 			},
@@ -1527,24 +1575,29 @@ func (c *MakerClient) generateDaiActionETH(collateralAmount *big.Int, daiAmount
 	}
 }
 
-func (c *MakerClient) generateDaiActionErc20(collateralAmount *big.Int, daiAmount *big.Int, collateralType coinType) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hmaker.HmakerABI))
+func (c *MakerClient) generateDaiActionErc20(collateralAmount *big.Int, daiAmount *big.Int, collateralType Token) *Actions {
+	gemJoin, ilk, err := c.makerCollateral(collateralType)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "openLockGemAndDraw", "collateralType", err)
+	}
+	daiJoin, err := c.daiJoin()
+	if err != nil {
+		return failedActions("Maker", "openLockGemAndDraw", "", err)
 	}
 
-	data, err := parsed.Pack("openLockGemAndDraw", CoinToJoinMap[collateralType], CoinToJoinMap[DAI], CoinToIlkMap[collateralType], collateralAmount, daiAmount)
-
+	data, err := packAction(
+		"Maker", hmaker.HmakerABI, "openLockGemAndDraw",
+		gemJoin, daiJoin, ilk, collateralAmount, daiAmount)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "openLockGemAndDraw", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hMakerDaoAddr),
+				handlerAddr:          c.client.network.Handlers.Maker,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
-				approvalTokens:       []common.Address{CoinToAddressMap[collateralType]},
+				approvalTokens:       []common.Address{c.client.erc20Address(collateralType)},
 				approvalTokenAmounts: []*big.Int{collateralAmount},
 			},
 		},
@@ -1552,29 +1605,32 @@ func (c *MakerClient) generateDaiActionErc20(collateralAmount *big.Int, daiAmoun
 }
 
 // DepositCollateralActions deposits additional collateral to the given vault.
-func (c *MakerClient) DepositCollateralActions(collateralAmount *big.Int, collateralType coinType, cdp *big.Int) *Actions {
-	if collateralType == ETH {
+func (c *MakerClient) DepositCollateralActions(collateralSize Size, collateralType Token, cdp *big.Int) *Actions {
+	collateralAmount, err := c.client.sizeOf(collateralSize, c.client.erc20Address(collateralType))
+	if err != nil {
+		return failedActions("Maker", "safeLockGem", "collateralAmount", err)
+	}
+	if collateralType.IsETH() {
 		return c.depositETHActions(collateralAmount, collateralType, cdp)
 	} else {
 		return c.depositERC20Actions(collateralAmount, collateralType, cdp)
 	}
 }
 
-func (c *MakerClient) depositETHActions(collateralAmount *big.Int, collateralType coinType, cdp *big.Int) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hmaker.HmakerABI))
+func (c *MakerClient) depositETHActions(collateralAmount *big.Int, collateralType Token, cdp *big.Int) *Actions {
+	ethJoin, _, err := c.makerCollateral(ETH)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "safeLockETH", "collateralType", err)
 	}
 
-	data, err := parsed.Pack("safeLockETH", collateralAmount, CoinToJoinMap[ETH], cdp)
-
+	data, err := packAction("Maker", hmaker.HmakerABI, "safeLockETH", collateralAmount, ethJoin, cdp)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "safeLockETH", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hMakerDaoAddr),
+				handlerAddr:  c.client.network.Handlers.Maker,
 				data:         data,
 				ethersNeeded: collateralAmount,
 			},
@@ -1582,24 +1638,23 @@ func (c *MakerClient) depositETHActions(collateralAmount *big.Int, collateralTyp
 	}
 }
 
-func (c *MakerClient) depositERC20Actions(collateralAmount *big.Int, collateralType coinType, cdp *big.Int) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hmaker.HmakerABI))
+func (c *MakerClient) depositERC20Actions(collateralAmount *big.Int, collateralType Token, cdp *big.Int) *Actions {
+	gemJoin, _, err := c.makerCollateral(collateralType)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "safeLockGem", "collateralType", err)
 	}
 
-	data, err := parsed.Pack("safeLockGem", CoinToJoinMap[collateralType], cdp, collateralAmount)
-
+	data, err := packAction("Maker", hmaker.HmakerABI, "safeLockGem", gemJoin, cdp, collateralAmount)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "safeLockGem", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:          common.HexToAddress(hMakerDaoAddr),
+				handlerAddr:          c.client.network.Handlers.Maker,
 				data:                 data,
 				ethersNeeded:         big.NewInt(0),
-				approvalTokens:       []common.Address{CoinToAddressMap[collateralType]},
+				approvalTokens:       []common.Address{c.client.erc20Address(collateralType)},
 				approvalTokenAmounts: []*big.Int{collateralAmount},
 			},
 		},
@@ -1607,21 +1662,24 @@ func (c *MakerClient) depositERC20Actions(collateralAmount *big.Int, collateralT
 }
 
 // WipeAction creates a wipe action to decrease debt for th given cdp/vault.
-func (c *MakerClient) WipeAction(daiAmount *big.Int, cdp *big.Int) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hmaker.HmakerABI))
+func (c *MakerClient) WipeAction(daiSize Size, cdp *big.Int) *Actions {
+	daiAmount, err := c.client.sizeOf(daiSize, c.dai().Address)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "wipe", "daiAmount", err)
+	}
+	daiJoin, err := c.daiJoin()
+	if err != nil {
+		return failedActions("Maker", "wipe", "", err)
 	}
 
-	data, err := parsed.Pack("wipe", CoinToJoinMap[DAI], cdp, daiAmount)
-
+	data, err := packAction("Maker", hmaker.HmakerABI, "wipe", daiJoin, cdp, daiAmount)
 	if err != nil {
-		return nil
+		return failedActions("Maker", "wipe", "", err)
 	}
 	return &Actions{
 		Actions: []action{
 			{
-				handlerAddr:  common.HexToAddress(hMakerDaoAddr),
+				handlerAddr:  c.client.network.Handlers.Maker,
 				data:         data,
 				ethersNeeded: big.NewInt(0),
 			},
@@ -1629,6 +1687,32 @@ func (c *MakerClient) WipeAction(daiAmount *big.Int, cdp *big.Int) *Actions {
 	}
 }
 
+// makerCollateral returns the Join adapter and the Ilk of the given collateral.
+func (c *MakerClient) makerCollateral(collateralType Token) (common.Address, [32]byte, error) {
+	join, ilk, ok := c.client.network.MakerCollateral(collateralType)
+	if !ok {
+		return common.Address{}, [32]byte{}, fmt.Errorf("%w: no Maker collateral for %v", ErrUnsupportedCoin, collateralType)
+	}
+	return join, ilk, nil
+}
+
+// dai returns the DAI token of the network.
+func (c *MakerClient) dai() Token {
+	if dai, ok := c.client.tokens.BySymbol(DAI.Symbol); ok {
+		return dai
+	}
+	return DAI
+}
+
+// daiJoin returns the Join adapter of DAI.
+func (c *MakerClient) daiJoin() (common.Address, error) {
+	join, ok := c.client.network.Contracts.MakerJoins[DAI.Symbol]
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: no Maker join for DAI on %s", ErrUnsupportedCoin, c.client.network.Name)
+	}
+	return join, nil
+}
+
 // Balancer-----------------------------------------------------------
 
 // BalancerClient is an instance of Balancer protocol.
@@ -1643,24 +1727,37 @@ func (c *DefiClient) Balancer() *BalancerClient {
 	return balancerClient
 }
 
-// Swap swaps on Balancer Exchange
-func (c *BalancerClient) Swap(inputCoin coinType, outputCoin coinType, inputAmount *big.Int) *Actions {
-	parsed, err := abi.JSON(strings.NewReader(hbalancer_exchange.HbalancerExchangeABI))
+// Swap swaps on Balancer Exchange, `options` protect the swap against slippage.
+func (c *BalancerClient) Swap(ctx context.Context, inputCoin Token, outputCoin Token, inputSize Size, options ...SwapOption) *Actions {
+	inputAddr, err := c.client.coinAddr(inputCoin)
 	if err != nil {
-		return nil
+		return failedActions("Balancer", "smartSwapExactIn", "inputCoin", err)
+	}
+	inputAmount, err := c.client.sizeOf(inputSize, inputAddr)
+	if err != nil {
+		return failedActions("Balancer", "smartSwapExactIn", "totalAmountIn", err)
+	}
+	outputAddr, err := c.client.coinAddr(outputCoin)
+	if err != nil {
+		return failedActions("Balancer", "smartSwapExactIn", "outputCoin", err)
+	}
+	minOut, err := c.client.minOutput(ctx, newSwapOptions(options), outputAddr, c.balancerQuote(inputAddr, outputAddr, inputAmount))
+	if err != nil {
+		return failedActions("Balancer", "smartSwapExactIn", "minTotalAmountOut", err)
 	}
 
-	data, err := parsed.Pack("smartSwapExactIn", CoinToAddressMap[inputCoin], CoinToAddressMap[outputCoin], inputAmount, big.NewInt(0), big.NewInt(10))
-
+	data, err := packAction(
+		"Balancer", hbalancer_exchange.HbalancerExchangeABI, "smartSwapExactIn",
+		inputAddr, outputAddr, inputAmount, minOut, big.NewInt(balancerPools))
 	if err != nil {
-		return nil
+		return failedActions("Balancer", "smartSwapExactIn", "", err)
 	}
 
-	if inputCoin == ETH {
+	if inputCoin.IsETH() {
 		return &Actions{
 			Actions: []action{
 				{
-					handlerAddr:  common.HexToAddress(hBalancerExchangeAddr),
+					handlerAddr:  c.client.network.Handlers.BalancerExchange,
 					data:         data,
 					ethersNeeded: inputAmount,
 				},
@@ -1670,10 +1767,10 @@ func (c *BalancerClient) Swap(inputCoin coinType, outputCoin coinType, inputAmou
 		return &Actions{
 			Actions: []action{
 				{
-					handlerAddr:          common.HexToAddress(hBalancerExchangeAddr),
+					handlerAddr:          c.client.network.Handlers.BalancerExchange,
 					data:                 data,
 					ethersNeeded:         big.NewInt(0),
-					approvalTokens:       []common.Address{CoinToAddressMap[inputCoin]},
+					approvalTokens:       []common.Address{inputAddr},
 					approvalTokenAmounts: []*big.Int{inputAmount},
 				},
 			},
@@ -1685,33 +1782,24 @@ func (c *BalancerClient) Swap(inputCoin coinType, outputCoin coinType, inputAmou
 // utility------------------------------------------------------------------------
 
 // Approve approves ERC-20 token transfer.
-func Approve(client *DefiClient, coin coinType, addr common.Address, size *big.Int) error {
-	erc20Contract, err := erc20.NewErc20(CoinToAddressMap[coin], client.conn)
+func Approve(ctx context.Context, client *DefiClient, coin Token, addr common.Address, amount Size) error {
+	size, err := client.sizeOf(amount, client.erc20Address(coin))
 	if err != nil {
 		return err
 	}
-	opts := &bind.TransactOpts{
-		Signer:   client.opts.Signer,
-		From:     client.opts.From,
-		GasLimit: 500000,
-		GasPrice: big.NewInt(20000000000),
+	erc20Contract, err := erc20.NewErc20(client.erc20Address(coin), client.conn)
+	if err != nil {
+		return err
 	}
-	tx, err := erc20Contract.Approve(opts, addr, size)
-	bind.WaitMined(context.Background(), client.conn, tx)
-	return nil
-}
-
-// Convert string to a fixed length 32 byte.
-func byte32PutString(s string) [32]byte {
-	var res [32]byte
-	decoded, err := hex.DecodeString(s)
+	opts, err := client.transactOpts(ctx, nil)
 	if err != nil {
-		return res
+		return err
 	}
-	if len(s) > 32 {
-		copy(res[:], decoded)
-	} else {
-		copy(res[32-len(s):], decoded)
+	tx, err := erc20Contract.Approve(opts, addr, size)
+	if err != nil {
+		client.releaseNonce(opts)
+		return err
 	}
-	return res
+	_, err = client.waitMined(ctx, tx)
+	return err
 }
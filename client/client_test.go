@@ -1,16 +1,26 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
 	"log"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/rafaelescrich/go-defi-1/binding/compound/cToken"
 	"github.com/rafaelescrich/go-defi-1/binding/erc20"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -20,6 +30,7 @@ var ethClient *ethclient.Client
 var publicKey *ecdsa.PublicKey
 var fromAddr common.Address
 var defiClient *DefiClient
+var signer *bind.TransactOpts
 
 func init() {
 	var err error
@@ -37,7 +48,14 @@ func init() {
 		log.Fatal("cannot assert type")
 	}
 	fromAddr = crypto.PubkeyToAddress(*publicKeyECDSA)
-	defiClient = NewClient(bind.NewKeyedTransactor(key), ethClient)
+	signer, err = bind.NewKeyedTransactorWithChainID(key, new(big.Int).SetUint64(GanacheChainID))
+	if err != nil {
+		log.Fatalf("Failed to create signer: %v", err)
+	}
+	defiClient, err = NewClient(signer, ethClient, WithNetwork(MainnetFork()))
+	if err != nil {
+		log.Fatalf("Failed to create client: %v", err)
+	}
 	//_ = fromAddr
 	//_ = ethClient
 	//_ = defiClient
@@ -47,14 +65,14 @@ func TestInteractWithCompound(t *testing.T) {
 
 	beforeETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
 
-	err = defiClient.Compound().Supply(int64(1e18), ETH)
+	err = defiClient.Compound().Supply(context.Background(), big.NewInt(1e18), ETH)
 	if err != nil {
 		t.Errorf("Failed to supply in compound: %v", err)
 	}
 
-	cETH, err := defiClient.Compound().BalanceOf(ETH)
+	cETH, err := defiClient.Compound().BalanceOf(context.Background(), ETH, nil)
 	if err != nil {
-		t.Errorf("Failed to get balance: %v", err)
+		t.Fatalf("Failed to get balance: %v", err)
 	}
 
 	if cETH.Cmp(big.NewInt(0)) == 0 {
@@ -70,15 +88,15 @@ func TestInteractWithCompound(t *testing.T) {
 
 func TestInteractWithUniswap(t *testing.T) {
 	beforeETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
-	beforeDAI, err := defiClient.BalanceOf(DAI)
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 
-	err = defiClient.Uniswap().Swap(1e18, DAI, ETH, fromAddr)
+	err = defiClient.Uniswap().Swap(context.Background(), big.NewInt(1e18), DAI, ETH, fromAddr)
 	if err != nil {
 		t.Errorf("Failed to swap in uniswap: %v", err)
 	}
 
 	afterETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
-	afterDAI, err := defiClient.BalanceOf(DAI)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 
 	if beforeETH.Cmp(afterETH) != 1 {
 		t.Errorf("ETH balance not decreasing.")
@@ -87,12 +105,12 @@ func TestInteractWithUniswap(t *testing.T) {
 		t.Errorf("Dai hasn't increased!")
 	}
 
-	err = defiClient.Compound().Supply(int64(1e18), DAI)
+	err = defiClient.Compound().Supply(context.Background(), big.NewInt(1e18), DAI)
 	if err != nil {
 		t.Errorf("Failed to supply Dai in compound: %v", err)
 	}
 
-	cDai, err := defiClient.Compound().BalanceOf(DAI)
+	cDai, err := defiClient.Compound().BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Failed to get balance: %v", err)
 	}
@@ -103,16 +121,34 @@ func TestInteractWithUniswap(t *testing.T) {
 
 }
 
+func TestSwapDeadline(t *testing.T) {
+	err := defiClient.Uniswap().Swap(context.Background(), big.NewInt(1e18), DAI, ETH, fromAddr, WithDeadline(time.Now().Add(-time.Hour)))
+	if !errors.Is(err, ErrDeadlineExpired) {
+		t.Errorf("Expected ErrDeadlineExpired, got %v", err)
+	}
+	deadline, err := defiClient.deadlineOf(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to get deadline: %v", err)
+	}
+	header, err := ethClient.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to get latest block: %v", err)
+	}
+	if want := header.Time + uint64(DefaultSwapDeadline/time.Second); deadline.Uint64() != want {
+		t.Errorf("Deadline = %v, want %d", deadline, want)
+	}
+}
+
 func TestInteractWithCompoundInDai(t *testing.T) {
 
-	beforeDAI, err := defiClient.BalanceOf(DAI)
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 
-	err = defiClient.Compound().Supply(int64(1e18), DAI)
+	err = defiClient.Compound().Supply(context.Background(), big.NewInt(1e18), DAI)
 	if err != nil {
 		t.Errorf("Failed to supply in compound: %v", err)
 	}
 
-	cDAI, err := defiClient.Compound().BalanceOf(DAI)
+	cDAI, err := defiClient.Compound().BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Failed to get balance: %v", err)
 	}
@@ -121,7 +157,7 @@ func TestInteractWithCompoundInDai(t *testing.T) {
 		t.Errorf("CDai minting is not successful")
 	}
 
-	afterDAI, err := defiClient.BalanceOf(DAI)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 
 	if beforeDAI.Cmp(afterDAI) != 1 {
 		t.Errorf("Dai balance not decreasing.")
@@ -129,11 +165,11 @@ func TestInteractWithCompoundInDai(t *testing.T) {
 }
 
 func TestMintSomeUSDC(t *testing.T) {
-	_, err := erc20.NewErc20(CoinToAddressMap[USDC], ethClient)
+	_, err := erc20.NewErc20(USDC.Address, ethClient)
 	if err != nil {
 		t.Errorf("Error getting USDC Contract")
 	}
-	beforeUSDC, err := defiClient.BalanceOf(USDC)
+	beforeUSDC, err := defiClient.BalanceOf(context.Background(), USDC, nil)
 	if err != nil {
 		t.Errorf("Error getting USDC balance")
 	}
@@ -146,7 +182,7 @@ func TestMintSomeUSDC(t *testing.T) {
 func TestInteractWithYearn(t *testing.T) {
 	beforeETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
 
-	err = defiClient.Yearn().addLiquidity(big.NewInt(1e18), ETH)
+	err = defiClient.Yearn().addLiquidity(context.Background(), big.NewInt(1e18), ETH)
 	if err != nil {
 		t.Errorf("Failed to add liquidity in yearn: %v", err)
 	}
@@ -162,10 +198,10 @@ func TestInteractWithFurucomboWithYearn(t *testing.T) {
 
 	actions := new(Actions)
 	actions.Add(
-		defiClient.Yearn().AddLiquidityActions(big.NewInt(1e18), ETH),
+		defiClient.Yearn().AddLiquidityActions(context.Background(), big.NewInt(1e18), ETH),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 	if err != nil {
 		t.Errorf("Failed to add liquidity in yearn: %v", err)
 	}
@@ -177,10 +213,10 @@ func TestInteractWithFurucomboWithYearn(t *testing.T) {
 
 	actions = new(Actions)
 	actions.Add(
-		defiClient.Yearn().RemoveLiquidityActions(big.NewInt(1e18), ETH),
+		defiClient.Yearn().RemoveLiquidityActions(context.Background(), big.NewInt(1e18), ETH),
 	)
-	Approve(defiClient, yWETH, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
-	err = defiClient.ExecuteActions(actions)
+	Approve(context.Background(), defiClient, yWETH, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 	if err != nil {
 		t.Errorf("Failed to remove liquidity in yearn: %v", err)
 	}
@@ -195,7 +231,7 @@ func TestInteractWithFurucomboWithYearn(t *testing.T) {
 
 func TestInteractWithFurucomboWithCompoundNew(t *testing.T) {
 
-	beforeCETH, err := defiClient.Compound().BalanceOf(ETH)
+	beforeCETH, err := defiClient.Compound().BalanceOf(context.Background(), ETH, nil)
 
 	if err != nil {
 		log.Fatalf("Failed to get balance: %v", err)
@@ -207,13 +243,13 @@ func TestInteractWithFurucomboWithCompoundNew(t *testing.T) {
 		defiClient.Compound().SupplyActions(big.NewInt(1e18), ETH),
 	)
 
-	defiClient.ExecuteActions(actions)
+	defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterCETH, err := defiClient.Compound().BalanceOf(ETH)
+	afterCETH, err := defiClient.Compound().BalanceOf(context.Background(), ETH, nil)
 	if err != nil {
 		t.Errorf("Failed to get balance: %v", err)
 	}
@@ -225,8 +261,8 @@ func TestInteractWithFurucomboWithCompoundNew(t *testing.T) {
 }
 
 func TestInteractWithFurucomboWithCompoundERC20New(t *testing.T) {
-	Approve(defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
-	beforeCDai, err := defiClient.Compound().BalanceOf(DAI)
+	Approve(context.Background(), defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
+	beforeCDai, err := defiClient.Compound().BalanceOf(context.Background(), DAI, nil)
 
 	if err != nil {
 		log.Fatalf("Failed to get balance: %v", err)
@@ -238,13 +274,13 @@ func TestInteractWithFurucomboWithCompoundERC20New(t *testing.T) {
 		defiClient.Compound().SupplyActions(big.NewInt(1e18), DAI),
 	)
 
-	defiClient.ExecuteActions(actions)
+	defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterCDai, err := defiClient.Compound().BalanceOf(DAI)
+	afterCDai, err := defiClient.Compound().BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Failed to get balance: %v", err)
 	}
@@ -256,10 +292,10 @@ func TestInteractWithFurucomboWithCompoundERC20New(t *testing.T) {
 }
 
 func TestInteractWithFurucomboWithCompoundERC20withRedeem(t *testing.T) {
-	Approve(defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
-	Approve(defiClient, cDAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
+	Approve(context.Background(), defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
+	Approve(context.Background(), defiClient, cDAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
 
-	beforeCDai, err := defiClient.Compound().BalanceOf(DAI)
+	beforeCDai, err := defiClient.Compound().BalanceOf(context.Background(), DAI, nil)
 
 	if err != nil {
 		log.Fatalf("Failed to get balance: %v", err)
@@ -272,13 +308,13 @@ func TestInteractWithFurucomboWithCompoundERC20withRedeem(t *testing.T) {
 		defiClient.Compound().RedeemActions(big.NewInt(100000), DAI),
 	)
 
-	defiClient.ExecuteActions(actions)
+	defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterCDai, err := defiClient.Compound().BalanceOf(DAI)
+	afterCDai, err := defiClient.Compound().BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Failed to get balance: %v", err)
 	}
@@ -290,7 +326,7 @@ func TestInteractWithFurucomboWithCompoundERC20withRedeem(t *testing.T) {
 }
 
 func TestInteractWithFurucomboFlashLoan(t *testing.T) {
-	Approve(defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
+	Approve(context.Background(), defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
 
 	actions := new(Actions)
 	flashLoanActions := new(Actions)
@@ -304,7 +340,7 @@ func TestInteractWithFurucomboFlashLoan(t *testing.T) {
 		),
 	)
 
-	err := defiClient.ExecuteActions(actions)
+	_, err := defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
@@ -313,7 +349,7 @@ func TestInteractWithFurucomboFlashLoan(t *testing.T) {
 }
 
 // func TestInteractWithFurucomboFlashSwap(t *testing.T) {
-// 	Approve(defiClient, DAI, common.HexToAddress(FurucomboAddr), big.NewInt(1e18))
+// 	Approve(context.Background(), defiClient, DAI, common.HexToAddress(FurucomboAddr), big.NewInt(1e18))
 
 // 	actions := new(Actions)
 // 	flashSwapActions := new(Actions)
@@ -328,7 +364,7 @@ func TestInteractWithFurucomboFlashLoan(t *testing.T) {
 // 		),
 // 	)
 
-// 	err := defiClient.ExecuteActions(actions)
+// 	_, err := defiClient.ExecuteActions(context.Background(), actions)
 
 // 	if err != nil {
 // 		t.Errorf("Failed to interact with Furucombo..: %v", err)
@@ -341,7 +377,7 @@ func TestInteractWithFurucomboUniswap(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error getting ETH balance")
 	}
-	beforeDAI, err := defiClient.BalanceOf(DAI)
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
 	}
@@ -349,13 +385,13 @@ func TestInteractWithFurucomboUniswap(t *testing.T) {
 	actions := new(Actions)
 
 	actions.Add(
-		defiClient.Uniswap().SwapActions(big.NewInt(1e18), DAI, ETH),
+		defiClient.Uniswap().SwapActions(context.Background(), big.NewInt(1e18), DAI, ETH),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	afterETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
-	afterDAI, err := defiClient.BalanceOf(DAI)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 
 	if beforeETH.Cmp(afterETH) != 1 {
 		t.Errorf("ETH balance not decreasing.")
@@ -366,12 +402,77 @@ func TestInteractWithFurucomboUniswap(t *testing.T) {
 
 }
 
+func TestInteractWithFurucomboUniswapExactOutput(t *testing.T) {
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
+	if err != nil {
+		t.Errorf("Error getting DAI balance")
+	}
+
+	hundredDAI, _ := NewAmount("100", DAI)
+	actions := new(Actions)
+	err = actions.Add(
+		defiClient.Uniswap().SwapExactOutputActions(context.Background(), hundredDAI, DAI, ETH, WithSlippage(100)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build exact output swap: %v", err)
+	}
+
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
+	if err != nil {
+		t.Errorf("Failed to execute exact output swap: %v", err)
+	}
+
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
+	if got := new(big.Int).Sub(afterDAI, beforeDAI); got.Cmp(hundredDAI.Int()) != 0 {
+		t.Errorf("Expected exactly 100 DAI, got %v", got)
+	}
+}
+
+func TestInteractWithFurucomboUniswapLiquidity(t *testing.T) {
+	ctx := context.Background()
+	swap := new(Actions)
+	swap.Add(defiClient.Uniswap().SwapActions(context.Background(), big.NewInt(1e18), DAI, ETH))
+	if _, err := defiClient.ExecuteActions(ctx, swap); err != nil {
+		t.Fatalf("Failed to swap for DAI: %v", err)
+	}
+
+	hundredDAI, _ := NewAmount("100", DAI)
+	actions := new(Actions)
+	err := actions.Add(
+		defiClient.Uniswap().AddLiquidityActions(ctx, big.NewInt(1e18), ETH, hundredDAI, DAI, WithSlippage(100)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build add liquidity: %v", err)
+	}
+	if _, err := defiClient.ExecuteActions(ctx, actions); err != nil {
+		t.Errorf("Failed to add liquidity: %v", err)
+	}
+
+	position, err := defiClient.Uniswap().LiquidityPosition(ctx, fromAddr, ETH, DAI)
+	if err != nil {
+		t.Fatalf("Failed to get liquidity position: %v", err)
+	}
+	if position.Liquidity.Sign() <= 0 || position.AmountB.Sign() <= 0 || position.Value.Int().Cmp(position.AmountB.Int()) <= 0 {
+		t.Errorf("Unexpected liquidity position: %+v", position)
+	}
+
+	actions = new(Actions)
+	actions.Add(defiClient.Uniswap().RemoveLiquidityActions(ctx, position.Liquidity, ETH, DAI, WithSlippage(100)))
+	if _, err := defiClient.ExecuteActions(ctx, actions); err != nil {
+		t.Errorf("Failed to remove liquidity: %v", err)
+	}
+	position, err = defiClient.Uniswap().LiquidityPosition(ctx, fromAddr, ETH, DAI)
+	if err != nil || position.Liquidity.Sign() != 0 {
+		t.Errorf("Liquidity not removed: %+v %v", position, err)
+	}
+}
+
 func TestInteractWithFurucomboKyber(t *testing.T) {
 	beforeETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
 	if err != nil {
 		t.Errorf("Error getting ETH balance")
 	}
-	beforeDAI, err := defiClient.BalanceOf(DAI)
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
 	}
@@ -379,13 +480,13 @@ func TestInteractWithFurucomboKyber(t *testing.T) {
 	actions := new(Actions)
 
 	actions.Add(
-		defiClient.Kyberswap().SwapActions(big.NewInt(1e18), DAI, ETH),
+		defiClient.Kyberswap().SwapActions(context.Background(), big.NewInt(1e18), DAI, ETH),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	afterETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
-	afterDAI, err := defiClient.BalanceOf(DAI)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 
 	if beforeETH.Cmp(afterETH) != 1 {
 		t.Errorf("ETH balance not decreasing.")
@@ -397,8 +498,8 @@ func TestInteractWithFurucomboKyber(t *testing.T) {
 }
 
 func TestInteractWithFurucomboFlashLoanCompound(t *testing.T) {
-	Approve(defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(3e18))
-	beforecDAI, err := defiClient.BalanceOf(cDAI)
+	Approve(context.Background(), defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(3e18))
+	beforecDAI, err := defiClient.BalanceOf(context.Background(), cDAI, nil)
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
 	}
@@ -421,21 +522,21 @@ func TestInteractWithFurucomboFlashLoanCompound(t *testing.T) {
 		),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	aftercDAI, err := defiClient.BalanceOf(cDAI)
+	aftercDAI, err := defiClient.BalanceOf(context.Background(), cDAI, nil)
 	if beforecDAI.Cmp(aftercDAI) != -1 {
 		t.Errorf("cdai balance not increasing.")
 	}
 }
 
 // func TestInteractWithFurucomboFlashSwapCompound(t *testing.T) {
-// 	Approve(defiClient, DAI, common.HexToAddress(FurucomboAddr), big.NewInt(2e18))
-// 	beforecDAI, err := defiClient.BalanceOf(cDAI)
+// 	Approve(context.Background(), defiClient, DAI, common.HexToAddress(FurucomboAddr), big.NewInt(2e18))
+// 	beforecDAI, err := defiClient.BalanceOf(context.Background(), cDAI, nil)
 // 	if err != nil {
 // 		t.Errorf("Error getting DAI balance")
 // 	}
@@ -458,21 +559,21 @@ func TestInteractWithFurucomboFlashLoanCompound(t *testing.T) {
 // 		),
 // 	)
 
-// 	err = defiClient.ExecuteActions(actions)
+// 	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 // 	if err != nil {
 // 		t.Errorf("Failed to interact with Furucombo: %v", err)
 // 	}
 
-// 	aftercDAI, err := defiClient.BalanceOf(cDAI)
+// 	aftercDAI, err := defiClient.BalanceOf(context.Background(), cDAI, nil)
 // 	if beforecDAI.Cmp(aftercDAI) != -1 {
 // 		t.Errorf("cdai balance not increasing.")
 // 	}
 // }
 
 func TestInteractWithFurucomboCurve(t *testing.T) {
-	Approve(defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(2e18))
-	beforeUSDC, err := defiClient.BalanceOf(USDC)
+	Approve(context.Background(), defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(2e18))
+	beforeUSDC, err := defiClient.BalanceOf(context.Background(), USDC, nil)
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
 	}
@@ -481,22 +582,22 @@ func TestInteractWithFurucomboCurve(t *testing.T) {
 
 	actions.Add(
 		defiClient.Curve().ExchangeActions(
-			common.HexToAddress(c3Pool),
-			CoinToAddressMap[DAI],
-			CoinToAddressMap[USDC],
+			defiClient.Network().Contracts.CurvePools["3pool"].Pool,
+			DAI.Address,
+			USDC.Address,
 			big.NewInt(0),
 			big.NewInt(1),
 			big.NewInt(1e18),
 			big.NewInt(1e5)),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterUSDC, err := defiClient.BalanceOf(USDC)
+	afterUSDC, err := defiClient.BalanceOf(context.Background(), USDC, nil)
 	if beforeUSDC.Cmp(afterUSDC) != -1 {
 		t.Errorf("USDC balance not increasing. %v %v", beforeUSDC, afterUSDC)
 	}
@@ -504,8 +605,8 @@ func TestInteractWithFurucomboCurve(t *testing.T) {
 
 // Supplying DAI to the Curve 3 pool
 func TestInteractWithFurucomboCurveAddLiquidity(t *testing.T) {
-	Approve(defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(2e18))
-	beforeDAI, err := defiClient.BalanceOf(DAI)
+	Approve(context.Background(), defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(2e18))
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
 	}
@@ -514,27 +615,27 @@ func TestInteractWithFurucomboCurveAddLiquidity(t *testing.T) {
 
 	actions.Add(
 		defiClient.Curve().AddLiquidityActions(
-			common.HexToAddress(c3Pool),
-			common.HexToAddress(threePoolCrv),
-			[]common.Address{CoinToAddressMap[DAI], CoinToAddressMap[USDC], CoinToAddressMap[USDT]},
-			[]*big.Int{big.NewInt(1e18), big.NewInt(0), big.NewInt(0)},
+			defiClient.Network().Contracts.CurvePools["3pool"].Pool,
+			defiClient.Network().Contracts.CurvePools["3pool"].LPToken,
+			[]common.Address{DAI.Address, USDC.Address, USDT.Address},
+			[]Size{big.NewInt(1e18), big.NewInt(0), big.NewInt(0)},
 			big.NewInt(0)),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterDAI, err := defiClient.BalanceOf(DAI)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 	if beforeDAI.Cmp(afterDAI) != 1 {
 		t.Errorf("USDC balance not decreasing. %v %v", beforeDAI, afterDAI)
 	}
 }
 
 func TestInteractWithFurucomboMaker(t *testing.T) {
-	beforeDAI, err := defiClient.BalanceOf(DAI)
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
 	}
@@ -550,26 +651,26 @@ func TestInteractWithFurucomboMaker(t *testing.T) {
 		defiClient.Maker().GenerateDaiAction(collateralAmount, outputAmount, ETH),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterDAI, err := defiClient.BalanceOf(DAI)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 	if beforeDAI.Cmp(afterDAI) != -1 {
 		t.Errorf("dai balance not increasing: %v, %v.", beforeDAI, afterDAI)
 	}
 }
 
 func TestInteractWithFurucomboMakerUSDC(t *testing.T) {
-	beforeDAI, err := defiClient.BalanceOf(DAI)
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
 	}
 
-	Approve(defiClient, USDC, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
+	Approve(context.Background(), defiClient, USDC, common.HexToAddress(ProxyAddr), big.NewInt(1e18))
 	actions := new(Actions)
 
 	collateralAmount := big.NewInt(0)
@@ -577,25 +678,25 @@ func TestInteractWithFurucomboMakerUSDC(t *testing.T) {
 	outputAmount := big.NewInt(0)
 	outputAmount.SetString("520000000000000000000", 10)
 	actions.Add(
-		defiClient.Uniswap().SwapActions(big.NewInt(5e18), USDC, ETH),
+		defiClient.Uniswap().SwapActions(context.Background(), big.NewInt(5e18), USDC, ETH),
 		defiClient.Maker().GenerateDaiAction(collateralAmount, outputAmount, USDC),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterDAI, err := defiClient.BalanceOf(DAI)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), DAI, nil)
 	if beforeDAI.Cmp(afterDAI) != -1 {
 		t.Errorf("dai balance not increasing: %v, %v.", beforeDAI, afterDAI)
 	}
 }
 
 func TestInteractWithFurucomboBalancer(t *testing.T) {
-	beforeDAI, err := defiClient.BalanceOf(ETH)
-	Approve(defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(6e18))
+	beforeDAI, err := defiClient.BalanceOf(context.Background(), ETH, nil)
+	Approve(context.Background(), defiClient, DAI, common.HexToAddress(ProxyAddr), big.NewInt(6e18))
 
 	if err != nil {
 		t.Errorf("Error getting DAI balance")
@@ -604,17 +705,1227 @@ func TestInteractWithFurucomboBalancer(t *testing.T) {
 	actions := new(Actions)
 
 	actions.Add(
-		defiClient.Balancer().Swap(DAI, ETH, big.NewInt(6e18)),
+		defiClient.Balancer().Swap(context.Background(), DAI, ETH, big.NewInt(6e18)),
 	)
 
-	err = defiClient.ExecuteActions(actions)
+	_, err = defiClient.ExecuteActions(context.Background(), actions)
 
 	if err != nil {
 		t.Errorf("Failed to interact with Furucombo: %v", err)
 	}
 
-	afterDAI, err := defiClient.BalanceOf(ETH)
+	afterDAI, err := defiClient.BalanceOf(context.Background(), ETH, nil)
 	if beforeDAI.Cmp(afterDAI) != -1 {
 		t.Errorf("dai balance not increasing: %v, %v.", beforeDAI, afterDAI)
 	}
 }
+
+func TestActionsCarryBuildError(t *testing.T) {
+	actions := new(Actions)
+	err := actions.Add(
+		defiClient.Compound().SupplyActions(big.NewInt(1e18), ETH),
+		defiClient.Uniswap().SwapActions(context.Background(), nil, DAI, ETH),
+	)
+
+	var actionErr *ActionError
+	if !errors.As(err, &actionErr) {
+		t.Fatalf("Expected an ActionError, got: %v", err)
+	}
+	if actionErr.Protocol != "Uniswap" || actionErr.Method != "swapExactETHForTokens" || actionErr.Arg != "value" {
+		t.Errorf("Unexpected action error: %v", actionErr)
+	}
+	if !errors.Is(err, ErrNilAmount) {
+		t.Errorf("Expected ErrNilAmount, got: %v", err)
+	}
+
+	_, _, _, err = defiClient.CombineActions(context.Background(), actions)
+	if err != actions.Err() {
+		t.Errorf("CombineActions should surface the build error, got: %v", err)
+	}
+}
+
+func TestActionsUnsupportedCoin(t *testing.T) {
+	actions := defiClient.Kyberswap().SwapActions(context.Background(), big.NewInt(1e18), Token{}, ETH)
+
+	var actionErr *ActionError
+	if !errors.As(actions.Err(), &actionErr) {
+		t.Fatalf("Expected an ActionError, got: %v", actions.Err())
+	}
+	if actionErr.Arg != "baseCurrency" || !errors.Is(actionErr, ErrUnsupportedCoin) {
+		t.Errorf("Unexpected action error: %v", actionErr)
+	}
+
+	flashLoan := defiClient.Aave().FlashLoanActions(big.NewInt(1e18), DAI, actions)
+	if !errors.Is(flashLoan.Err(), ErrUnsupportedCoin) {
+		t.Errorf("Nested build error is not surfaced: %v", flashLoan.Err())
+	}
+}
+
+type revertError struct {
+	msg  string
+	data interface{}
+}
+
+func (e revertError) Error() string          { return e.msg }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+func TestDecodeRevert(t *testing.T) {
+	// Error(string) with the reason "HUniswap: failed"
+	data := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000010" +
+		"48556e69737761703a206661696c656400000000000000000000000000000000"
+
+	reason, _, ok := decodeRevert(revertError{msg: "execution reverted", data: data})
+	if !ok || reason != "HUniswap: failed" {
+		t.Errorf("Failed to decode geth revert data: %v %v", reason, ok)
+	}
+
+	reason, _, ok = decodeRevert(revertError{
+		msg:  "VM Exception while processing transaction: revert HUniswap: failed",
+		data: map[string]interface{}{"0xabc": map[string]interface{}{"error": "revert", "return": data}},
+	})
+	if !ok || reason != "HUniswap: failed" {
+		t.Errorf("Failed to decode ganache revert data: %v %v", reason, ok)
+	}
+
+	reason, _, ok = decodeRevert(errors.New("VM Exception while processing transaction: revert HMaker: failed"))
+	if !ok || reason != "HMaker: failed" {
+		t.Errorf("Failed to decode revert message: %v %v", reason, ok)
+	}
+
+	if _, _, ok = decodeRevert(errors.New("connection refused")); ok {
+		t.Errorf("Connection error should not be a revert")
+	}
+}
+
+func TestSimulateFurucomboUniswap(t *testing.T) {
+	beforeETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
+	if err != nil {
+		t.Errorf("Error getting ETH balance")
+	}
+
+	actions := new(Actions)
+	actions.Add(
+		defiClient.Uniswap().SwapActions(context.Background(), big.NewInt(1e18), DAI, ETH),
+	)
+
+	result, err := defiClient.SimulateActions(context.Background(), actions, nil)
+	if err != nil {
+		t.Fatalf("Failed to simulate: %v", err)
+	}
+	if !result.Success || result.GasUsed == 0 {
+		t.Errorf("Simulation is not successful: %v", result.RevertReason)
+	}
+	if result.BalanceDeltas != nil {
+		t.Errorf("Balance deltas need a tracing client: %v", result.BalanceDeltas)
+	}
+
+	afterETH, err := ethClient.BalanceAt(context.Background(), fromAddr, nil)
+	if beforeETH.Cmp(afterETH) != 0 {
+		t.Errorf("Simulation should not spend ETH.")
+	}
+}
+
+func TestBalanceDeltas(t *testing.T) {
+	proxy := common.HexToAddress(ProxyAddr)
+	transfer := func(token Token, from common.Address, to common.Address, amount int64) callLog {
+		return callLog{
+			Address: token.Address,
+			Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:    common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+		}
+	}
+	frame := &callFrame{
+		Type:  "CALL",
+		From:  fromAddr,
+		To:    proxy,
+		Value: (*hexutil.Big)(big.NewInt(1e18)),
+		Calls: []callFrame{
+			{Type: "DELEGATECALL", From: proxy, To: Mainnet().Handlers.Uniswap, Value: (*hexutil.Big)(big.NewInt(1e18))},
+			{Type: "CALL", From: proxy, To: DAI.Address, Logs: []callLog{transfer(DAI, proxy, fromAddr, 2000)}},
+			{Type: "CALL", From: proxy, To: USDC.Address, Error: "execution reverted", Logs: []callLog{transfer(USDC, proxy, fromAddr, 5)}},
+			{Type: "CALL", From: proxy, To: fromAddr, Value: (*hexutil.Big)(big.NewInt(1e17))},
+		},
+	}
+
+	deltas := balanceDeltas(frame, fromAddr)
+	if len(deltas) != 2 {
+		t.Errorf("Unexpected deltas: %v", deltas)
+	}
+	if deltas[common.Address{}].Cmp(big.NewInt(-9e17)) != 0 {
+		t.Errorf("Unexpected ETH delta: %v", deltas[common.Address{}])
+	}
+	if deltas[DAI.Address].Cmp(big.NewInt(2000)) != 0 {
+		t.Errorf("Unexpected DAI delta: %v", deltas[DAI.Address])
+	}
+}
+
+func TestExecutionErrorHandler(t *testing.T) {
+	handlers := Mainnet().Handlers
+	handler, ok := handlers.handlerFromReason("HUniswap: UniswapV2Router: INSUFFICIENT_OUTPUT_AMOUNT")
+	if !ok || handler != handlers.Uniswap {
+		t.Errorf("Failed to find the handler: %v", handler.Hex())
+	}
+	if _, ok := handlers.handlerFromReason("Invalid handler"); ok {
+		t.Errorf("Reason without a handler prefix should not match")
+	}
+
+	panicData := common.FromHex("0x4e487b710000000000000000000000000000000000000000000000000000000000000011")
+	if msg := decodeRevertData(panicData); msg != "panic: code 0x11" {
+		t.Errorf("Failed to decode panic: %v", msg)
+	}
+	if msg := decodeRevertData(common.FromHex("0xdeadbeef")); msg != "custom error 0xdeadbeef" {
+		t.Errorf("Failed to decode custom error: %v", msg)
+	}
+}
+
+func TestGasLimit(t *testing.T) {
+	c, err := NewClient(signer, ethClient, WithNetwork(MainnetFork()), WithGasLimit(1.5, 1000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit, err := c.gasLimit(100000)
+	if err != nil || limit != 150000 {
+		t.Errorf("Unexpected gas limit: %v %v", limit, err)
+	}
+	limit, err = c.gasLimit(900000)
+	if err != nil || limit != 1000000 {
+		t.Errorf("Gas limit should be capped: %v %v", limit, err)
+	}
+	if _, err = c.gasLimit(2000000); !errors.Is(err, ErrGasCapExceeded) {
+		t.Errorf("Expected ErrGasCapExceeded, got: %v", err)
+	}
+
+	estimate := &GasEstimate{
+		Actions: []ActionGas{
+			{Gas: 50000},
+			{Gas: 400000, Nested: []ActionGas{{Gas: 30000}, {Gas: 300000}}},
+			{Gas: 100000},
+		},
+	}
+	if dominant := estimate.Dominant(); len(dominant) != 2 || dominant[0] != 1 || dominant[1] != 1 {
+		t.Errorf("Unexpected dominant action: %v", dominant)
+	}
+}
+
+func TestAttributeGas(t *testing.T) {
+	handlers := Mainnet().Handlers
+	proxy := common.HexToAddress(ProxyAddr)
+	lendingPool := common.HexToAddress("0x7d2768dE32b0b80b7a3454c06BdAc94A69DDc7A9")
+	actions := []action{
+		{handlerAddr: handlers.Uniswap},
+		{handlerAddr: handlers.Aave, nested: &Actions{Actions: []action{{handlerAddr: handlers.Uniswap}, {handlerAddr: handlers.Curve}}}},
+		{handlerAddr: handlers.CToken},
+	}
+	frame := &callFrame{
+		Type: "CALL",
+		To:   proxy,
+		Calls: []callFrame{
+			{Type: "DELEGATECALL", To: handlers.Funds, GasUsed: 40000},
+			{Type: "STATICCALL", To: common.HexToAddress("0x1")},
+			{Type: "DELEGATECALL", To: handlers.Uniswap, GasUsed: 100000},
+			{Type: "DELEGATECALL", To: handlers.Aave, GasUsed: 500000, Calls: []callFrame{
+				{Type: "CALL", To: lendingPool, Calls: []callFrame{
+					{Type: "CALL", To: proxy, Calls: []callFrame{
+						{Type: "DELEGATECALL", To: handlers.Uniswap, GasUsed: 90000},
+						{Type: "DELEGATECALL", To: handlers.Curve, GasUsed: 300000},
+					}},
+				}},
+			}},
+		},
+	}
+
+	breakdown := attributeGas(handlerCalls(frame)[1:], actions)
+	if len(breakdown) != 3 || breakdown[0].Gas != 100000 || breakdown[1].Gas != 500000 {
+		t.Fatalf("Unexpected breakdown: %+v", breakdown)
+	}
+	if nested := breakdown[1].Nested; len(nested) != 2 || nested[0].Gas != 90000 || nested[1].Gas != 300000 {
+		t.Errorf("Unexpected nested breakdown: %+v", nested)
+	}
+	if !errors.Is(breakdown[2].Err, ErrActionNotTraced) {
+		t.Errorf("Expected ErrActionNotTraced, got: %v", breakdown[2].Err)
+	}
+	estimate := &GasEstimate{Actions: breakdown}
+	if dominant := estimate.Dominant(); len(dominant) != 2 || dominant[0] != 1 || dominant[1] != 1 {
+		t.Errorf("Unexpected dominant action: %v", dominant)
+	}
+}
+
+func TestFees(t *testing.T) {
+	median := medianFee([]*big.Int{big.NewInt(3), big.NewInt(1), big.NewInt(2)})
+	if median.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("Unexpected median: %v", median)
+	}
+	median = medianFee([]*big.Int{big.NewInt(4), big.NewInt(1), big.NewInt(2), big.NewInt(10)})
+	if median.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("Unexpected median: %v", median)
+	}
+
+	txs := []*types.Transaction{
+		types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(130), GasTipCap: big.NewInt(50)}),
+		types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(105)}),
+		types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(90), GasTipCap: big.NewInt(1)}),
+	}
+	block := types.NewBlockWithHeader(&types.Header{BaseFee: big.NewInt(100)}).WithBody(txs, nil)
+	prices := EffectiveGasPrices(block)
+	if len(prices) != 2 || prices[0].Int64() != 105 || prices[1].Int64() != 130 {
+		t.Errorf("Unexpected effective gas prices: %v", prices)
+	}
+
+	fees := dynamicFees(big.NewInt(100), big.NewInt(2), big.NewInt(90))
+	if fees.GasTipCap.Int64() != 2 || fees.GasFeeCap.Int64() != 202 {
+		t.Errorf("Tip should not be zeroed by a price under the base fee: %+v", fees)
+	}
+	fees = dynamicFees(big.NewInt(100), big.NewInt(5), big.NewInt(103))
+	if fees.GasTipCap.Int64() != 3 || fees.GasFeeCap.Int64() != 203 {
+		t.Errorf("Tip should be capped by the price: %+v", fees)
+	}
+
+	opts := new(bind.TransactOpts)
+	(&Fees{GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(2)}).apply(opts)
+	if opts.GasPrice != nil || opts.GasFeeCap.Int64() != 100 || opts.GasTipCap.Int64() != 2 {
+		t.Errorf("Dynamic fees not applied: %+v", opts)
+	}
+	opts = new(bind.TransactOpts)
+	(&Fees{GasPrice: big.NewInt(20)}).apply(opts)
+	if opts.GasPrice.Int64() != 20 || opts.GasFeeCap != nil {
+		t.Errorf("Legacy fees not applied: %+v", opts)
+	}
+}
+
+func TestGasOracle(t *testing.T) {
+	c, err := NewClient(signer, ethClient, WithNetwork(MainnetFork()), WithGasOracle(&FixedOracle{Price: big.NewInt(7e9)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	price, err := c.SuggestGasPrice(context.Background(), big.NewInt(1))
+	if err != nil || price.Int64() != 7e9 {
+		t.Errorf("Unexpected fixed gas price: %v %v", price, err)
+	}
+
+	prices := []*big.Int{big.NewInt(5), big.NewInt(1), big.NewInt(1000), big.NewInt(3), big.NewInt(2)}
+	sortFees(prices)
+	if p := percentileFee(prices, 50); p.Int64() != 3 {
+		t.Errorf("Unexpected 50th percentile: %v", p)
+	}
+	if p := percentileFee(prices, 0); p.Int64() != 1 {
+		t.Errorf("Unexpected 0th percentile: %v", p)
+	}
+	if p := percentileFee(prices, 100); p.Int64() != 1000 {
+		t.Errorf("Unexpected 100th percentile: %v", p)
+	}
+
+	cache := newBlockCache(2)
+	cache.put(10, prices)
+	cache.put(11, prices)
+	cache.put(12, prices)
+	if _, ok := cache.get(10); ok {
+		t.Errorf("The lowest block should be evicted")
+	}
+	if _, ok := cache.get(12); !ok {
+		t.Errorf("The latest block should be cached")
+	}
+
+	if _, err := NewPercentileOracle(ethClient, 0, 50).SuggestGasPrice(context.Background(), big.NewInt(1)); !errors.Is(err, ErrBadOracleConfig) {
+		t.Errorf("Expected ErrBadOracleConfig, got: %v", err)
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	actions := new(Actions)
+	actions.Add(defiClient.Compound().SupplyActions(big.NewInt(1e18), ETH))
+	if _, err := defiClient.ExecuteActions(ctx, actions); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+	if _, err := defiClient.BalanceOf(ctx, DAI, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestDecodeLogs(t *testing.T) {
+	token := DAI.Address
+	proxy := common.HexToAddress(ProxyAddr)
+	transferLog := func(from common.Address, to common.Address, value int64) *types.Log {
+		return &types.Log{
+			Address: token,
+			Topics: []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				common.BytesToHash(from.Bytes()),
+				common.BytesToHash(to.Bytes()),
+			},
+			Data: common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+		}
+	}
+	mintLog := &types.Log{
+		Address: cDAI.Address,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("Mint(address,uint256,uint256)"))},
+		Data: append(append(common.LeftPadBytes(proxy.Bytes(), 32),
+			common.LeftPadBytes(big.NewInt(100).Bytes(), 32)...),
+			common.LeftPadBytes(big.NewInt(4000).Bytes(), 32)...),
+	}
+
+	transfers, events := decodeLogs([]*types.Log{
+		transferLog(fromAddr, proxy, 100), mintLog, transferLog(proxy, fromAddr, 30),
+	})
+	if len(transfers) != 2 || transfers[0].Value.Int64() != 100 || transfers[1].To != fromAddr {
+		t.Errorf("Unexpected transfers: %+v", transfers)
+	}
+	if len(events) != 1 || events[0].Protocol != "Compound" || events[0].Name != "Mint" {
+		t.Fatalf("Unexpected events: %+v", events)
+	}
+	if minter, ok := events[0].Args["minter"].(common.Address); !ok || minter != proxy {
+		t.Errorf("Unexpected Mint args: %v", events[0].Args)
+	}
+	if delta := tokenDeltas(transfers, fromAddr)[token]; delta.Int64() != -70 {
+		t.Errorf("Unexpected token delta: %v", delta)
+	}
+}
+
+func TestBumpFees(t *testing.T) {
+	bumped := bumpFees(&Fees{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(0)}, MinFeeBump)
+	if bumped.GasFeeCap.Int64() != 220 || bumped.GasTipCap.Int64() != 1 || bumped.GasPrice != nil {
+		t.Errorf("Unexpected bumped fees: %+v", bumped)
+	}
+	bumped = bumpFees(&Fees{GasPrice: big.NewInt(1e9)}, 25)
+	if bumped.GasPrice.Int64() != 1.25e9 || bumped.IsDynamic() {
+		t.Errorf("Unexpected bumped gas price: %+v", bumped)
+	}
+
+	nonces := NewNonceManager(ethClient, fromAddr)
+	nonces.synced, nonces.next = true, 5
+	nonces.Release(4)
+	if next, err := nonces.Next(context.Background()); err != nil || next != 4 {
+		t.Errorf("The released nonce should be reused: %v %v", next, err)
+	}
+	nonces.Release(2)
+	if nonces.synced {
+		t.Errorf("Releasing an older nonce should resync the manager")
+	}
+}
+
+// fakeNode answers the JSON-RPC calls of a node whose account nonce is `nonce` and which knows
+// no transaction.
+func fakeNode(t *testing.T, nonce uint64, calls map[string]*big.Int) *ethclient.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "eth_getTransactionCount":
+			result = hexutil.Uint64(nonce)
+		case "eth_call":
+			// The calls are answered by the name of the method, the others revert.
+			var call struct {
+				Data hexutil.Bytes `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &call)
+			for method, out := range calls {
+				if bytes.HasPrefix(call.Data, crypto.Keccak256([]byte(method))[:4]) {
+					result = hexutil.Bytes(common.LeftPadBytes(out.Bytes(), 32))
+				}
+			}
+			if result == nil {
+				json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID,
+					"error": map[string]interface{}{"code": -32000, "message": "execution reverted"}})
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	conn, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestSubmissionDropped(t *testing.T) {
+	ctx := context.Background()
+	conn := fakeNode(t, 5, nil)
+	c, err := NewClient(signer, conn, WithNetwork(MainnetFork()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := c.nonces.Next(ctx)
+	if err != nil || nonce != 5 {
+		t.Fatalf("Unexpected nonce: %v %v", nonce, err)
+	}
+	// The combo was dropped: the node never mines it and the nonce of the account stays at 5.
+	s := &Submission{
+		client:   c,
+		nonce:    nonce,
+		txs:      []*types.Transaction{types.NewTx(&types.LegacyTx{Nonce: nonce})},
+		deadline: time.Now().Add(-time.Second),
+		updates:  make(chan StatusUpdate, 16),
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	if !s.poll(ctx) {
+		t.Fatal("The tracking should end at the deadline")
+	}
+	if _, err := s.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	if next, err := c.nonces.Next(ctx); err != nil || next != nonce {
+		t.Errorf("The nonce of the dropped combo should be handed out again: %v %v", next, err)
+	}
+}
+
+func TestTokenRegistry(t *testing.T) {
+	registry := DefaultTokenRegistry()
+	usdc, err := registry.Resolve("usdc")
+	if err != nil || usdc != USDC || usdc.Decimals != 6 {
+		t.Errorf("Unexpected USDC: %+v %v", usdc, err)
+	}
+	if dai, err := registry.Resolve(DAI.Address.Hex()); err != nil || dai != DAI {
+		t.Errorf("Unexpected DAI: %+v %v", dai, err)
+	}
+	if _, err := registry.Resolve("FOO"); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("Expected ErrUnknownToken, got %v", err)
+	}
+
+	list := `{"name": "test", "tokens": [
+		{"address": "0x0000000000000000000000000000000000000001", "symbol": "FOO", "decimals": 9, "chainId": 1},
+		{"address": "0x0000000000000000000000000000000000000002", "symbol": "BAR", "decimals": 18, "chainId": 137}
+	]}`
+	if err := registry.LoadJSON(strings.NewReader(list)); err != nil {
+		t.Fatal(err)
+	}
+	if foo, err := registry.Resolve("FOO"); err != nil || foo.Decimals != 9 {
+		t.Errorf("Unexpected FOO: %+v %v", foo, err)
+	}
+	if _, ok := registry.BySymbol("BAR"); ok {
+		t.Errorf("Tokens of other chains shouldn't be added")
+	}
+	moved := Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000003"), Symbol: "foo", Decimals: 9}
+	registry.Add(moved)
+	if foo, ok := registry.BySymbol("FOO"); !ok || foo.Address != moved.Address {
+		t.Errorf("FOO should have its new address: %+v", foo)
+	}
+	if old, ok := registry.ByAddress(common.HexToAddress("0x0000000000000000000000000000000000000001")); ok {
+		t.Errorf("The old address of FOO should be unknown: %+v", old)
+	}
+	if addr, err := defiClient.coinAddr(ETH); err != nil || addr != WETH.Address {
+		t.Errorf("ETH should be mapped to WETH: %v %v", addr, err)
+	}
+}
+
+func TestNetworkConfig(t *testing.T) {
+	redeployment := `
+name: redeployment
+chainId: 5777
+extends: 1
+proxy: 0x1111111111111111111111111111111111111111
+handlers:
+  sushiswap: 0x2222222222222222222222222222222222222222
+tokens:
+  - {address: "0x3333333333333333333333333333333333333333", symbol: FOO, decimals: 9}
+  - {address: "0x5555555555555555555555555555555555555555", symbol: WETH, decimals: 18}
+`
+	network, err := LoadNetwork(strings.NewReader(redeployment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered, err := LookupNetwork(5777); err != nil || registered != network {
+		t.Errorf("The network should be registered: %v", err)
+	}
+	mainnet := Mainnet()
+	if network.Handlers.Sushiswap != common.HexToAddress("0x2222222222222222222222222222222222222222") ||
+		network.Handlers.Uniswap != mainnet.Handlers.Uniswap ||
+		network.Contracts.UniswapRouter != mainnet.Contracts.UniswapRouter {
+		t.Errorf("Unexpected handlers: %+v", network.Handlers)
+	}
+	if market, ok := network.CompoundMarket(DAI); !ok || market != cDAI.Address {
+		t.Errorf("Unexpected DAI market: %v %v", market, ok)
+	}
+	if _, ilk, ok := network.MakerCollateral(ETH); !ok || string(bytes.TrimRight(ilk[:], "\x00")) != "ETH-A" {
+		t.Errorf("Unexpected ETH ilk: %q %v", ilk, ok)
+	}
+	tokens := NewTokenRegistry(network.ChainID, network.Tokens...)
+	if foo, err := tokens.Resolve("FOO"); err != nil || foo.ChainID != 5777 {
+		t.Errorf("Unexpected FOO: %+v %v", foo, err)
+	}
+	if dai, err := tokens.Resolve("DAI"); err != nil || dai.Address != DAI.Address {
+		t.Errorf("Inherited DAI not found: %+v %v", dai, err)
+	}
+	redeployed := common.HexToAddress("0x5555555555555555555555555555555555555555")
+	c, err := NewClient(signer, ethClient, WithNetwork(network))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr, err := c.coinAddr(ETH); err != nil || addr != redeployed {
+		t.Errorf("ETH should be mapped to the WETH of the network: %v %v", addr, err)
+	}
+	if path := c.pathAddrs(c.swapPath(USDC, DAI)); len(path) != 3 || path[1] != redeployed {
+		t.Errorf("Expected a path through the WETH of the network, got %v", path)
+	}
+
+	_, err = LoadNetwork(strings.NewReader(`{"chainId": 5, "proxy": "0x1111111111111111111111111111111111111111", "contracts": {"compoundMarkets": {"BAR": "0x4444444444444444444444444444444444444444"}}}`))
+	if !errors.Is(err, ErrBadNetworkConfig) {
+		t.Errorf("Expected ErrBadNetworkConfig, got %v", err)
+	}
+	if _, err := LookupNetwork(5); !errors.Is(err, ErrUnknownNetwork) {
+		t.Errorf("An invalid network shouldn't be registered: %v", err)
+	}
+	extending := &NetworkConfig{ChainID: 7777, Extends: MainnetChainID, Proxy: redeployed}
+	c, err = NewClient(signer, ethClient, WithNetwork(extending))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Network().Handlers.Uniswap != mainnet.Handlers.Uniswap || c.Network().Proxy != redeployed {
+		t.Errorf("The config given to the client should extend mainnet: %+v", c.Network().Handlers)
+	}
+	if addr, err := c.coinAddr(ETH); err != nil || addr != WETH.Address {
+		t.Errorf("ETH should be mapped to the inherited WETH: %v %v", addr, err)
+	}
+	_, err = LoadNetwork(strings.NewReader(`{"chainId": 6, "proxy": "0x1111111111111111111111111111111111111111"}`))
+	if !errors.Is(err, ErrBadNetworkConfig) {
+		t.Errorf("Expected ErrBadNetworkConfig without WETH, got %v", err)
+	}
+}
+
+func TestAmount(t *testing.T) {
+	usdc, err := NewAmount("1.5", USDC)
+	if err != nil || usdc.Int().Int64() != 1500000 || usdc.String() != "1.5 USDC" {
+		t.Errorf("Unexpected amount: %v %v %v", usdc.Int(), usdc, err)
+	}
+	if _, err := NewAmount("0.0000001", USDC); !errors.Is(err, ErrBadAmount) {
+		t.Errorf("Expected ErrBadAmount for too many decimals, got %v", err)
+	}
+	if _, err := NewAmount("1,5", USDC); !errors.Is(err, ErrBadAmount) {
+		t.Errorf("Expected ErrBadAmount, got %v", err)
+	}
+	for raw, want := range map[int64]string{0: "0", 1: "0.000001", 1000000: "1", 1234500: "1.2345", -20: "-0.00002"} {
+		if got := FormatUnits(big.NewInt(raw), 6); got != want {
+			t.Errorf("FormatUnits(%d) = %s, want %s", raw, got, want)
+		}
+	}
+
+	if err := defiClient.Uniswap().SwapActions(context.Background(), usdc, DAI, USDC).Err(); err != nil {
+		t.Errorf("An amount of the input token should be accepted: %v", err)
+	}
+	err = defiClient.Uniswap().SwapActions(context.Background(), usdc, USDC, DAI).Err()
+	var actionErr *ActionError
+	if !errors.As(err, &actionErr) || actionErr.Arg != "size" || !errors.Is(err, ErrTokenMismatch) {
+		t.Errorf("Expected ErrTokenMismatch on size, got %v", err)
+	}
+	eth, _ := NewAmount("1", ETH)
+	if err := defiClient.Compound().SupplyActions(eth, ETH).Err(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	pool := defiClient.Network().Contracts.CurvePools["3pool"]
+	err = defiClient.Curve().AddLiquidityActions(pool.Pool, pool.LPToken,
+		[]common.Address{DAI.Address, USDC.Address}, []Size{usdc, big.NewInt(0)}, big.NewInt(0)).Err()
+	if !errors.As(err, &actionErr) || actionErr.Arg != "amounts" || !errors.Is(err, ErrTokenMismatch) {
+		t.Errorf("Expected ErrTokenMismatch on the Curve amounts, got %v", err)
+	}
+}
+
+func TestSlippage(t *testing.T) {
+	for bps, want := range map[uint64]int64{0: 1000000, 50: 995000, 10000: 0} {
+		if got, err := applySlippage(big.NewInt(1000000), bps); err != nil || got.Int64() != want {
+			t.Errorf("applySlippage(%d) = %v %v, want %d", bps, got, err, want)
+		}
+	}
+	if _, err := applySlippage(big.NewInt(1), MaxSlippageBps+1); !errors.Is(err, ErrBadSlippage) {
+		t.Errorf("Expected ErrBadSlippage, got %v", err)
+	}
+
+	err := defiClient.Uniswap().SwapActions(context.Background(), big.NewInt(1e6), DAI, USDC, WithSlippage(MaxSlippageBps+1)).Err()
+	if !errors.Is(err, ErrBadSlippage) {
+		t.Errorf("Expected ErrBadSlippage before quoting, got %v", err)
+	}
+	minDai, _ := NewAmount("0.99", DAI)
+	if err := defiClient.Sushiswap().SwapActions(context.Background(), big.NewInt(1e6), DAI, USDC, WithMinOutput(minDai)).Err(); err != nil {
+		t.Errorf("An explicit minimum needs no quote: %v", err)
+	}
+	err = defiClient.Balancer().Swap(context.Background(), USDC, DAI, big.NewInt(1e6), WithMinOutput(RawAmount(big.NewInt(1), USDC))).Err()
+	var actionErr *ActionError
+	if !errors.As(err, &actionErr) || actionErr.Arg != "minTotalAmountOut" || !errors.Is(err, ErrTokenMismatch) {
+		t.Errorf("Expected ErrTokenMismatch on the minimum output, got %v", err)
+	}
+}
+
+func TestRouterError(t *testing.T) {
+	err := routerError(errors.New("execution reverted: UniswapV2Router: EXPIRED"))
+	if !errors.Is(err, ErrDeadlineExpired) {
+		t.Errorf("Expected ErrDeadlineExpired, got %v", err)
+	}
+	err = routerError(errors.New("execution reverted: UniswapV2Router: INSUFFICIENT_OUTPUT_AMOUNT"))
+	if errors.Is(err, ErrDeadlineExpired) {
+		t.Errorf("Unexpected ErrDeadlineExpired: %v", err)
+	}
+	if routerError(nil) != nil {
+		t.Errorf("A nil error should stay nil")
+	}
+}
+
+func TestFindRoute(t *testing.T) {
+	e18 := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	e6 := func(n int64) *big.Int { return big.NewInt(n * 1e6) }
+	// A shallow direct DAI/USDC pair and deep pairs through WETH.
+	pools := map[[2]common.Address][2]*big.Int{
+		{DAI.Address, USDC.Address}:  {e18(1000), e6(1000)},
+		{DAI.Address, WETH.Address}:  {e18(1000000), e18(1000)},
+		{WETH.Address, USDC.Address}: {e18(1000), e6(1000000)},
+	}
+	reserves := func(tokenIn common.Address, tokenOut common.Address) (*big.Int, *big.Int, error) {
+		if r, ok := pools[[2]common.Address{tokenIn, tokenOut}]; ok {
+			return r[0], r[1], nil
+		}
+		if r, ok := pools[[2]common.Address{tokenOut, tokenIn}]; ok {
+			return r[1], r[0], nil
+		}
+		return nil, nil, nil
+	}
+
+	route, err := defiClient.findRoute(e18(100), DAI, USDC, 3, []Token{WETH, USDT}, reserves)
+	if err != nil {
+		t.Fatalf("Failed to find route: %v", err)
+	}
+	if len(route.Path) != 3 || route.Path[1] != WETH {
+		t.Errorf("Expected the deep route through WETH, got %v", route.Path)
+	}
+	if want := getAmountOut(getAmountOut(e18(100), e18(1000000), e18(1000)), e18(1000), e6(1000000)); route.AmountOut.Cmp(want) != 0 {
+		t.Errorf("AmountOut = %v, want %v", route.AmountOut, want)
+	}
+
+	route, err = defiClient.findRoute(e18(100), DAI, USDC, 1, []Token{WETH}, reserves)
+	if err != nil || len(route.Path) != 2 {
+		t.Errorf("Expected the direct pair with a single hop, got %v %v", route, err)
+	}
+	if _, err := defiClient.findRoute(e18(100), DAI, YFI, 3, []Token{WETH}, reserves); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("Expected ErrNoRoute, got %v", err)
+	}
+
+	actions := defiClient.Sushiswap().SwapPathActions(context.Background(), e18(100), route.Path)
+	if err := actions.Err(); err != nil || actions.Actions[0].approvalTokens[0] != DAI.Address {
+		t.Errorf("Unexpected path swap: %v", err)
+	}
+	err = defiClient.Uniswap().SwapPathActions(context.Background(), e18(1), []Token{DAI, ETH, USDC}).Err()
+	if !errors.Is(err, ErrBadPath) {
+		t.Errorf("Expected ErrBadPath, got %v", err)
+	}
+}
+
+func TestExactOutputSwap(t *testing.T) {
+	if got, _ := addSlippage(big.NewInt(1000001), 50); got.Int64() != 1005002 {
+		t.Errorf("addSlippage should round up, got %v", got)
+	}
+
+	hundredDAI, _ := NewAmount("100", DAI)
+	maxETH := big.NewInt(1e17)
+	actions := defiClient.Uniswap().SwapExactOutputActions(context.Background(), hundredDAI, DAI, ETH, WithMaxInput(maxETH))
+	if err := actions.Err(); err != nil || actions.Actions[0].ethersNeeded.Cmp(maxETH) != 0 {
+		t.Errorf("Expected the maximum input to be sent along: %v", err)
+	}
+	maxUSDC, _ := NewAmount("101", USDC)
+	actions = defiClient.Sushiswap().SwapExactOutputActions(context.Background(), hundredDAI, DAI, USDC, WithMaxInput(maxUSDC))
+	if err := actions.Err(); err != nil || actions.Actions[0].approvalTokenAmounts[0].Cmp(maxUSDC.Int()) != 0 {
+		t.Errorf("Expected the maximum input to be approved: %v", err)
+	}
+	err := defiClient.Uniswap().SwapExactOutputActions(context.Background(), nil, DAI, USDC, WithMaxInput(maxUSDC)).Err()
+	if !errors.Is(err, ErrNilAmount) {
+		t.Errorf("Expected ErrNilAmount, got %v", err)
+	}
+}
+
+func TestAaveLendNilAmount(t *testing.T) {
+	c, err := NewClient(signer, fakeNode(t, 0, nil), WithNetwork(MainnetFork()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Aave().Lend(context.Background(), nil, DAI); !errors.Is(err, ErrNilAmount) {
+		t.Errorf("Expected ErrNilAmount, got %v", err)
+	}
+}
+
+func TestOptimalLiquidity(t *testing.T) {
+	// A pair at 1 ETH for 2000 DAI.
+	reserveETH, reserveDAI := big.NewInt(10), big.NewInt(20000)
+	a, b := optimalLiquidity(big.NewInt(1), big.NewInt(3000), reserveETH, reserveDAI)
+	if a.Int64() != 1 || b.Int64() != 2000 {
+		t.Errorf("Expected 1 and 2000, got %v and %v", a, b)
+	}
+	a, b = optimalLiquidity(big.NewInt(2), big.NewInt(3000), reserveETH, reserveDAI)
+	if a.Int64() != 1 || b.Int64() != 3000 {
+		t.Errorf("Expected 1 and 3000, got %v and %v", a, b)
+	}
+	a, b = optimalLiquidity(big.NewInt(2), big.NewInt(3000), nil, nil)
+	if a.Int64() != 2 || b.Int64() != 3000 {
+		t.Errorf("A new pair should take the desired amounts, got %v and %v", a, b)
+	}
+
+	err := defiClient.Sushiswap().AddLiquidityActions(context.Background(), big.NewInt(1), ETH, big.NewInt(1), WETH).Err()
+	if !errors.Is(err, ErrBadPath) {
+		t.Errorf("Expected ErrBadPath for ETH and WETH, got %v", err)
+	}
+}
+
+func TestQuoter(t *testing.T) {
+	ctx := context.Background()
+	hundredDAI, _ := NewAmount("100", DAI)
+	quoter := defiClient.Quoter()
+
+	if _, err := quoter.Quote(ctx, VenueCurve, hundredDAI, DAI, USDC); !errors.Is(err, ErrUnknownVenue) {
+		t.Errorf("Expected ErrUnknownVenue, got %v", err)
+	}
+	if _, err := quoter.Uniswap(ctx, nil, DAI, USDC); !errors.Is(err, ErrNilAmount) {
+		t.Errorf("Expected ErrNilAmount, got %v", err)
+	}
+
+	var best *Quote
+	for _, venue := range PairVenues {
+		quote, err := quoter.Quote(ctx, venue, hundredDAI, DAI, USDC)
+		if err != nil {
+			t.Errorf("Failed to quote %s: %v", venue, err)
+			continue
+		}
+		if quote.Output.Token != USDC || quote.Output.Sign() <= 0 {
+			t.Errorf("Unexpected quote: %v", quote)
+		}
+		if quote.Better(best) {
+			best = quote
+		}
+	}
+
+	pool := defiClient.Network().Contracts.CurvePools["3pool"].Pool
+	quote, err := quoter.Curve(ctx, pool, big.NewInt(0), big.NewInt(1), hundredDAI, DAI, USDC)
+	if err != nil || quote.Output.Sign() <= 0 {
+		t.Errorf("Failed to quote Curve: %v %v", quote, err)
+	}
+
+	deposit, err := quoter.YearnDeposit(ctx, hundredDAI, DAI)
+	if err != nil {
+		t.Fatalf("Failed to quote Yearn deposit: %v", err)
+	}
+	withdraw, err := quoter.YearnWithdraw(ctx, deposit.Output, DAI)
+	if err != nil || withdraw.Output.Int().Cmp(hundredDAI.Int()) > 0 {
+		t.Errorf("Shares should convert back to at most the deposit: %v %v", withdraw, err)
+	}
+}
+
+func TestSplitParts(t *testing.T) {
+	venue := func(outputs ...int64) *smartVenue {
+		v := &smartVenue{}
+		for _, output := range outputs {
+			v.outputs = append(v.outputs, big.NewInt(output))
+		}
+		return v
+	}
+	// Linear venues: the best one takes everything.
+	parts := splitParts([]*smartVenue{venue(10, 20, 30, 40), venue(11, 22, 33, 44)})
+	if parts[0] != 0 || parts[1] != SmartSwapParts {
+		t.Errorf("Expected all the parts on the second venue, got %v", parts)
+	}
+	// Shallow venues: splitting gets more.
+	parts = splitParts([]*smartVenue{venue(10, 15, 17, 18), venue(10, 15, 17, 18), venue(1, 2, 3, 4)})
+	if parts[0] != 2 || parts[1] != 2 || parts[2] != 0 {
+		t.Errorf("Expected an even split on the first two venues, got %v", parts)
+	}
+	// Ties go to a single venue.
+	parts = splitParts([]*smartVenue{venue(10, 20, 30, 40), venue(10, 20, 30, 40)})
+	if parts[0]+parts[1] != SmartSwapParts || parts[0] != 0 && parts[1] != 0 {
+		t.Errorf("Expected a single venue, got %v", parts)
+	}
+
+	// The rest of the rounded down parts goes to the first leg, which is quoted for it.
+	linear := func(venue Venue) *smartVenue {
+		v := &smartVenue{venue: venue, quote: func(ctx context.Context, size *big.Int) (*Quote, error) {
+			return newQuote(venue, size, DAI, new(big.Int).Mul(size, big.NewInt(2)), USDC), nil
+		}}
+		if err := v.quoteParts(context.Background(), big.NewInt(11)); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	venues := []*smartVenue{linear(VenueUniswap), linear(VenueSushiswap)}
+	legs, err := smartLegs(context.Background(), venues, []int{2, 2}, big.NewInt(11), DAI, USDC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legs[0].Input.Int().Int64() != 6 || legs[0].Expected.Int().Int64() != 12 {
+		t.Errorf("Expected the first leg to swap 6 for 12, got %v for %v", legs[0].Input, legs[0].Expected)
+	}
+	if legs[1].Input.Int().Int64() != 5 || legs[1].Expected.Int().Int64() != 10 {
+		t.Errorf("Expected the second leg to swap 5 for 10, got %v for %v", legs[1].Input, legs[1].Expected)
+	}
+}
+
+func TestSmartSwap(t *testing.T) {
+	ctx := context.Background()
+	hundredDAI, _ := NewAmount("100", DAI)
+	actions, decision := defiClient.SmartSwapActions(ctx, hundredDAI, DAI, USDC, 50)
+	if err := actions.Err(); err != nil {
+		t.Fatalf("Failed to build smart swap: %v (%v)", err, decision.Failures)
+	}
+	if len(decision.Legs) == 0 || decision.MinOutput.Int().Cmp(decision.Expected.Int()) > 0 {
+		t.Errorf("Unexpected decision: %v", decision)
+	}
+	if best := decision.Quotes[0]; decision.Expected.Int().Cmp(best.Output.Int()) < 0 {
+		t.Errorf("The decision %v gets less than the best venue %v", decision, best)
+	}
+	if tokens := actions.Actions[0].approvalTokens; len(tokens) != 1 || tokens[0] != DAI.Address {
+		t.Errorf("Expected a single DAI injection, got %v", tokens)
+	}
+
+	Approve(ctx, defiClient, DAI, common.HexToAddress(ProxyAddr), hundredDAI)
+	if _, err := defiClient.ExecuteActions(ctx, actions); err != nil {
+		t.Errorf("Failed to execute smart swap %v: %v", decision, err)
+	}
+}
+
+func TestCompoundBalanceOfError(t *testing.T) {
+	// Nothing listens on port 1, every call fails.
+	down, err := ethclient.Dial("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(signer, down, WithNetwork(MainnetFork()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, coin := range []Token{ETH, DAI} {
+		if balance, err := c.Compound().BalanceOf(context.Background(), coin, nil); err == nil || balance != nil {
+			t.Errorf("Expected the error of the call for %s, got %v %v", coin, balance, err)
+		}
+	}
+}
+
+func TestCompoundBorrowActions(t *testing.T) {
+	err := defiClient.Compound().BorrowActions(big.NewInt(1e18), DAI).Err()
+	if !errors.Is(err, ErrNoBorrowHandler) || !errors.Is(err, ErrNoHandler) {
+		t.Errorf("Expected ErrNoBorrowHandler, got %v", err)
+	}
+	network := MainnetFork()
+	network.Handlers.CompoundBorrow = common.HexToAddress("0x4444444444444444444444444444444444444444")
+	c, err := NewClient(signer, ethClient, WithNetwork(network))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := c.Compound().BorrowActions(big.NewInt(1e18), DAI)
+	if err := actions.Err(); err != nil || actions.Actions[0].handlerAddr != network.Handlers.CompoundBorrow {
+		t.Errorf("Expected a borrow with the borrow handler: %v", err)
+	}
+	borrower := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	actions = defiClient.Compound().RepayBehalfActions(big.NewInt(1e18), DAI, borrower)
+	if err := actions.Err(); err != nil || actions.Actions[0].approvalTokens[0] != DAI.Address {
+		t.Errorf("Expected DAI to be injected: %v", err)
+	}
+	actions = defiClient.Compound().RepayActions(big.NewInt(1e17), ETH)
+	if err := actions.Err(); err != nil || actions.Actions[0].ethersNeeded.Int64() != 1e17 {
+		t.Errorf("Expected the ethers to be sent along: %v", err)
+	}
+	if err := defiClient.Compound().RepayActions(nil, DAI).Err(); !errors.Is(err, ErrNilAmount) {
+		t.Errorf("Expected ErrNilAmount, got %v", err)
+	}
+	if err := compoundError("borrow", big.NewInt(3), cTokenErrors); err.Reason != "COMPTROLLER_REJECTION" {
+		t.Errorf("Unexpected reason: %v", err)
+	}
+}
+
+func TestInteractWithCompoundBorrow(t *testing.T) {
+	ctx := context.Background()
+	compound := defiClient.Compound()
+	if err := compound.Supply(ctx, big.NewInt(1e18), ETH); err != nil {
+		t.Fatalf("Failed to supply: %v", err)
+	}
+	if err := compound.EnterMarkets(ctx, ETH); err != nil {
+		t.Fatalf("Failed to enter market: %v", err)
+	}
+	if in, err := compound.InMarket(ctx, ETH); err != nil || !in {
+		t.Fatalf("Expected to be in the ETH market: %v", err)
+	}
+	tooMuch, _ := NewAmount("1000000", DAI)
+	var compoundErr *CompoundError
+	if err := compound.Borrow(ctx, tooMuch, DAI); !errors.As(err, &compoundErr) {
+		t.Errorf("Expected a CompoundError, got %v", err)
+	}
+	tenDAI, _ := NewAmount("10", DAI)
+	if err := compound.Borrow(ctx, tenDAI, DAI); err != nil {
+		t.Fatalf("Failed to borrow: %v", err)
+	}
+	if err := compound.ExitMarkets(ctx, ETH); !errors.As(err, &compoundErr) {
+		t.Errorf("Expected a CompoundError exiting the collateral of a borrow, got %v", err)
+	}
+
+	cDAIContract, err := cToken.NewCToken(cDAI.Address, ethClient)
+	if err != nil {
+		t.Fatalf("Failed to get cDAI: %v", err)
+	}
+	before, err := cDAIContract.BorrowBalanceStored(nil, fromAddr)
+	if err != nil {
+		t.Fatalf("Failed to get borrow balance: %v", err)
+	}
+	fiveDAI, _ := NewAmount("5", DAI)
+	if err := compound.Repay(ctx, fiveDAI, DAI); err != nil {
+		t.Errorf("Failed to repay: %v", err)
+	}
+	Approve(ctx, defiClient, DAI, common.HexToAddress(ProxyAddr), fiveDAI)
+	if _, err := defiClient.ExecuteActions(ctx, compound.RepayActions(fiveDAI, DAI)); err != nil {
+		t.Errorf("Failed to repay via Furucombo: %v", err)
+	}
+	after, err := cDAIContract.BorrowBalanceStored(nil, fromAddr)
+	if err != nil {
+		t.Fatalf("Failed to get borrow balance: %v", err)
+	}
+	if new(big.Int).Sub(before, after).Cmp(fiveDAI.Int()) <= 0 {
+		t.Errorf("Expected both repayments, borrow balance %v then %v", before, after)
+	}
+}
+
+func TestCompoundSnapshot(t *testing.T) {
+	e18 := pow10(18)
+	snapshot := newCompoundSnapshot(fromAddr, big.NewInt(1))
+	// 50 cETH at 0.02 ETH each, ETH at $2000, 75% collateral factor.
+	cTokens := new(big.Int).Mul(big.NewInt(50), pow10(8))
+	exchangeRate := new(big.Int).Mul(big.NewInt(2), pow10(26))
+	price := new(big.Int).Mul(big.NewInt(2000), e18)
+	snapshot.add(newCompoundPosition(cETH.Address, ETH, cTokens, big.NewInt(0), exchangeRate, price, big.NewInt(75e16), true))
+	// 1000 DAI borrowed at $1.
+	borrowed := new(big.Int).Mul(big.NewInt(1000), e18)
+	snapshot.add(newCompoundPosition(cDAI.Address, DAI, big.NewInt(0), borrowed, big.NewInt(0), e18, big.NewInt(75e16), false))
+
+	if supplied := snapshot.Markets[0].Supplied; supplied.Decimal() != "1" {
+		t.Errorf("Expected 1 ETH supplied, got %v", supplied)
+	}
+	if value := FormatUnits(snapshot.SuppliedValue, 18); value != "2000" {
+		t.Errorf("Expected $2000 supplied, got %v", value)
+	}
+	if limit := FormatUnits(snapshot.BorrowLimit, 18); limit != "1500" {
+		t.Errorf("Expected a $1500 borrow limit, got %v", limit)
+	}
+	if used := snapshot.LimitUsed(); used < 0.666 || used > 0.667 {
+		t.Errorf("Expected 2/3 of the limit used, got %v", used)
+	}
+}
+
+func TestInteractWithCompoundSnapshot(t *testing.T) {
+	ctx := context.Background()
+	compound := defiClient.Compound()
+	if err := compound.Supply(ctx, big.NewInt(1e18), ETH); err != nil {
+		t.Fatalf("Failed to supply: %v", err)
+	}
+	if err := compound.EnterMarkets(ctx, ETH); err != nil {
+		t.Fatalf("Failed to enter market: %v", err)
+	}
+	tenDAI, _ := NewAmount("10", DAI)
+	if err := compound.Borrow(ctx, tenDAI, DAI); err != nil {
+		t.Fatalf("Failed to borrow: %v", err)
+	}
+
+	snapshot, err := compound.AccountSnapshot(ctx, fromAddr)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	if snapshot.BorrowedValue.Sign() <= 0 || snapshot.Shortfall.Sign() != 0 {
+		t.Errorf("Expected a healthy borrow: %v", snapshot)
+	}
+	// The Comptroller computes the liquidity from the same positions and prices.
+	left := new(big.Int).Sub(snapshot.BorrowLimit, snapshot.BorrowedValue)
+	if diff := new(big.Int).Sub(left, snapshot.Liquidity); diff.CmpAbs(pow10(12)) > 0 {
+		t.Errorf("Liquidity %v doesn't match the positions: %v", snapshot.Liquidity, snapshot)
+	}
+	for _, position := range snapshot.Markets {
+		if position.Market == cETH.Address && (!position.Collateral || position.Supplied.Token != ETH) {
+			t.Errorf("Expected the ETH supply as collateral: %+v", position)
+		}
+	}
+}
+
+func TestCompoundMarketActions(t *testing.T) {
+	actions := defiClient.Compound().SupplyActions(big.NewInt(1e6), USDC)
+	if err := actions.Err(); err != nil || !bytes.Contains(actions.Actions[0].data, cUSDC.Address.Bytes()) {
+		t.Errorf("Expected USDC to be supplied to cUSDC: %v", err)
+	}
+	actions = defiClient.Compound().RedeemActions(big.NewInt(1e8), ETH)
+	if err := actions.Err(); err != nil || actions.Actions[0].ethersNeeded.Sign() != 0 || actions.Actions[0].approvalTokens[0] != cETH.Address {
+		t.Errorf("Expected cETH to be injected and no ethers sent: %v", err)
+	}
+	actions = defiClient.Compound().RedeemUnderlyingActions(big.NewInt(1e18), DAI)
+	if err := actions.Err(); err != nil || actions.Actions[0].approvalTokens[0] != cDAI.Address {
+		t.Errorf("Expected cDAI to be injected: %v", err)
+	}
+
+	aave := common.HexToAddress("0x7Fc66500c84A76Ad7e9c93437bFc5Ac33E2DDaE9")
+	if err := defiClient.Compound().SupplyActions(big.NewInt(1), Token{Address: aave}).Err(); err == nil {
+		t.Errorf("Expected an undiscovered market to be unsupported")
+	}
+	var markets compoundMarketsCache
+	markets.add(aave, common.HexToAddress("0x1"))
+	markets.add(aave, common.HexToAddress("0x2"))
+	if market, ok := markets.get(aave); !ok || market != common.HexToAddress("0x1") {
+		t.Errorf("Expected the first market to be kept, got %v", market)
+	}
+}
+
+func TestInteractWithCompoundMarkets(t *testing.T) {
+	ctx := context.Background()
+	markets, err := defiClient.Compound().Markets(ctx)
+	if err != nil {
+		t.Fatalf("Failed to discover markets: %v", err)
+	}
+	found := false
+	for _, market := range markets {
+		if market.CToken == cUSDC.Address {
+			found = market.Underlying == USDC
+		}
+	}
+	if !found {
+		t.Errorf("Expected cUSDC with USDC among %v", markets)
+	}
+
+	hundredUSDC, _ := NewAmount("100", USDC)
+	fiftyUSDC, _ := NewAmount("50", USDC)
+	actions := new(Actions)
+	actions.Add(
+		defiClient.Uniswap().SwapActions(context.Background(), big.NewInt(1e18), USDC, ETH),
+		defiClient.Compound().SupplyActions(hundredUSDC, USDC),
+		defiClient.Compound().RedeemUnderlyingActions(fiftyUSDC, USDC),
+	)
+	if _, err := defiClient.ExecuteActions(ctx, actions); err != nil {
+		t.Fatalf("Failed to supply and redeem USDC: %v", err)
+	}
+	cUSDCBalance, err := defiClient.Compound().BalanceOf(ctx, USDC, nil)
+	if err != nil || cUSDCBalance.Sign() <= 0 {
+		t.Errorf("Expected cUSDC to be left: %v %v", cUSDCBalance, err)
+	}
+}
+
+func TestCompoundRates(t *testing.T) {
+	// 5% a year over 6570 blocks a day, compounded daily.
+	perBlock, _ := new(big.Float).Mul(big.NewFloat(0.05/365/CompoundBlocksPerDay), big.NewFloat(1e18)).Int(nil)
+	if apy := compoundAPY(perBlock); apy < 0.0512 || apy > 0.0513 {
+		t.Errorf("Expected an APY of 5.127%%, got %v", apy)
+	}
+	if used := utilization(big.NewInt(60), big.NewInt(50), big.NewInt(10)); used != 0.5 {
+		t.Errorf("Expected a utilization of 0.5, got %v", used)
+	}
+	if used := utilization(big.NewInt(0), big.NewInt(0), big.NewInt(0)); used != 0 {
+		t.Errorf("Expected an empty market to be unused, got %v", used)
+	}
+	if _, err := defiClient.Compound().RateHistory(context.Background(), DAI, 10, 5, 1); !errors.Is(err, ErrBadBlockRange) {
+		t.Errorf("Expected ErrBadBlockRange, got %v", err)
+	}
+}
+
+func TestInteractWithCompoundRates(t *testing.T) {
+	ctx := context.Background()
+	rates, err := defiClient.Compound().Rates(ctx, DAI, nil)
+	if err != nil {
+		t.Fatalf("Failed to get rates: %v", err)
+	}
+	if rates.BorrowAPY <= rates.SupplyAPY || rates.Utilization <= 0 || rates.Utilization >= 1 {
+		t.Errorf("Unexpected rates: %v", rates)
+	}
+	from := new(big.Int).Sub(rates.Block, big.NewInt(4)).Uint64()
+	history, err := defiClient.Compound().RateHistory(ctx, DAI, from, rates.Block.Uint64(), 2)
+	if err != nil {
+		t.Fatalf("Failed to get rate history: %v", err)
+	}
+	if len(history) != 3 || history[2].Block.Cmp(rates.Block) != 0 {
+		t.Errorf("Expected 3 samples up to block %v, got %v", rates.Block, history)
+	}
+
+	if err := defiClient.Compound().Supply(ctx, big.NewInt(1e18), DAI); err != nil {
+		t.Fatalf("Failed to supply: %v", err)
+	}
+	underlying, err := defiClient.Compound().BalanceOfUnderlying(ctx, DAI, nil)
+	if err != nil || underlying.Cmp(big.NewInt(1e18-1e12)) < 0 {
+		t.Errorf("Expected at least the supplied DAI, got %v %v", underlying, err)
+	}
+}
+
+func TestCompAccrual(t *testing.T) {
+	// 10 COMP a block for 5 blocks, shared by 100 cTokens.
+	index := updatedCompIndex(compInitialIndex, 10, 15, big.NewInt(10), big.NewInt(100))
+	if want := new(big.Int).Add(compInitialIndex, new(big.Int).Div(pow10(36), big.NewInt(2))); index.Cmp(want) != 0 {
+		t.Errorf("Expected an index of 1.5e36, got %v", index)
+	}
+	if delta := compDelta(big.NewInt(40), index, compInitialIndex); delta.Int64() != 20 {
+		t.Errorf("Expected 20 COMP for 40 of the 100 cTokens, got %v", delta)
+	}
+	if same := updatedCompIndex(compInitialIndex, 10, 15, big.NewInt(0), big.NewInt(100)); same.Cmp(compInitialIndex) != 0 {
+		t.Errorf("Expected a market without speed to keep its index, got %v", same)
+	}
+
+	// The speeds are split in a supply and a borrow speed, or are a single speed before.
+	market := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	for _, test := range []struct {
+		calls          map[string]*big.Int
+		supply, borrow int64
+	}{
+		{map[string]*big.Int{"compSupplySpeeds(address)": big.NewInt(2), "compBorrowSpeeds(address)": big.NewInt(3)}, 2, 3},
+		{map[string]*big.Int{"compSpeeds(address)": big.NewInt(5)}, 5, 5},
+	} {
+		speedClient, err := NewClient(signer, fakeNode(t, 0, test.calls), WithNetwork(MainnetFork()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		comptrollerContract, err := speedClient.Compound().comptroller()
+		if err != nil {
+			t.Fatal(err)
+		}
+		supply, borrow, err := speedClient.Compound().compSpeeds(&bind.CallOpts{}, comptrollerContract, market)
+		if err != nil || supply.Int64() != test.supply || borrow.Int64() != test.borrow {
+			t.Errorf("Expected speeds %d and %d, got %v %v %v", test.supply, test.borrow, supply, borrow, err)
+		}
+	}
+
+	if err := defiClient.Compound().ClaimCompActions().Err(); !errors.Is(err, ErrNoHandler) {
+		t.Errorf("Expected ErrNoHandler, got %v", err)
+	}
+	network := Mainnet()
+	network.Handlers.Comptroller = common.HexToAddress("0x3333333333333333333333333333333333333333")
+	c, err := NewClient(signer, ethClient, WithNetwork(network))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := c.Compound().ClaimCompActions()
+	if err := actions.Err(); err != nil || actions.Actions[0].handlerAddr != network.Handlers.Comptroller {
+		t.Errorf("Expected a claim with the Comptroller handler: %v", err)
+	}
+}
+
+func TestInteractWithCompoundComp(t *testing.T) {
+	ctx := context.Background()
+	compound := defiClient.Compound()
+	if err := compound.Supply(ctx, big.NewInt(1e18), DAI); err != nil {
+		t.Fatalf("Failed to supply: %v", err)
+	}
+	accrual, err := compound.CompAccrued(ctx, fromAddr)
+	if err != nil {
+		t.Fatalf("Failed to compute accrued COMP: %v", err)
+	}
+	if accrual.Total.Sign() <= 0 || len(accrual.Markets) == 0 {
+		t.Errorf("Expected COMP for the DAI supply: %+v", accrual)
+	}
+	before, err := defiClient.BalanceOf(ctx, COMP, nil)
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	if err := compound.ClaimComp(ctx); err != nil {
+		t.Fatalf("Failed to claim COMP: %v", err)
+	}
+	after, err := defiClient.BalanceOf(ctx, COMP, nil)
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	// The claim is mined in the block the accrual is computed for.
+	if claimed := new(big.Int).Sub(after, before); claimed.Cmp(accrual.Total.Int()) < 0 {
+		t.Errorf("Claimed %v, less than the accrued %v", claimed, accrual.Total)
+	}
+}
@@ -0,0 +1,310 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/cToken"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/comptroller"
+	"github.com/rafaelescrich/go-defi-1/binding/hcomptroller"
+)
+
+var (
+	// ErrNoHandler is returned when an action needs a handler the network config has no address for.
+	ErrNoHandler = errors.New("no handler in the network config")
+	// ErrNoComp is returned when there is no COMP to claim.
+	ErrNoComp = errors.New("no COMP to claim")
+)
+
+// comptrollerSpeedsABI is the interface of the COMP speeds of the Comptroller since they were split
+// in a supply and a borrow speed, which replaced the single `compSpeeds` of a market.
+const comptrollerSpeedsABI = `[{"inputs":[{"internalType":"address","name":"","type":"address"}],"name":"compSupplySpeeds","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"","type":"address"}],"name":"compBorrowSpeeds","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// compInitialIndex is the index of the markets when COMP started, and of the suppliers that
+// supplied before.
+var compInitialIndex = pow10(36)
+
+// CompMarketAccrual is the COMP of a holder in a market, earned since it last accrued in the Comptroller.
+type CompMarketAccrual struct {
+	Market   common.Address
+	Supplier Amount
+	Borrower Amount
+}
+
+// CompAccrual is the COMP a holder can claim at a block.
+type CompAccrual struct {
+	Holder common.Address
+	Block  *big.Int
+	// Accrued is the COMP kept for the holder in the Comptroller.
+	Accrued Amount
+	// Markets are the markets the holder earns COMP in.
+	Markets []CompMarketAccrual
+	// Total is the accrued COMP and the COMP of the markets, which a claim transfers.
+	Total Amount
+}
+
+// updatedCompIndex returns the COMP index of a market, scaled by 1e36, moved from the block of its
+// state to `block` at `speed` COMP a block shared by `shares`, as the Comptroller would.
+func updatedCompIndex(index *big.Int, stateBlock uint64, block uint64, speed *big.Int, shares *big.Int) *big.Int {
+	if speed.Sign() == 0 || block <= stateBlock || shares.Sign() == 0 {
+		return index
+	}
+	accrued := new(big.Int).Mul(speed, new(big.Int).SetUint64(block-stateBlock))
+	return new(big.Int).Add(index, share(accrued, pow10(36), shares))
+}
+
+// compDelta returns the COMP earned by `shares` since the index of the account.
+func compDelta(shares *big.Int, index *big.Int, accountIndex *big.Int) *big.Int {
+	if accountIndex.Cmp(index) >= 0 {
+		return big.NewInt(0)
+	}
+	return share(shares, new(big.Int).Sub(index, accountIndex), pow10(36))
+}
+
+// compSpeeds returns the COMP a block of the suppliers and of the borrowers of `market`. A
+// Comptroller without the split speeds reverts on them, its `compSpeeds` is the speed of both.
+func (c *CompoundClient) compSpeeds(opts *bind.CallOpts, comptrollerContract *comptroller.Comptroller, market common.Address) (*big.Int, *big.Int, error) {
+	parsed, err := abi.JSON(strings.NewReader(comptrollerSpeedsABI))
+	if err != nil {
+		return nil, nil, err
+	}
+	contract := bind.NewBoundContract(c.client.network.Contracts.CompoundComptroller, parsed, c.client.conn, nil, nil)
+	speeds := make([]*big.Int, 0, 2)
+	for _, method := range []string{"compSupplySpeeds", "compBorrowSpeeds"} {
+		var out []interface{}
+		if err := contract.Call(opts, &out, method, market); err != nil {
+			if _, _, reverted := decodeRevert(err); !reverted {
+				return nil, nil, fmt.Errorf("Error getting COMP speed of market %s: %w", market.Hex(), err)
+			}
+			speed, err := comptrollerContract.CompSpeeds(opts, market)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Error getting COMP speed of market %s: %w", market.Hex(), err)
+			}
+			return speed, speed, nil
+		}
+		speeds = append(speeds, *abi.ConvertType(out[0], new(*big.Int)).(**big.Int))
+	}
+	return speeds[0], speeds[1], nil
+}
+
+// CompAccrued computes the COMP `holder` can claim at the latest block: the COMP accrued in the
+// Comptroller, and the COMP earned in each market since, from the supply and borrow indices of
+// the markets and of the holder.
+func (c *CompoundClient) CompAccrued(ctx context.Context, holder common.Address) (*CompAccrual, error) {
+	header, err := c.client.conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting latest block: %w", err)
+	}
+	// The claim is mined in a later block, the indices move to the block after the latest.
+	block := header.Number.Uint64() + 1
+	opts := c.client.callOpts(ctx, header.Number)
+
+	comptrollerContract, err := c.comptroller()
+	if err != nil {
+		return nil, err
+	}
+	compAddr, err := comptrollerContract.GetCompAddress(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting COMP address: %w", err)
+	}
+	comp, err := c.client.tokens.Fetch(ctx, c.client.conn, compAddr)
+	if err != nil {
+		return nil, err
+	}
+	accrued, err := comptrollerContract.CompAccrued(opts, holder)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting accrued COMP of %s: %w", holder.Hex(), err)
+	}
+	markets, err := comptrollerContract.GetAllMarkets(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Compound markets: %w", err)
+	}
+
+	total := new(big.Int).Set(accrued)
+	accrual := &CompAccrual{Holder: holder, Block: header.Number, Accrued: RawAmount(accrued, comp)}
+	for _, market := range markets {
+		cTokenContract, err := cToken.NewCToken(market, c.client.conn)
+		if err != nil {
+			return nil, err
+		}
+		code, cTokens, borrowed, _, err := cTokenContract.GetAccountSnapshot(opts, holder)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting account snapshot of market %s: %w", market.Hex(), err)
+		}
+		if code.Sign() != 0 {
+			return nil, compoundError("getAccountSnapshot", code, cTokenErrors)
+		}
+		if cTokens.Sign() == 0 && borrowed.Sign() == 0 {
+			continue
+		}
+		supplySpeed, borrowSpeed, err := c.compSpeeds(opts, comptrollerContract, market)
+		if err != nil {
+			return nil, err
+		}
+
+		supplier := big.NewInt(0)
+		if cTokens.Sign() > 0 {
+			state, err := comptrollerContract.CompSupplyState(opts, market)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting COMP supply state of market %s: %w", market.Hex(), err)
+			}
+			totalSupply, err := cTokenContract.TotalSupply(opts)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting total supply of market %s: %w", market.Hex(), err)
+			}
+			supplierIndex, err := comptrollerContract.CompSupplierIndex(opts, market, holder)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting COMP supplier index in market %s: %w", market.Hex(), err)
+			}
+			index := updatedCompIndex(state.Index, uint64(state.Block), block, supplySpeed, totalSupply)
+			if supplierIndex.Sign() == 0 && index.Sign() > 0 {
+				supplierIndex = compInitialIndex
+			}
+			supplier = compDelta(cTokens, index, supplierIndex)
+		}
+
+		borrower := big.NewInt(0)
+		if borrowed.Sign() > 0 {
+			borrowerIndex, err := comptrollerContract.CompBorrowerIndex(opts, market, holder)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting COMP borrower index in market %s: %w", market.Hex(), err)
+			}
+			// A borrower earns from the first time its index is set, as it borrows or claims.
+			if borrowerIndex.Sign() > 0 {
+				state, err := comptrollerContract.CompBorrowState(opts, market)
+				if err != nil {
+					return nil, fmt.Errorf("Error getting COMP borrow state of market %s: %w", market.Hex(), err)
+				}
+				borrowIndex, err := cTokenContract.BorrowIndex(opts)
+				if err != nil {
+					return nil, fmt.Errorf("Error getting borrow index of market %s: %w", market.Hex(), err)
+				}
+				totalBorrows, err := cTokenContract.TotalBorrows(opts)
+				if err != nil {
+					return nil, fmt.Errorf("Error getting borrows of market %s: %w", market.Hex(), err)
+				}
+				// The borrows are shared in principals, i.e. the borrows over the borrow index.
+				principals := share(totalBorrows, pow10(18), borrowIndex)
+				index := updatedCompIndex(state.Index, uint64(state.Block), block, borrowSpeed, principals)
+				borrower = compDelta(share(borrowed, pow10(18), borrowIndex), index, borrowerIndex)
+			}
+		}
+
+		if supplier.Sign() == 0 && borrower.Sign() == 0 {
+			continue
+		}
+		total.Add(total, supplier)
+		total.Add(total, borrower)
+		accrual.Markets = append(accrual.Markets, CompMarketAccrual{
+			Market:   market,
+			Supplier: RawAmount(supplier, comp),
+			Borrower: RawAmount(borrower, comp),
+		})
+	}
+	accrual.Total = RawAmount(total, comp)
+	return accrual, nil
+}
+
+// ClaimComp claims the COMP of the account in all the markets, or in the markets of `coins`.
+func (c *CompoundClient) ClaimComp(ctx context.Context, coins ...Token) error {
+	comptrollerContract, err := c.comptroller()
+	if err != nil {
+		return err
+	}
+	markets := make([]common.Address, 0, len(coins))
+	for _, coin := range coins {
+		cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+		if err != nil {
+			return err
+		}
+		markets = append(markets, cTokenAddr)
+	}
+
+	opts, err := c.client.transactOpts(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var tx *types.Transaction
+	if len(markets) == 0 {
+		tx, err = comptrollerContract.ClaimComp(opts, c.client.opts.From)
+	} else {
+		tx, err = comptrollerContract.ClaimComp0(opts, c.client.opts.From, markets)
+	}
+	if err != nil {
+		c.client.releaseNonce(opts)
+		return err
+	}
+	_, err = c.client.waitMined(ctx, tx)
+	return err
+}
+
+// ClaimCompActions creates an action claiming the COMP of the user in all the markets. The COMP
+// is transferred to the user, not to the proxy, see `ReinvestCompActions` to use it in the combo.
+// The Comptroller handler has to be set in the network config.
+func (c *CompoundClient) ClaimCompActions() *Actions {
+	handlerAddr := c.client.network.Handlers.Comptroller
+	if handlerAddr == (common.Address{}) {
+		return failedActions("Compound", "claimComp", "", fmt.Errorf("%w: comptroller", ErrNoHandler))
+	}
+	data, err := packAction("Compound", hcomptroller.HcomptrollerABI, "claimComp")
+	if err != nil {
+		return failedActions("Compound", "claimComp", "", err)
+	}
+	return &Actions{
+		Actions: []action{
+			{
+				handlerAddr:  handlerAddr,
+				data:         data,
+				ethersNeeded: big.NewInt(0),
+			},
+		},
+	}
+}
+
+// ReinvestCompActions creates the actions claiming the COMP of the user, swapping it to `coin`
+// on Uniswap and supplying the output to the market of `coin`. The COMP swapped is the COMP
+// claimable at the latest block, the COMP earned until the combo is mined stays with the user.
+// The swap gets at least its Uniswap quote less `slippageBps`, which is the amount supplied,
+// and any output above it is returned. The proxy must be approved for the COMP.
+func (c *CompoundClient) ReinvestCompActions(ctx context.Context, coin Token, slippageBps uint64) *Actions {
+	if _, err := c.getPoolAddrFromCoin(coin); err != nil {
+		return failedActions("Compound", "mint", "coin", err)
+	}
+	accrual, err := c.CompAccrued(ctx, c.client.opts.From)
+	if err != nil {
+		return failedActions("Compound", "claimComp", "", err)
+	}
+	comp := accrual.Total
+	if comp.Sign() == 0 {
+		return failedActions("Compound", "claimComp", "", ErrNoComp)
+	}
+	quote, err := c.client.Quoter().Uniswap(ctx, comp, comp.Token, coin)
+	if err != nil {
+		return failedActions("Uniswap", "swap", "", err)
+	}
+	minOut, err := applySlippage(quote.Output.Int(), slippageBps)
+	if err != nil {
+		return failedActions("Uniswap", "swap", "", err)
+	}
+
+	swap := c.client.Uniswap().SwapActions(ctx, comp, coin, comp.Token, WithMinOutput(minOut))
+	supply := c.SupplyActions(minOut, coin)
+	// The swap and the supply take the COMP and the output in the proxy. The claimed COMP is
+	// injected after the claim, instead of with the funds of the combo before it.
+	for _, step := range []*Actions{swap, supply} {
+		for i := range step.Actions {
+			step.Actions[i].approvalTokens, step.Actions[i].approvalTokenAmounts = nil, nil
+			step.Actions[i].ethersNeeded = big.NewInt(0)
+		}
+	}
+	actions := new(Actions)
+	actions.Add(c.ClaimCompActions(), c.client.SupplyFundActions(comp, comp.Token), swap, supply)
+	return actions
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// DefaultSwapDeadline is the default time a direct swap stays valid after the latest block.
+const DefaultSwapDeadline = 20 * time.Minute
+
+// ErrDeadlineExpired is returned when the deadline of a swap has passed before it is mined.
+var ErrDeadlineExpired = errors.New("swap deadline expired")
+
+// WithSwapDeadline sets how long the swaps sent directly to the Uniswap router stay valid, relative
+// to the timestamp of the latest block when they are sent. A delayed transaction reverts instead of
+// executing at a stale price. Zero or less means `DefaultSwapDeadline`.
+// The swaps in a combo have no deadline, the handlers pass the timestamp of their own block.
+func WithSwapDeadline(deadline time.Duration) Option {
+	return func(c *DefiClient) {
+		c.swapDeadline = deadline
+	}
+}
+
+// WithDeadline sets the absolute deadline of a direct swap, overriding the client policy.
+// A deadline that already passed at the latest block fails the swap before anything is sent.
+func WithDeadline(deadline time.Time) SwapOption {
+	return func(o *swapOptions) {
+		o.deadline = deadline
+	}
+}
+
+// deadlineOf returns the deadline of a direct router call as a unix timestamp, from the latest
+// block timestamp and the policy of the client, or the absolute `deadline` if it is set.
+func (c *DefiClient) deadlineOf(ctx context.Context, deadline time.Time) (*big.Int, error) {
+	header, err := c.conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting latest block: %w", err)
+	}
+	if !deadline.IsZero() {
+		if deadline.Unix() <= int64(header.Time) {
+			return nil, fmt.Errorf("%w: %s is before block %v at %s",
+				ErrDeadlineExpired, deadline.UTC().Format(time.RFC3339), header.Number, time.Unix(int64(header.Time), 0).UTC().Format(time.RFC3339))
+		}
+		return big.NewInt(deadline.Unix()), nil
+	}
+	relative := c.swapDeadline
+	if relative <= 0 {
+		relative = DefaultSwapDeadline
+	}
+	return new(big.Int).SetUint64(header.Time + uint64(relative/time.Second)), nil
+}
+
+// routerError marks the revert of an expired router call with `ErrDeadlineExpired`. The binding
+// estimates the gas of the call before sending it, so an expired swap fails there without a transaction.
+func routerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if reason, _, ok := decodeRevert(err); ok && strings.HasSuffix(reason, "EXPIRED") {
+		return fmt.Errorf("%w: %v", ErrDeadlineExpired, err)
+	}
+	return err
+}
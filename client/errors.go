@@ -0,0 +1,148 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrNilActions is returned when a nil `Actions` is added or executed.
+	ErrNilActions = errors.New("actions is nil")
+	// ErrNilAmount is returned when a nil amount is passed to an action builder.
+	ErrNilAmount = errors.New("amount is nil")
+	// ErrUnsupportedCoin is returned when a coin has no known address for the protocol.
+	ErrUnsupportedCoin = errors.New("coin is not supported")
+)
+
+// ActionError is the error carried by an `Actions` that failed to build.
+// It names the protocol, the handler method and, when known, the argument that caused the failure.
+type ActionError struct {
+	Protocol string
+	Method   string
+	Arg      string
+	Err      error
+}
+
+func (e *ActionError) Error() string {
+	if e.Arg == "" {
+		return fmt.Sprintf("%s %s: %v", e.Protocol, e.Method, e.Err)
+	}
+	return fmt.Sprintf("%s %s: bad argument %s: %v", e.Protocol, e.Method, e.Arg, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// failedActions returns an `Actions` which carries the build error instead of any action.
+func failedActions(protocol string, method string, arg string, err error) *Actions {
+	var actionErr *ActionError
+	if errors.As(err, &actionErr) {
+		return &Actions{err: err}
+	}
+	return &Actions{err: &ActionError{Protocol: protocol, Method: method, Arg: arg, Err: err}}
+}
+
+// packAction packs the call data of `method` from the given handler ABI.
+// If packing fails the returned *ActionError names the offending argument.
+func packAction(protocol string, abiJSON string, method string, args ...interface{}) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, &ActionError{Protocol: protocol, Method: method, Err: err}
+	}
+	m, ok := parsed.Methods[method]
+	if !ok {
+		return nil, &ActionError{Protocol: protocol, Method: method, Err: fmt.Errorf("method not found in handler ABI")}
+	}
+	if len(args) != len(m.Inputs) {
+		return nil, &ActionError{
+			Protocol: protocol, Method: method,
+			Err: fmt.Errorf("got %d arguments, want %d", len(args), len(m.Inputs)),
+		}
+	}
+	// A nil *big.Int makes the ABI encoder panic, so it's caught here.
+	for i, arg := range args {
+		if amount, ok := arg.(*big.Int); ok && amount == nil {
+			return nil, &ActionError{Protocol: protocol, Method: method, Arg: m.Inputs[i].Name, Err: ErrNilAmount}
+		}
+	}
+
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		actionErr := &ActionError{Protocol: protocol, Method: method, Err: err}
+		for i, input := range m.Inputs {
+			if _, argErr := (abi.Arguments{input}).Pack(args[i]); argErr != nil {
+				actionErr.Arg = input.Name
+				break
+			}
+		}
+		return nil, actionErr
+	}
+	return data, nil
+}
+
+// coinAddr returns the ERC-20 address of the given coin, ETH is mapped to the WETH of the network.
+// The zero Token isn't a coin.
+func (c *DefiClient) coinAddr(coin Token) (common.Address, error) {
+	if coin != (Token{}) {
+		return c.erc20Address(coin), nil
+	}
+	return common.Address{}, fmt.Errorf("%w: %v", ErrUnsupportedCoin, coin)
+}
+
+var (
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+)
+
+// ExecutionError is returned when a combo reverts, either on chain or in a simulation.
+type ExecutionError struct {
+	// Index is the position of the failing action in the `Actions` list, -1 if it is unknown.
+	// A failure inside a flash loan or flash swap points to the flash loan action.
+	Index int
+	// Handler is the address of the handler that reverted, the zero address if it is unknown.
+	Handler common.Address
+	// Message is the decoded revert reason.
+	Message string
+	// Data is the raw revert data, if the node returned it.
+	Data []byte
+	// TxHash is the hash of the reverted transaction, it is empty for a simulation.
+	TxHash common.Hash
+}
+
+func (e *ExecutionError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = "reverted without a reason"
+	}
+	if e.Index < 0 {
+		return fmt.Sprintf("combo reverted: %s", msg)
+	}
+	return fmt.Sprintf("action %d (handler %s) reverted: %s", e.Index, e.Handler.Hex(), msg)
+}
+
+// decodeRevertData decodes revert data into a human readable message.
+// Besides Error(string) it understands Panic(uint256), other custom errors are reported by selector.
+func decodeRevertData(data []byte) string {
+	switch {
+	case len(data) < 4:
+		return ""
+	case bytes.Equal(data[:4], errorSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return fmt.Sprintf("malformed Error(string): 0x%x", data)
+		}
+		return reason
+	case bytes.Equal(data[:4], panicSelector) && len(data) == 36:
+		return fmt.Sprintf("panic: code 0x%x", new(big.Int).SetBytes(data[4:]))
+	default:
+		return fmt.Sprintf("custom error 0x%x", data[:4])
+	}
+}
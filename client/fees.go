@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// baseFeeMultiplier is the headroom of the max fee over the current base fee. Doubling it
+// keeps the transaction valid through 6 consecutive full blocks.
+const baseFeeMultiplier = 2
+
+// Fees are the fees a transaction is priced with.
+// On chains with EIP-1559 GasFeeCap and GasTipCap are set and the transaction is a dynamic
+// fee transaction, otherwise only GasPrice is set and it is a legacy transaction.
+type Fees struct {
+	// GasPrice is the gas price of a legacy transaction.
+	GasPrice *big.Int
+	// GasFeeCap is the maxFeePerGas of a dynamic fee transaction.
+	GasFeeCap *big.Int
+	// GasTipCap is the maxPriorityFeePerGas of a dynamic fee transaction.
+	GasTipCap *big.Int
+}
+
+// IsDynamic returns true if the fees price a dynamic fee transaction.
+func (f *Fees) IsDynamic() bool {
+	return f.GasFeeCap != nil && f.GasTipCap != nil
+}
+
+// apply sets the fees on the transaction options.
+func (f *Fees) apply(opts *bind.TransactOpts) {
+	if f.IsDynamic() {
+		opts.GasFeeCap = f.GasFeeCap
+		opts.GasTipCap = f.GasTipCap
+		return
+	}
+	opts.GasPrice = f.GasPrice
+}
+
+// SuggestFees suggests the fees of a transaction sent now. If the latest block has a base fee,
+// the tip is the priority fee suggested by the gas oracle, see `TipOracle`, or else by the node,
+// and the max fee is twice the base fee plus the tip. The gas price of the gas oracle, see
+// `WithGasOracle`, caps the tip at what it pays over the base fee, see `dynamicFees`.
+// On chains without London the suggested price is used as a legacy gas price.
+func (c *DefiClient) SuggestFees(ctx context.Context) (*Fees, error) {
+	header, err := c.conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	price, err := c.gasOracle.SuggestGasPrice(ctx, header.Number)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return &Fees{GasPrice: price}, nil
+	}
+
+	var tip *big.Int
+	if tipOracle, ok := c.gasOracle.(TipOracle); ok {
+		tip, err = tipOracle.SuggestGasTipCap(ctx, header.Number)
+	} else {
+		tip, err = c.conn.SuggestGasTipCap(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dynamicFees(header.BaseFee, tip, price), nil
+}
+
+// dynamicFees prices a dynamic fee transaction with the priority fee `tip`, capped at what the
+// gas price `price` pays over `baseFee`. A price below the base fee, e.g. while the base fee rises
+// above the prices of the past blocks, doesn't cap the tip, so the transaction is still included.
+func dynamicFees(baseFee *big.Int, tip *big.Int, price *big.Int) *Fees {
+	tip = new(big.Int).Set(tip)
+	if over := new(big.Int).Sub(price, baseFee); over.Sign() > 0 && over.Cmp(tip) < 0 {
+		tip.Set(over)
+	}
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(baseFeeMultiplier))
+	feeCap.Add(feeCap, tip)
+	return &Fees{GasFeeCap: feeCap, GasTipCap: tip}
+}
@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultGasMultiplier is the default safety multiplier applied to the estimated gas.
+	DefaultGasMultiplier float64 = 1.2
+	// DefaultGasCap is the default maximum gas limit of a transaction.
+	DefaultGasCap uint64 = 10000000
+)
+
+var (
+	// ErrGasCapExceeded is returned when the estimated gas of a transaction is above the gas cap.
+	ErrGasCapExceeded = errors.New("estimated gas exceeds the gas cap")
+	// ErrActionNotTraced is set on an action whose handler call isn't found in the trace of the combo.
+	ErrActionNotTraced = errors.New("no call to the handler of the action in the trace")
+)
+
+// WithGasLimit sets the safety multiplier applied to the estimated gas and the cap of the gas limit.
+func WithGasLimit(multiplier float64, cap uint64) Option {
+	return func(c *DefiClient) {
+		c.gasMultiplier = multiplier
+		c.gasCap = cap
+	}
+}
+
+// GasEstimate is the gas estimation of a combo.
+type GasEstimate struct {
+	// Estimated is the gas the node estimates for the whole combo.
+	Estimated uint64
+	// GasLimit is the estimation scaled by the safety multiplier and capped, it is what
+	// ExecuteActions sends the combo with.
+	GasLimit uint64
+	// Actions is the gas attributed to each action, in the order of the `Actions` list. It is read
+	// from a `debug_traceCall` of the combo, so it is nil if the client has no RPC client to trace
+	// with, see `WithRPCClient`.
+	Actions []ActionGas
+}
+
+// ActionGas is the gas attributed to one action of a combo.
+type ActionGas struct {
+	Handler common.Address
+	// Gas is the gas used by the call of the proxy to the handler, including the nested actions.
+	Gas uint64
+	// Err is set if the call to the handler isn't found in the trace.
+	Err error
+	// Nested is the breakdown of the actions run inside a flash loan or a flash swap.
+	Nested []ActionGas
+}
+
+// Dominant returns the path to the most expensive action, e.g. [2, 1] is the second nested
+// action of the third action. Nested actions are preferred over the action wrapping them.
+func (e *GasEstimate) Dominant() []int {
+	return dominantAction(e.Actions)
+}
+
+func dominantAction(actions []ActionGas) []int {
+	best := -1
+	for i, action := range actions {
+		if best < 0 || action.Gas > actions[best].Gas {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return append([]int{best}, dominantAction(actions[best].Nested)...)
+}
+
+// EstimateActionsGas estimates the gas of the combo on the latest state and attributes it to
+// each action, including the actions nested in flash loans and flash swaps.
+// The gas of an action is the gas used by its handler call in a single trace of the whole combo,
+// so the nested actions are attributed inside the flash loan that repays them.
+func (c *DefiClient) EstimateActionsGas(ctx context.Context, actions *Actions) (*GasEstimate, error) {
+	combined, err := c.combine(ctx, actions, nil)
+	if err != nil {
+		return nil, err
+	}
+	total, err := c.estimateCombo(ctx, combined)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := c.gasLimit(total)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &GasEstimate{Estimated: total, GasLimit: limit}
+	if c.rpcClient == nil {
+		return estimate, nil
+	}
+
+	msg, err := c.batchExecMsg(combined)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := c.traceCall(ctx, msg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error tracing the combo: %w", err)
+	}
+	calls := handlerCalls(frame)
+	// The funds of the combo are injected by the first handler call.
+	if len(combined.approvalTokens) > 0 && len(calls) > 0 {
+		calls = calls[1:]
+	}
+	estimate.Actions = attributeGas(calls, actions.Actions)
+	return estimate, nil
+}
+
+// attributeGas matches the handler calls of the proxy, in order, to the actions of `list`.
+// The calls to handlers that aren't actions, e.g. the post processing, are skipped.
+func attributeGas(calls []*callFrame, list []action) []ActionGas {
+	breakdown := make([]ActionGas, len(list))
+	next := 0
+	for k, current := range list {
+		breakdown[k].Handler = current.handlerAddr
+		found := -1
+		for i := next; i < len(calls); i++ {
+			if calls[i].To == current.handlerAddr {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			breakdown[k].Err = ErrActionNotTraced
+			continue
+		}
+		call := calls[found]
+		next = found + 1
+		breakdown[k].Gas = uint64(call.GasUsed)
+
+		if current.nested != nil && len(current.nested.Actions) > 0 {
+			var nestedCalls []*callFrame
+			if callback := findCallback(call, current.nested.Actions[0].handlerAddr); callback != nil {
+				nestedCalls = handlerCalls(callback)
+			}
+			breakdown[k].Nested = attributeGas(nestedCalls, current.nested.Actions)
+		}
+	}
+	return breakdown
+}
+
+// handlerCalls returns the delegate calls of the proxy `frame` to the handlers, in order.
+func handlerCalls(frame *callFrame) []*callFrame {
+	calls := make([]*callFrame, 0, len(frame.Calls))
+	for i := range frame.Calls {
+		if frame.Calls[i].Type == "DELEGATECALL" {
+			calls = append(calls, &frame.Calls[i])
+		}
+	}
+	return calls
+}
+
+// findCallback finds the call back into the proxy made under `frame` by a flash loan or a flash
+// swap, i.e. the first call that delegates to `handler`, the handler of the first nested action.
+func findCallback(frame *callFrame, handler common.Address) *callFrame {
+	for i := range frame.Calls {
+		call := &frame.Calls[i]
+		for _, inner := range call.Calls {
+			if inner.Type == "DELEGATECALL" && inner.To == handler {
+				return call
+			}
+		}
+		if callback := findCallback(call, handler); callback != nil {
+			return callback
+		}
+	}
+	return nil
+}
+
+func (c *DefiClient) estimateCombo(ctx context.Context, combined *combo) (uint64, error) {
+	msg, err := c.batchExecMsg(combined)
+	if err != nil {
+		return 0, err
+	}
+	gas, err := c.conn.EstimateGas(ctx, msg)
+	if err != nil {
+		if reason, data, ok := decodeRevert(err); ok {
+			return 0, &ExecutionError{Index: -1, Message: reason, Data: data}
+		}
+		return 0, err
+	}
+	return gas, nil
+}
+
+// gasLimit scales the estimated gas by the safety multiplier and caps it.
+func (c *DefiClient) gasLimit(estimated uint64) (uint64, error) {
+	if estimated > c.gasCap {
+		return 0, fmt.Errorf("%w: %d > %d", ErrGasCapExceeded, estimated, c.gasCap)
+	}
+	limit := uint64(float64(estimated) * c.gasMultiplier)
+	if limit > c.gasCap {
+		limit = c.gasCap
+	}
+	return limit, nil
+}
+
+// transactOpts returns the options for the transactions sent directly to the protocols.
+// The fees are the suggested ones, see `SuggestFees`, and the nonce is handed out by the nonce
+// manager of the client, so the transaction doesn't collide with the combos in flight. The
+// caller gives it back with `releaseNonce` if the transaction isn't sent. The binding estimates
+// the gas, and the signer scales it by the safety multiplier before signing.
+func (c *DefiClient) transactOpts(ctx context.Context, value *big.Int) (*bind.TransactOpts, error) {
+	fees, err := c.SuggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := c.nonces.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.TransactOpts{
+		From:    c.opts.From,
+		Nonce:   new(big.Int).SetUint64(nonce),
+		Value:   value,
+		Context: ctx,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			limit, err := c.gasLimit(tx.Gas())
+			if err != nil {
+				return nil, err
+			}
+			return c.opts.Signer(addr, withGasLimit(tx, limit))
+		},
+	}
+	fees.apply(opts)
+	return opts, nil
+}
+
+// releaseNonce gives back the nonce of `opts` to the nonce manager, when its transaction wasn't sent.
+func (c *DefiClient) releaseNonce(opts *bind.TransactOpts) {
+	c.nonces.Release(opts.Nonce.Uint64())
+}
+
+// withGasLimit returns a copy of the unsigned transaction `tx` with the given gas limit.
+func withGasLimit(tx *types.Transaction, gas uint64) *types.Transaction {
+	if tx.Type() == types.DynamicFeeTxType {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        gas,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: tx.GasPrice(),
+		Gas:      gas,
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// DefaultOracleBlocks is the number of recent blocks sampled by the default gas oracle.
+	DefaultOracleBlocks = 20
+	// DefaultOraclePercentile is the percentile of the gas prices suggested by the default gas oracle.
+	DefaultOraclePercentile float64 = 50
+)
+
+var (
+	// ErrNoGasPrices is returned when the sampled blocks have no transactions to price from.
+	ErrNoGasPrices = errors.New("no gas prices in the sampled blocks")
+	// ErrBadOracleConfig is returned when an oracle samples no blocks or its percentile is not in [0, 100].
+	ErrBadOracleConfig = errors.New("bad gas oracle config")
+)
+
+// GasOracle suggests the gas price of a transaction included right after the block `blockNum`,
+// nil means the latest block. On chains with EIP-1559 the price includes the base fee, i.e. it is
+// the effective gas price that the transaction pays.
+type GasOracle interface {
+	SuggestGasPrice(ctx context.Context, blockNum *big.Int) (*big.Int, error)
+}
+
+// TipOracle is a gas oracle that also suggests the priority fee of a dynamic fee transaction.
+// `SuggestFees` takes the tip from the node for the oracles that don't implement it.
+type TipOracle interface {
+	GasOracle
+	SuggestGasTipCap(ctx context.Context, blockNum *big.Int) (*big.Int, error)
+}
+
+// WithGasOracle sets the gas oracle that prices the transactions of the client.
+func WithGasOracle(oracle GasOracle) Option {
+	return func(c *DefiClient) {
+		c.gasOracle = oracle
+	}
+}
+
+// FixedOracle always suggests the same gas price.
+type FixedOracle struct {
+	Price *big.Int
+}
+
+// SuggestGasPrice returns the fixed price.
+func (o *FixedOracle) SuggestGasPrice(ctx context.Context, blockNum *big.Int) (*big.Int, error) {
+	return new(big.Int).Set(o.Price), nil
+}
+
+// PercentileOracle suggests the given percentile of the effective gas prices paid in the last
+// blocks. Unlike a mean, it isn't skewed by a few outliers, and empty blocks are skipped.
+type PercentileOracle struct {
+	conn       *ethclient.Client
+	blocks     int
+	percentile float64
+	cache      *blockCache
+}
+
+// NewPercentileOracle creates an oracle that suggests the `percentile` of the effective gas prices
+// paid in the last `blocks` blocks.
+func NewPercentileOracle(conn *ethclient.Client, blocks int, percentile float64) *PercentileOracle {
+	return &PercentileOracle{
+		conn:       conn,
+		blocks:     blocks,
+		percentile: percentile,
+		cache:      newBlockCache(2 * blocks),
+	}
+}
+
+// SuggestGasPrice returns the percentile of the gas prices of the blocks up to `blockNum`.
+func (o *PercentileOracle) SuggestGasPrice(ctx context.Context, blockNum *big.Int) (*big.Int, error) {
+	if o.blocks < 1 || o.percentile < 0 || o.percentile > 100 {
+		return nil, fmt.Errorf("%w: %d blocks, percentile %v", ErrBadOracleConfig, o.blocks, o.percentile)
+	}
+	last, err := resolveBlock(ctx, o.conn, blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]*big.Int, 0)
+	for i := uint64(0); i < uint64(o.blocks) && i <= last; i++ {
+		blockPrices, err := o.blockPrices(ctx, last-i)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, blockPrices...)
+	}
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("%w: %d blocks up to %d", ErrNoGasPrices, o.blocks, last)
+	}
+	sortFees(prices)
+	return new(big.Int).Set(percentileFee(prices, o.percentile)), nil
+}
+
+// blockPrices returns the effective gas prices paid in a block.
+func (o *PercentileOracle) blockPrices(ctx context.Context, number uint64) ([]*big.Int, error) {
+	if prices, ok := o.cache.get(number); ok {
+		return prices, nil
+	}
+	block, err := o.conn.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, err
+	}
+	prices := EffectiveGasPrices(block)
+	o.cache.put(number, prices)
+	return prices, nil
+}
+
+// EffectiveGasPrices returns the effective gas prices paid in a block, sorted in ascending order.
+// On chains with EIP-1559 it is the base fee plus the tip each transaction paid, otherwise the
+// gas price.
+func EffectiveGasPrices(block *types.Block) []*big.Int {
+	prices := make([]*big.Int, 0, block.Transactions().Len())
+	for _, tx := range block.Transactions() {
+		if block.BaseFee() == nil {
+			prices = append(prices, tx.GasPrice())
+			continue
+		}
+		tip, err := tx.EffectiveGasTip(block.BaseFee())
+		if err != nil {
+			continue
+		}
+		prices = append(prices, tip.Add(tip, block.BaseFee()))
+	}
+	sortFees(prices)
+	return prices
+}
+
+// FeeHistoryOracle suggests the base fee of the next block plus the median of the given
+// percentile of the priority fees paid in the last blocks, as reported by eth_feeHistory.
+// It needs a node serving eth_feeHistory, which doesn't require London: before it the base
+// fee is zero and the priority fee is the gas price.
+type FeeHistoryOracle struct {
+	conn       *ethclient.Client
+	blocks     int
+	percentile float64
+	cache      *blockCache
+}
+
+// NewFeeHistoryOracle creates an oracle from the `percentile` of the priority fees paid in the
+// last `blocks` blocks.
+func NewFeeHistoryOracle(conn *ethclient.Client, blocks int, percentile float64) *FeeHistoryOracle {
+	return &FeeHistoryOracle{
+		conn:       conn,
+		blocks:     blocks,
+		percentile: percentile,
+		cache:      newBlockCache(8),
+	}
+}
+
+// SuggestGasPrice returns the gas price suggested from the fee history up to `blockNum`.
+func (o *FeeHistoryOracle) SuggestGasPrice(ctx context.Context, blockNum *big.Int) (*big.Int, error) {
+	tip, baseFee, err := o.suggest(ctx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Add(tip, baseFee), nil
+}
+
+// SuggestGasTipCap returns the median of the percentile of the priority fees paid in the blocks
+// up to `blockNum`.
+func (o *FeeHistoryOracle) SuggestGasTipCap(ctx context.Context, blockNum *big.Int) (*big.Int, error) {
+	tip, _, err := o.suggest(ctx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(tip), nil
+}
+
+// suggest returns the suggested tip and the base fee of the block after `blockNum`.
+func (o *FeeHistoryOracle) suggest(ctx context.Context, blockNum *big.Int) (*big.Int, *big.Int, error) {
+	if o.blocks < 1 || o.percentile < 0 || o.percentile > 100 {
+		return nil, nil, fmt.Errorf("%w: %d blocks, percentile %v", ErrBadOracleConfig, o.blocks, o.percentile)
+	}
+	last, err := resolveBlock(ctx, o.conn, blockNum)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cached, ok := o.cache.get(last); ok {
+		return cached[0], cached[1], nil
+	}
+
+	history, err := o.conn.FeeHistory(ctx, uint64(o.blocks), new(big.Int).SetUint64(last), []float64{o.percentile})
+	if err != nil {
+		return nil, nil, err
+	}
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for i, rewards := range history.Reward {
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		if len(rewards) > 0 && rewards[0] != nil {
+			tips = append(tips, rewards[0])
+		}
+	}
+	if len(tips) == 0 {
+		return nil, nil, fmt.Errorf("%w: %d blocks up to %d", ErrNoGasPrices, o.blocks, last)
+	}
+
+	// The base fees include the one of the block after the last one.
+	tip := medianFee(tips)
+	baseFee := big.NewInt(0)
+	if n := len(history.BaseFee); n > 0 && history.BaseFee[n-1] != nil {
+		baseFee = history.BaseFee[n-1]
+	}
+	o.cache.put(last, []*big.Int{tip, baseFee})
+	return tip, baseFee, nil
+}
+
+// resolveBlock returns the number of `blockNum`, or of the latest block if it is nil.
+func resolveBlock(ctx context.Context, conn *ethclient.Client, blockNum *big.Int) (uint64, error) {
+	if blockNum != nil {
+		return blockNum.Uint64(), nil
+	}
+	header, err := conn.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+// blockCache is a small in-memory cache of values keyed by block number. When it is full, the
+// lowest block is evicted since the oracles mostly move forward.
+type blockCache struct {
+	mu     sync.Mutex
+	size   int
+	values map[uint64][]*big.Int
+}
+
+func newBlockCache(size int) *blockCache {
+	return &blockCache{size: size, values: make(map[uint64][]*big.Int)}
+}
+
+func (c *blockCache) get(number uint64) ([]*big.Int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values, ok := c.values[number]
+	return values, ok
+}
+
+func (c *blockCache) put(number uint64, values []*big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size < 1 {
+		return
+	}
+	if _, ok := c.values[number]; !ok && len(c.values) >= c.size {
+		lowest := uint64(math.MaxUint64)
+		for n := range c.values {
+			if n < lowest {
+				lowest = n
+			}
+		}
+		delete(c.values, lowest)
+	}
+	c.values[number] = values
+}
+
+// sortFees sorts a list of fees in ascending order.
+func sortFees(fees []*big.Int) {
+	sort.Slice(fees, func(i, j int) bool {
+		return fees[i].Cmp(fees[j]) < 0
+	})
+}
+
+// percentileFee returns the nearest-rank percentile of a sorted, non-empty list of fees.
+func percentileFee(sorted []*big.Int, percentile float64) *big.Int {
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// medianFee returns the median of a non-empty list of fees, the list is sorted in place.
+func medianFee(fees []*big.Int) *big.Int {
+	sortFees(fees)
+	mid := len(fees) / 2
+	if len(fees)%2 == 1 {
+		return new(big.Int).Set(fees[mid])
+	}
+	median := new(big.Int).Add(fees[mid-1], fees[mid])
+	return median.Rsh(median, 1)
+}
@@ -0,0 +1,285 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/huniswap"
+	"github.com/rafaelescrich/go-defi-1/binding/uniswap/factory"
+	"github.com/rafaelescrich/go-defi-1/binding/uniswap/pair"
+)
+
+// ErrNoPair is returned when the factory has no pair for two tokens.
+var ErrNoPair = errors.New("no pair")
+
+// LiquidityPosition is the share of an address in a Uniswap V2 pair, Uniswap or Sushiswap.
+type LiquidityPosition struct {
+	// Pair is the address of the pair, which is also the LP token.
+	Pair common.Address
+	// Liquidity is the LP token balance of the address.
+	Liquidity   *big.Int
+	TotalSupply *big.Int
+	// AmountA and AmountB are the tokens the liquidity is redeemable for.
+	AmountA Amount
+	AmountB Amount
+	// Value is the value of the position in token B, at the price of the pair.
+	Value Amount
+}
+
+// pairState is a pair read at the latest block, its reserves ordered as the tokens asked for.
+type pairState struct {
+	addr        common.Address
+	reserveA    *big.Int
+	reserveB    *big.Int
+	totalSupply *big.Int
+}
+
+// pairState reads the pair of `tokenA` and `tokenB` of the factory at `factoryAddr`.
+func (c *DefiClient) pairState(ctx context.Context, factoryAddr common.Address, tokenA Token, tokenB Token) (*pairState, error) {
+	addrA, addrB := c.erc20Address(tokenA), c.erc20Address(tokenB)
+	uniFactory, err := factory.NewFactory(factoryAddr, c.conn)
+	if err != nil {
+		return nil, err
+	}
+	opts := c.callOpts(ctx, nil)
+	pairAddr, err := uniFactory.GetPair(opts, addrA, addrB)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting pair: %w", err)
+	}
+	if pairAddr == (common.Address{}) {
+		return nil, fmt.Errorf("%w of %s and %s", ErrNoPair, tokenA, tokenB)
+	}
+	uniPair, err := pair.NewPair(pairAddr, c.conn)
+	if err != nil {
+		return nil, err
+	}
+	reserves, err := uniPair.GetReserves(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting reserves of %s: %w", pairAddr.Hex(), err)
+	}
+	totalSupply, err := uniPair.TotalSupply(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting total supply of %s: %w", pairAddr.Hex(), err)
+	}
+	state := &pairState{addr: pairAddr, reserveA: reserves.Reserve0, reserveB: reserves.Reserve1, totalSupply: totalSupply}
+	// The pairs sort their tokens by address.
+	if bytes.Compare(addrB.Bytes(), addrA.Bytes()) < 0 {
+		state.reserveA, state.reserveB = state.reserveB, state.reserveA
+	}
+	return state, nil
+}
+
+// optimalLiquidity returns the amounts the router deposits out of the desired ones, at the ratio of
+// the reserves. A new or empty pair takes the desired amounts.
+func optimalLiquidity(desiredA *big.Int, desiredB *big.Int, reserveA *big.Int, reserveB *big.Int) (*big.Int, *big.Int) {
+	if reserveA == nil || reserveB == nil || reserveA.Sign() == 0 || reserveB.Sign() == 0 {
+		return desiredA, desiredB
+	}
+	optimalB := new(big.Int).Mul(desiredA, reserveB)
+	optimalB.Div(optimalB, reserveA)
+	if optimalB.Cmp(desiredB) <= 0 {
+		return desiredA, optimalB
+	}
+	optimalA := new(big.Int).Mul(desiredB, reserveA)
+	optimalA.Div(optimalA, reserveB)
+	return optimalA, desiredB
+}
+
+// addLiquidityActions creates the action depositing `amountA` and `amountB` in their pair of the
+// factory at `factoryAddr` with the handler at `handler`. The minimums are the amounts the current
+// reserves take, less the slippage tolerance.
+func (c *DefiClient) addLiquidityActions(ctx context.Context, protocol string, handler common.Address, factoryAddr common.Address, amountA Size, tokenA Token, amountB Size, tokenB Token, options []SwapOption) *Actions {
+	if err := c.checkPath([]Token{tokenA, tokenB}); err != nil {
+		return failedActions(protocol, "addLiquidity", "tokens", err)
+	}
+	desiredA, err := c.sizeOf(amountA, c.erc20Address(tokenA))
+	if err != nil {
+		return failedActions(protocol, "addLiquidity", "amountA", err)
+	}
+	desiredB, err := c.sizeOf(amountB, c.erc20Address(tokenB))
+	if err != nil {
+		return failedActions(protocol, "addLiquidity", "amountB", err)
+	}
+	if desiredA == nil || desiredB == nil {
+		return failedActions(protocol, "addLiquidity", "amount", ErrNilAmount)
+	}
+	o := newSwapOptions(options)
+	if err := o.check(); err != nil {
+		return failedActions(protocol, "addLiquidity", "slippage", err)
+	}
+
+	var reserveA, reserveB *big.Int
+	state, err := c.pairState(ctx, factoryAddr, tokenA, tokenB)
+	switch {
+	case err == nil:
+		reserveA, reserveB = state.reserveA, state.reserveB
+	case !errors.Is(err, ErrNoPair):
+		return failedActions(protocol, "addLiquidity", "pair", err)
+	}
+	optimalA, optimalB := optimalLiquidity(desiredA, desiredB, reserveA, reserveB)
+	minA, _ := applySlippage(optimalA, o.slippageBps)
+	minB, _ := applySlippage(optimalB, o.slippageBps)
+
+	deposit := action{
+		handlerAddr:  handler,
+		ethersNeeded: big.NewInt(0),
+	}
+	switch {
+	case tokenA.IsETH():
+		deposit.ethersNeeded = desiredA
+		deposit.approvalTokens = []common.Address{tokenB.Address}
+		deposit.approvalTokenAmounts = []*big.Int{desiredB}
+		deposit.data, err = packAction(protocol, huniswap.HuniswapABI, "addLiquidityETH", desiredA, tokenB.Address, desiredB, minB, minA)
+	case tokenB.IsETH():
+		deposit.ethersNeeded = desiredB
+		deposit.approvalTokens = []common.Address{tokenA.Address}
+		deposit.approvalTokenAmounts = []*big.Int{desiredA}
+		deposit.data, err = packAction(protocol, huniswap.HuniswapABI, "addLiquidityETH", desiredB, tokenA.Address, desiredA, minA, minB)
+	default:
+		deposit.approvalTokens = []common.Address{tokenA.Address, tokenB.Address}
+		deposit.approvalTokenAmounts = []*big.Int{desiredA, desiredB}
+		deposit.data, err = packAction(protocol, huniswap.HuniswapABI, "addLiquidity", tokenA.Address, tokenB.Address, desiredA, desiredB, minA, minB)
+	}
+	if err != nil {
+		return failedActions(protocol, "addLiquidity", "", err)
+	}
+	return &Actions{Actions: []action{deposit}}
+}
+
+// removeLiquidityActions creates the action redeeming `liquidity` LP tokens of the pair of `tokenA`
+// and `tokenB` of the factory at `factoryAddr` with the handler at `handler`. The minimums are the
+// share of the current reserves, less the slippage tolerance.
+func (c *DefiClient) removeLiquidityActions(ctx context.Context, protocol string, handler common.Address, factoryAddr common.Address, liquidity Size, tokenA Token, tokenB Token, options []SwapOption) *Actions {
+	if err := c.checkPath([]Token{tokenA, tokenB}); err != nil {
+		return failedActions(protocol, "removeLiquidity", "tokens", err)
+	}
+	o := newSwapOptions(options)
+	if err := o.check(); err != nil {
+		return failedActions(protocol, "removeLiquidity", "slippage", err)
+	}
+	state, err := c.pairState(ctx, factoryAddr, tokenA, tokenB)
+	if err != nil {
+		return failedActions(protocol, "removeLiquidity", "pair", err)
+	}
+	size, err := c.sizeOf(liquidity, state.addr)
+	if err != nil {
+		return failedActions(protocol, "removeLiquidity", "liquidity", err)
+	}
+	if size == nil {
+		return failedActions(protocol, "removeLiquidity", "liquidity", ErrNilAmount)
+	}
+	if state.totalSupply.Sign() == 0 {
+		return failedActions(protocol, "removeLiquidity", "liquidity", fmt.Errorf("%w: the pair is empty", ErrBadAmount))
+	}
+	minA, _ := applySlippage(share(size, state.reserveA, state.totalSupply), o.slippageBps)
+	minB, _ := applySlippage(share(size, state.reserveB, state.totalSupply), o.slippageBps)
+
+	withdraw := action{
+		handlerAddr:          handler,
+		ethersNeeded:         big.NewInt(0),
+		approvalTokens:       []common.Address{state.addr},
+		approvalTokenAmounts: []*big.Int{size},
+	}
+	switch {
+	case tokenA.IsETH():
+		withdraw.data, err = packAction(protocol, huniswap.HuniswapABI, "removeLiquidityETH", tokenB.Address, size, minB, minA)
+	case tokenB.IsETH():
+		withdraw.data, err = packAction(protocol, huniswap.HuniswapABI, "removeLiquidityETH", tokenA.Address, size, minA, minB)
+	default:
+		withdraw.data, err = packAction(protocol, huniswap.HuniswapABI, "removeLiquidity", tokenA.Address, tokenB.Address, size, minA, minB)
+	}
+	if err != nil {
+		return failedActions(protocol, "removeLiquidity", "", err)
+	}
+	return &Actions{Actions: []action{withdraw}}
+}
+
+// liquidityPosition reads the position of `owner` in the pair of `tokenA` and `tokenB` of the factory at `factoryAddr`.
+func (c *DefiClient) liquidityPosition(ctx context.Context, factoryAddr common.Address, owner common.Address, tokenA Token, tokenB Token) (*LiquidityPosition, error) {
+	state, err := c.pairState(ctx, factoryAddr, tokenA, tokenB)
+	if err != nil {
+		return nil, err
+	}
+	uniPair, err := pair.NewPair(state.addr, c.conn)
+	if err != nil {
+		return nil, err
+	}
+	liquidity, err := uniPair.BalanceOf(c.callOpts(ctx, nil), owner)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting LP balance: %w", err)
+	}
+	position := &LiquidityPosition{
+		Pair:        state.addr,
+		Liquidity:   liquidity,
+		TotalSupply: state.totalSupply,
+		AmountA:     RawAmount(big.NewInt(0), tokenA),
+		AmountB:     RawAmount(big.NewInt(0), tokenB),
+		Value:       RawAmount(big.NewInt(0), tokenB),
+	}
+	if state.totalSupply.Sign() == 0 {
+		return position, nil
+	}
+	position.AmountA.Raw = share(liquidity, state.reserveA, state.totalSupply)
+	position.AmountB.Raw = share(liquidity, state.reserveB, state.totalSupply)
+	if state.reserveA.Sign() > 0 {
+		position.Value.Raw = share(position.AmountA.Raw, state.reserveB, state.reserveA)
+	}
+	position.Value.Raw.Add(position.Value.Raw, position.AmountB.Raw)
+	return position, nil
+}
+
+// share returns `amount` * `numerator` / `denominator`.
+func share(amount *big.Int, numerator *big.Int, denominator *big.Int) *big.Int {
+	result := new(big.Int).Mul(amount, numerator)
+	return result.Div(result, denominator)
+}
+
+// AddLiquidityActions creates an action depositing `amountA` of `tokenA` and `amountB` of `tokenB` in
+// their pair. The pair takes them at the ratio of its reserves and the rest is returned. The minimum
+// amounts are the ones the current reserves take, `WithSlippage` tolerates less.
+func (c *UniswapClient) AddLiquidityActions(ctx context.Context, amountA Size, tokenA Token, amountB Size, tokenB Token, options ...SwapOption) *Actions {
+	if c.err != nil {
+		return failedActions("Uniswap", "addLiquidity", "", c.err)
+	}
+	return c.client.addLiquidityActions(ctx,
+		"Uniswap", c.client.network.Handlers.Uniswap, c.client.network.Contracts.UniswapFactory, amountA, tokenA, amountB, tokenB, options)
+}
+
+// RemoveLiquidityActions creates an action redeeming `liquidity` LP tokens of the pair of `tokenA` and
+// `tokenB`. The minimum amounts are the share of the current reserves, `WithSlippage` tolerates less.
+func (c *UniswapClient) RemoveLiquidityActions(ctx context.Context, liquidity Size, tokenA Token, tokenB Token, options ...SwapOption) *Actions {
+	if c.err != nil {
+		return failedActions("Uniswap", "removeLiquidity", "", c.err)
+	}
+	return c.client.removeLiquidityActions(ctx,
+		"Uniswap", c.client.network.Handlers.Uniswap, c.client.network.Contracts.UniswapFactory, liquidity, tokenA, tokenB, options)
+}
+
+// LiquidityPosition returns the position of `owner` in the pair of `tokenA` and `tokenB` at the latest block.
+func (c *UniswapClient) LiquidityPosition(ctx context.Context, owner common.Address, tokenA Token, tokenB Token) (*LiquidityPosition, error) {
+	return c.client.liquidityPosition(ctx, c.client.network.Contracts.UniswapFactory, owner, tokenA, tokenB)
+}
+
+// AddLiquidityActions creates an action depositing `amountA` of `tokenA` and `amountB` of `tokenB` in
+// their pair. The pair takes them at the ratio of its reserves and the rest is returned. The minimum
+// amounts are the ones the current reserves take, `WithSlippage` tolerates less.
+func (c *SushiswapClient) AddLiquidityActions(ctx context.Context, amountA Size, tokenA Token, amountB Size, tokenB Token, options ...SwapOption) *Actions {
+	return c.client.addLiquidityActions(ctx,
+		"Sushiswap", c.client.network.Handlers.Sushiswap, c.client.network.Contracts.SushiswapFactory, amountA, tokenA, amountB, tokenB, options)
+}
+
+// RemoveLiquidityActions creates an action redeeming `liquidity` LP tokens of the pair of `tokenA` and
+// `tokenB`. The minimum amounts are the share of the current reserves, `WithSlippage` tolerates less.
+func (c *SushiswapClient) RemoveLiquidityActions(ctx context.Context, liquidity Size, tokenA Token, tokenB Token, options ...SwapOption) *Actions {
+	return c.client.removeLiquidityActions(ctx,
+		"Sushiswap", c.client.network.Handlers.Sushiswap, c.client.network.Contracts.SushiswapFactory, liquidity, tokenA, tokenB, options)
+}
+
+// LiquidityPosition returns the position of `owner` in the pair of `tokenA` and `tokenB` at the latest block.
+func (c *SushiswapClient) LiquidityPosition(ctx context.Context, owner common.Address, tokenA Token, tokenB Token) (*LiquidityPosition, error) {
+	return c.client.liquidityPosition(ctx, c.client.network.Contracts.SushiswapFactory, owner, tokenA, tokenB)
+}
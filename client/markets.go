@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/cToken"
+)
+
+// CompoundMarket is a market of the Comptroller and its underlying token.
+type CompoundMarket struct {
+	CToken     common.Address
+	Underlying Token
+}
+
+// compoundMarketsCache keeps the Compound markets discovered on chain, by underlying token.
+type compoundMarketsCache struct {
+	mu      sync.RWMutex
+	markets map[common.Address]common.Address
+}
+
+func (m *compoundMarketsCache) get(token common.Address) (common.Address, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	market, ok := m.markets[token]
+	return market, ok
+}
+
+// add keeps the first market of a token, e.g. the deprecated cWBTC and cWBTC2 share WBTC.
+func (m *compoundMarketsCache) add(token common.Address, market common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.markets == nil {
+		m.markets = make(map[common.Address]common.Address)
+	}
+	if _, ok := m.markets[token]; !ok {
+		m.markets[token] = market
+	}
+}
+
+// Markets lists the markets of the Comptroller with their underlying tokens, read with the
+// underlying of the cTokens, and registers them in the client. The actions and calls of the client
+// then support the tokens of markets missing from the network config, whose markets come first.
+func (c *CompoundClient) Markets(ctx context.Context) ([]CompoundMarket, error) {
+	comptrollerContract, err := c.comptroller()
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := comptrollerContract.GetAllMarkets(c.client.callOpts(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("Error getting Compound markets: %w", err)
+	}
+	markets := make([]CompoundMarket, 0, len(addrs))
+	for _, addr := range addrs {
+		token, err := c.marketToken(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		c.client.compoundMarkets.add(token.Address, addr)
+		markets = append(markets, CompoundMarket{CToken: addr, Underlying: token})
+	}
+	return markets, nil
+}
+
+// marketToken returns the underlying token of a market: its token in the network config, or else
+// the underlying of the cToken, fetched into the token registry.
+func (c *CompoundClient) marketToken(ctx context.Context, market common.Address) (Token, error) {
+	for symbol, addr := range c.client.network.Contracts.CompoundMarkets {
+		if addr != market {
+			continue
+		}
+		if token, ok := c.client.tokens.BySymbol(symbol); ok {
+			return token, nil
+		}
+	}
+	cTokenContract, err := cToken.NewCToken(market, c.client.conn)
+	if err != nil {
+		return Token{}, err
+	}
+	opts := c.client.callOpts(ctx, nil)
+	underlying, err := cTokenContract.Underlying(opts)
+	if err != nil {
+		// cETH has no underlying.
+		if symbol, symbolErr := cTokenContract.Symbol(opts); symbolErr == nil && symbol == cETH.Symbol {
+			return ETH, nil
+		}
+		return Token{}, fmt.Errorf("Error getting underlying of market %s: %w", market.Hex(), err)
+	}
+	token, err := c.client.tokens.Fetch(ctx, c.client.conn, underlying)
+	if err == nil {
+		return token, nil
+	}
+	// Some tokens, e.g. MKR, have a bytes32 symbol the registry can't read.
+	decimals, decimalsErr := c.client.Decimals(ctx, Token{Address: underlying})
+	if decimalsErr != nil {
+		return Token{}, err
+	}
+	return Token{Address: underlying, Symbol: underlying.Hex(), Decimals: decimals, ChainID: c.client.tokens.ChainID()}, nil
+}
@@ -0,0 +1,515 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v2"
+)
+
+// GanacheChainID is the chain ID of a ganache node, e.g. a mainnet fork.
+const GanacheChainID uint64 = 1337
+
+// networkLookupTimeout bounds the chain ID lookup of `NewClient`.
+const networkLookupTimeout = 10 * time.Second
+
+var (
+	// ErrUnknownNetwork is returned when no network is known for a chain ID.
+	ErrUnknownNetwork = errors.New("unknown network")
+	// ErrBadNetworkConfig is returned when a network config is incomplete or inconsistent.
+	ErrBadNetworkConfig = errors.New("bad network config")
+)
+
+// NetworkConfig is the address book of one chain: the Furucombo proxy and handlers, the protocol
+// contracts and the tokens. It can be written in YAML or JSON, see `LoadNetwork`.
+// A config must not be modified once it is registered or given to a client.
+type NetworkConfig struct {
+	Name    string `json:"name" yaml:"name"`
+	ChainID uint64 `json:"chainId" yaml:"chainId"`
+	// Extends is the chain ID of a registered network whose values fill in the ones missing from
+	// this config, e.g. a redeployment of the proxy on a mainnet fork only lists what it changed.
+	Extends uint64 `json:"extends,omitempty" yaml:"extends,omitempty"`
+
+	// Proxy is the Furucombo proxy that executes the combos.
+	Proxy common.Address `json:"proxy" yaml:"proxy"`
+	// Registry is the Furucombo registry the handlers are registered in, zero if unknown.
+	Registry  common.Address    `json:"registry" yaml:"registry"`
+	Handlers  HandlerAddresses  `json:"handlers" yaml:"handlers"`
+	Contracts ProtocolContracts `json:"contracts" yaml:"contracts"`
+	// Tokens are the tokens of the chain, a token without chain ID belongs to this chain.
+	Tokens []Token `json:"tokens" yaml:"tokens"`
+
+	resolveOnce     sync.Once
+	resolveErr      error
+	weth            Token
+	compoundMarkets map[common.Address]common.Address
+	makerJoins      map[common.Address]common.Address
+	makerIlks       map[common.Address][32]byte
+}
+
+// HandlerAddresses are the addresses of the Furucombo handlers.
+type HandlerAddresses struct {
+	CEther           common.Address `json:"cEther" yaml:"cEther"`
+	CToken           common.Address `json:"cToken" yaml:"cToken"`
+	ERC20TokenIn     common.Address `json:"erc20TokenIn" yaml:"erc20TokenIn"`
+	Maker            common.Address `json:"maker" yaml:"maker"`
+	Uniswap          common.Address `json:"uniswap" yaml:"uniswap"`
+	Sushiswap        common.Address `json:"sushiswap" yaml:"sushiswap"`
+	Curve            common.Address `json:"curve" yaml:"curve"`
+	Yearn            common.Address `json:"yearn" yaml:"yearn"`
+	Aave             common.Address `json:"aave" yaml:"aave"`
+	OneInch          common.Address `json:"oneInch" yaml:"oneInch"`
+	Funds            common.Address `json:"funds" yaml:"funds"`
+	Kyber            common.Address `json:"kyber" yaml:"kyber"`
+	BalancerExchange common.Address `json:"balancerExchange" yaml:"balancerExchange"`
+	// Comptroller is the handler claiming COMP, not in the built-in configs.
+	Comptroller common.Address `json:"comptroller" yaml:"comptroller"`
+	// CompoundBorrow is the handler borrowing from the Compound markets, not in the built-in configs.
+	CompoundBorrow common.Address `json:"compoundBorrow" yaml:"compoundBorrow"`
+	// Swapper is the Uniswap flash swapper.
+	Swapper common.Address `json:"swapper" yaml:"swapper"`
+}
+
+// ProtocolContracts are the addresses of the protocol contracts called directly or referenced
+// by the actions.
+type ProtocolContracts struct {
+	UniswapRouter       common.Address `json:"uniswapRouter" yaml:"uniswapRouter"`
+	SushiswapRouter     common.Address `json:"sushiswapRouter" yaml:"sushiswapRouter"`
+	UniswapFactory      common.Address `json:"uniswapFactory" yaml:"uniswapFactory"`
+	SushiswapFactory    common.Address `json:"sushiswapFactory" yaml:"sushiswapFactory"`
+	KyberNetworkProxy   common.Address `json:"kyberNetworkProxy" yaml:"kyberNetworkProxy"`
+	BalancerExchange    common.Address `json:"balancerExchange" yaml:"balancerExchange"`
+	YearnRegistry       common.Address `json:"yearnRegistry" yaml:"yearnRegistry"`
+	YearnETHVault       common.Address `json:"yearnETHVault" yaml:"yearnETHVault"`
+	AaveLendingPool     common.Address `json:"aaveLendingPool" yaml:"aaveLendingPool"`
+	AaveLendingPoolCore common.Address `json:"aaveLendingPoolCore" yaml:"aaveLendingPoolCore"`
+	CompoundComptroller common.Address `json:"compoundComptroller" yaml:"compoundComptroller"`
+	// CompoundMarkets maps the symbol of a token to its Compound cToken.
+	CompoundMarkets map[string]common.Address `json:"compoundMarkets" yaml:"compoundMarkets"`
+	// MakerJoins maps the symbol of a token to its Maker join, the adapter to deposit and withdraw
+	// unlocked collateral, DAI included.
+	MakerJoins map[string]common.Address `json:"makerJoins" yaml:"makerJoins"`
+	// MakerIlks maps the symbol of a token to the name of its Maker collateral type, e.g. ETH-A,
+	// see https://etherscan.io/address/0x8b4ce5DCbb01e0e1f0521cd8dCfb31B308E52c24
+	MakerIlks map[string]string `json:"makerIlks" yaml:"makerIlks"`
+	// CurvePools maps a name, e.g. 3pool, to the Curve pool and its LP token.
+	CurvePools map[string]CurvePool `json:"curvePools" yaml:"curvePools"`
+}
+
+// CurvePool is a Curve pool and its LP token.
+type CurvePool struct {
+	Pool    common.Address `json:"pool" yaml:"pool"`
+	LPToken common.Address `json:"lpToken" yaml:"lpToken"`
+}
+
+// Mainnet returns the config of the Ethereum mainnet.
+func Mainnet() *NetworkConfig {
+	addr := common.HexToAddress
+	return &NetworkConfig{
+		Name:    "mainnet",
+		ChainID: MainnetChainID,
+		Proxy:   addr(ProxyAddr),
+		Handlers: HandlerAddresses{
+			CEther:           addr("0x9A1049f7f87Dbb0468C745d9B3952e23d5d6CE5e"),
+			CToken:           addr("0x8973D623d883c5641Dd3906625Aac31cdC8790c5"),
+			ERC20TokenIn:     addr("0x914490a362f4507058403a99e28bdf685c5c767f"),
+			Maker:            addr("0x294fbca49c8a855e04d7d82b28256b086d39afea"),
+			Uniswap:          addr("0x58a21cfcee675d65d577b251668f7dc46ea9c3a0"),
+			Curve:            addr("0xa36dfb057010c419c5917f3d68b4520db3671cdb"),
+			Yearn:            addr("0xC50C8F34c9955217a6b3e385a069184DCE17fD2A"),
+			Aave:             addr("0xf579b009748a62b1978639d6b54259f8dc915229"),
+			OneInch:          addr("0x783f5c56e3c8b23d90e4a271d7acbe914bfcd319"),
+			Funds:            addr("0xf9b03e9ea64b2311b0221b2854edd6df97669c09"),
+			Kyber:            addr("0xe2a3431508cd8e72d53a0e4b57c24af2899322a0"),
+			BalancerExchange: addr("0x892dD6ebd2e3E1c0D6592309bA82a0095830D6d6"),
+			// TODO: The following is not on mainnet yet
+			Sushiswap: addr("0xB6F469a8930dd5111c0EA76571c7E86298A171f7"),
+			Swapper:   addr("0x017F3f2EB0c55DDF49B95ad38Cd2737ACf64AB4d"),
+		},
+		Contracts: ProtocolContracts{
+			// UniswapV2Router, see here: https://uniswap.org/docs/v2/smart-contracts/router02/#address
+			UniswapRouter:     addr("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"),
+			SushiswapRouter:   addr("0xd9e1cE17f2641f24aE83637ab66a2cca9C378B9F"),
+			UniswapFactory:    addr("0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f"),
+			SushiswapFactory:  addr("0xC0AEe478e3658e2610c5F7A4A2E1777cE9e4f2Ac"),
+			KyberNetworkProxy: addr("0x818E6FECD516Ecc3849DAf6845e3EC868087B755"),
+			// Balancer ExchangeProxy, the contract the Balancer handler swaps through.
+			BalancerExchange:    addr("0x3E66B66Fd1d0b02fDa6C811Da9E0547970DB2f21"),
+			YearnRegistry:       addr("0x3eE41C098f9666ed2eA246f4D2558010e59d63A0"),
+			YearnETHVault:       yWETH.Address,
+			AaveLendingPool:     addr("0x398eC7346DcD622eDc5ae82352F02bE94C62d119"),
+			AaveLendingPoolCore: addr("0x3dfd23A6c5E8BbcFc9581d2E864a68feb6a076d3"),
+			// Unitroller, the proxy of the Comptroller.
+			CompoundComptroller: addr("0x3d9819210A31b4961b30EF54bE2aeD79B9c9Cd3B"),
+			CompoundMarkets: map[string]common.Address{
+				"ETH":  cETH.Address,
+				"BAT":  addr("0x6C8c6b02E7b2BE14d4fA6022Dfd6d75921D90E4E"),
+				"COMP": addr("0x70e36f6BF80a52b3B46b3aF8e106CC0ed743E8e4"),
+				"DAI":  cDAI.Address,
+				"REP":  addr("0x158079Ee67Fce2f58472A96584A73C7Ab9AC95c1"),
+				"SAI":  addr("0xF5DCe57282A584D2746FaF1593d3121Fcac444dC"),
+				"UNI":  addr("0x35A18000230DA775CAc24873d00Ff85BccdeD550"),
+				"USDC": cUSDC.Address,
+				"USDT": addr("0xf650C3d88D12dB855b8bf7D11Be6C55A4e07dCC9"),
+				"WBTC": addr("0xC11b1268C1A384e55C48c2391d8d480264A3A7F4"),
+				"ZRX":  addr("0xB3319f5D18Bc0D84dD1b4825Dcde5d5f7266d407"),
+			},
+			MakerJoins: map[string]common.Address{
+				"DAI":  addr("0x9759A6Ac90977b93B58547b4A71c78317f391A28"),
+				"ETH":  addr("0x2F0b23f53734252Bda2277357e97e1517d6B042A"),
+				"USDC": addr("0x2600004fd1585f7270756DDc88aD9cfA10dD0428"),
+				"YFI":  addr("0x3ff33d9162aD47660083D7DC4bC02Fb231c81677"),
+				"USDT": addr("0x0Ac6A1D74E84C2dF9063bDDc31699FF2a2BB22A2"),
+				"UNI":  addr("0x2502F65D77cA13f183850b5f9272270454094A08"),
+				"AAVE": addr("0x24e459F61cEAa7b1cE70Dbaea938940A7c5aD46e"),
+			},
+			MakerIlks: map[string]string{
+				"ETH":  "ETH-A",
+				"YFI":  "YFI-A",
+				"USDC": "USDC-B",
+				"USDT": "USDT-A",
+				"UNI":  "UNIV2DAIETH-A",
+				"AAVE": "AAVE-A",
+			},
+			CurvePools: map[string]CurvePool{
+				"compound": {addr("0xA2B47E3D5c44877cca798226B7B8118F9BFb7A56"), addr("0x845838DF265Dcd2c412A1Dc9e959c7d08537f8a2")},
+				"usdt":     {addr("0x52EA46506B9CC5Ef470C5bf89f17Dc28bB35D85C"), addr("0x9fC689CCaDa600B6DF723D9E47D84d76664a1F23")},
+				"y":        {addr("0x45F783CCE6B7FF23B2ab2D70e416cdb7D6055f51"), addr("0xdF5e0e81Dff6FAF3A7e52BA697820c5e32D806A8")},
+				"busd":     {addr("0x79a8C46DeA5aDa233ABaFFD40F3A0A2B1e5A4F27"), addr("0x3B3Ac5386837Dc563660FB6a0937DFAa5924333B")},
+				"susd":     {addr("0xA5407eAE9Ba41422680e2e00537571bcC53efBfD"), addr("0xC25a3A3b969415c80451098fa907EC722572917F")},
+				"ren":      {addr("0x93054188d876f558f4a66B2EF1d97d16eDf0895B"), addr("0x49849C98ae39Fff122806C06791Fa73784FB3675")},
+				"sbtc":     {addr("0x7fC77b5c7614E1533320Ea6DDc2Eb61fa00A9714"), addr("0x075b1bb99792c9E1041bA13afEf80C91a1e70fB3")},
+				"hbtc":     {addr("0x4ca9b3063ec5866a4b82e437059d2c43d1be596f"), addr("0xb19059ebb43466C323583928285a49f558E572Fd")},
+				"3pool":    {addr("0xbebc44782c7db0a1a60cb6fe97d0b483032ff1c7"), addr("0x6c3F90f043a72FA612cbac8115EE7e52BDe6E490")},
+				"gusd":     {addr("0x4f062658eaaf2c1ccf8c8e36d6824cdf41167956"), addr("0xD2967f45c4f384DEEa880F807Be904762a3DeA07")},
+				"husd":     {addr("0x3eF6A01A0f81D6046290f3e2A8c5b843e738E604"), addr("0x5B5CFE992AdAC0C9D48E05854B2d91C73a003858")},
+				"usdk":     {addr("0x3e01dd8a5e1fb3481f0f589056b428fc308af0fb"), addr("0x97E2768e8E73511cA874545DC5Ff8067eB19B787")},
+				"usdn":     {addr("0x0f9cb53Ebe405d49A0bbdBD291A65Ff571bC83e1"), addr("0x4f3E8F405CF5aFC05D68142F3783bDfE13811522")},
+			},
+		},
+		Tokens: mainnetTokens,
+	}
+}
+
+// MainnetFork returns the config of a ganache fork of the mainnet, which has the mainnet contracts
+// under the ganache chain ID.
+func MainnetFork() *NetworkConfig {
+	fork := Mainnet()
+	fork.Name = "ganache"
+	fork.ChainID = GanacheChainID
+	fork.Tokens = make([]Token, len(mainnetTokens))
+	for i, token := range mainnetTokens {
+		token.ChainID = GanacheChainID
+		fork.Tokens[i] = token
+	}
+	return fork
+}
+
+var (
+	networksMu sync.RWMutex
+	networks   = map[uint64]*NetworkConfig{
+		MainnetChainID: Mainnet(),
+		GanacheChainID: MainnetFork(),
+	}
+)
+
+// RegisterNetwork makes a network known by its chain ID, it replaces the network of the same chain.
+// `NewClient` picks the network of the chain it is connected to among the registered ones.
+func RegisterNetwork(network *NetworkConfig) error {
+	if err := network.resolve(); err != nil {
+		return err
+	}
+	networksMu.Lock()
+	defer networksMu.Unlock()
+	networks[network.ChainID] = network
+	return nil
+}
+
+// LookupNetwork returns the registered network of a chain.
+func LookupNetwork(chainID uint64) (*NetworkConfig, error) {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	network, ok := networks[chainID]
+	if !ok {
+		return nil, fmt.Errorf("%w: chain %d", ErrUnknownNetwork, chainID)
+	}
+	return network, nil
+}
+
+// LoadNetwork reads a network config in YAML or JSON and registers it.
+func LoadNetwork(reader io.Reader) (*NetworkConfig, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	// YAML is a superset of JSON, so both are read by the YAML decoder.
+	network := new(NetworkConfig)
+	if err := yaml.UnmarshalStrict(data, network); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadNetworkConfig, err)
+	}
+	if err := RegisterNetwork(network); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// LoadNetworkFile reads a network config file in YAML or JSON and registers it.
+func LoadNetworkFile(path string) (*NetworkConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadNetwork(f)
+}
+
+// WithNetwork sets the network of the client instead of the one registered for its chain. A config
+// with `Extends` is filled from the network it extends, as by `RegisterNetwork`.
+func WithNetwork(network *NetworkConfig) Option {
+	return func(c *DefiClient) {
+		c.network = network
+	}
+}
+
+// Network returns the network the client targets.
+func (c *DefiClient) Network() *NetworkConfig {
+	return c.network
+}
+
+// networkOf returns the registered network of the chain `conn` is connected to.
+func networkOf(c *DefiClient) (*NetworkConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), networkLookupTimeout)
+	defer cancel()
+	chainID, err := c.conn.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting chain ID: %w", err)
+	}
+	if !chainID.IsUint64() {
+		return nil, fmt.Errorf("%w: chain %v", ErrUnknownNetwork, chainID)
+	}
+	return LookupNetwork(chainID.Uint64())
+}
+
+// inherit fills the values missing from the config with the ones of `base`.
+func (n *NetworkConfig) inherit(base *NetworkConfig) {
+	if n.Name == "" {
+		n.Name = base.Name
+	}
+	if n.ChainID == 0 {
+		n.ChainID = base.ChainID
+	}
+	orAddr(&n.Proxy, base.Proxy)
+	orAddr(&n.Registry, base.Registry)
+
+	h, bh := &n.Handlers, base.Handlers
+	orAddr(&h.CEther, bh.CEther)
+	orAddr(&h.CToken, bh.CToken)
+	orAddr(&h.ERC20TokenIn, bh.ERC20TokenIn)
+	orAddr(&h.Maker, bh.Maker)
+	orAddr(&h.Uniswap, bh.Uniswap)
+	orAddr(&h.Sushiswap, bh.Sushiswap)
+	orAddr(&h.Curve, bh.Curve)
+	orAddr(&h.Yearn, bh.Yearn)
+	orAddr(&h.Aave, bh.Aave)
+	orAddr(&h.OneInch, bh.OneInch)
+	orAddr(&h.Funds, bh.Funds)
+	orAddr(&h.Kyber, bh.Kyber)
+	orAddr(&h.BalancerExchange, bh.BalancerExchange)
+	orAddr(&h.Comptroller, bh.Comptroller)
+	orAddr(&h.CompoundBorrow, bh.CompoundBorrow)
+	orAddr(&h.Swapper, bh.Swapper)
+
+	p, bp := &n.Contracts, base.Contracts
+	orAddr(&p.UniswapRouter, bp.UniswapRouter)
+	orAddr(&p.SushiswapRouter, bp.SushiswapRouter)
+	orAddr(&p.UniswapFactory, bp.UniswapFactory)
+	orAddr(&p.SushiswapFactory, bp.SushiswapFactory)
+	orAddr(&p.KyberNetworkProxy, bp.KyberNetworkProxy)
+	orAddr(&p.BalancerExchange, bp.BalancerExchange)
+	orAddr(&p.YearnRegistry, bp.YearnRegistry)
+	orAddr(&p.YearnETHVault, bp.YearnETHVault)
+	orAddr(&p.AaveLendingPool, bp.AaveLendingPool)
+	orAddr(&p.AaveLendingPoolCore, bp.AaveLendingPoolCore)
+	orAddr(&p.CompoundComptroller, bp.CompoundComptroller)
+	p.CompoundMarkets = mergeAddrs(p.CompoundMarkets, bp.CompoundMarkets)
+	p.MakerJoins = mergeAddrs(p.MakerJoins, bp.MakerJoins)
+	if p.MakerIlks == nil {
+		p.MakerIlks = make(map[string]string)
+	}
+	for symbol, ilk := range bp.MakerIlks {
+		if _, ok := p.MakerIlks[symbol]; !ok {
+			p.MakerIlks[symbol] = ilk
+		}
+	}
+	if p.CurvePools == nil {
+		p.CurvePools = make(map[string]CurvePool)
+	}
+	for name, pool := range bp.CurvePools {
+		if _, ok := p.CurvePools[name]; !ok {
+			p.CurvePools[name] = pool
+		}
+	}
+
+	symbols := make(map[string]bool)
+	for _, token := range n.Tokens {
+		symbols[strings.ToUpper(token.Symbol)] = true
+	}
+	for _, token := range base.Tokens {
+		if !symbols[strings.ToUpper(token.Symbol)] {
+			token.ChainID = n.ChainID
+			n.Tokens = append(n.Tokens, token)
+		}
+	}
+}
+
+func orAddr(addr *common.Address, fallback common.Address) {
+	if *addr == (common.Address{}) {
+		*addr = fallback
+	}
+}
+
+func mergeAddrs(m map[string]common.Address, base map[string]common.Address) map[string]common.Address {
+	if m == nil {
+		m = make(map[string]common.Address)
+	}
+	for k, v := range base {
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// resolve fills the config from the network it extends, checks it and keys the per token tables
+// by token address.
+func (n *NetworkConfig) resolve() error {
+	n.resolveOnce.Do(func() {
+		n.resolveErr = n.doResolve()
+	})
+	return n.resolveErr
+}
+
+func (n *NetworkConfig) doResolve() error {
+	if n.Extends != 0 {
+		base, err := LookupNetwork(n.Extends)
+		if err != nil {
+			return err
+		}
+		n.inherit(base)
+	}
+	if n.ChainID == 0 {
+		return fmt.Errorf("%w: missing chain ID", ErrBadNetworkConfig)
+	}
+	if n.Proxy == (common.Address{}) {
+		return fmt.Errorf("%w: missing proxy of chain %d", ErrBadNetworkConfig, n.ChainID)
+	}
+	tokens := NewTokenRegistry(n.ChainID, n.Tokens...)
+	weth, ok := tokens.BySymbol("WETH")
+	if !ok {
+		return fmt.Errorf("%w: missing WETH of chain %d", ErrBadNetworkConfig, n.ChainID)
+	}
+	n.weth = weth
+	tokenAddr := func(table string, symbol string) (common.Address, error) {
+		token, ok := tokens.BySymbol(symbol)
+		if !ok {
+			return common.Address{}, fmt.Errorf("%w: unknown token %s in %s", ErrBadNetworkConfig, symbol, table)
+		}
+		return token.Address, nil
+	}
+
+	n.compoundMarkets = make(map[common.Address]common.Address)
+	for symbol, market := range n.Contracts.CompoundMarkets {
+		addr, err := tokenAddr("compoundMarkets", symbol)
+		if err != nil {
+			return err
+		}
+		n.compoundMarkets[addr] = market
+	}
+	n.makerJoins = make(map[common.Address]common.Address)
+	for symbol, join := range n.Contracts.MakerJoins {
+		addr, err := tokenAddr("makerJoins", symbol)
+		if err != nil {
+			return err
+		}
+		n.makerJoins[addr] = join
+	}
+	n.makerIlks = make(map[common.Address][32]byte)
+	for symbol, ilk := range n.Contracts.MakerIlks {
+		addr, err := tokenAddr("makerIlks", symbol)
+		if err != nil {
+			return err
+		}
+		if len(ilk) > 32 {
+			return fmt.Errorf("%w: ilk %s is longer than 32 bytes", ErrBadNetworkConfig, ilk)
+		}
+		var b [32]byte
+		copy(b[:], ilk)
+		n.makerIlks[addr] = b
+	}
+	return nil
+}
+
+// WETH returns wrapped ether on the network, the token of its config with the symbol WETH.
+func (n *NetworkConfig) WETH() (Token, bool) {
+	if n.resolve() != nil {
+		return Token{}, false
+	}
+	return n.weth, true
+}
+
+// CompoundMarket returns the Compound cToken of a token.
+func (n *NetworkConfig) CompoundMarket(token Token) (common.Address, bool) {
+	if n.resolve() != nil {
+		return common.Address{}, false
+	}
+	market, ok := n.compoundMarkets[token.Address]
+	return market, ok
+}
+
+// MakerCollateral returns the Maker join and collateral type of a token.
+func (n *NetworkConfig) MakerCollateral(token Token) (common.Address, [32]byte, bool) {
+	if n.resolve() != nil {
+		return common.Address{}, [32]byte{}, false
+	}
+	join, ok := n.makerJoins[token.Address]
+	if !ok {
+		return common.Address{}, [32]byte{}, false
+	}
+	ilk, ok := n.makerIlks[token.Address]
+	return join, ilk, ok
+}
+
+// handlerFromReason returns the handler named in the prefix of a revert reason.
+func (h *HandlerAddresses) handlerFromReason(reason string) (common.Address, bool) {
+	i := strings.Index(reason, ":")
+	if i < 0 {
+		return common.Address{}, false
+	}
+	// The names that the Furucombo handlers prefix their revert reasons with, e.g. "HUniswap: ...".
+	names := map[string]common.Address{
+		"HUniswap":          h.Uniswap,
+		"HSushiswap":        h.Sushiswap,
+		"HCurve":            h.Curve,
+		"HMaker":            h.Maker,
+		"HCToken":           h.CToken,
+		"HCEther":           h.CEther,
+		"HComptroller":      h.Comptroller,
+		"HAaveProtocol":     h.Aave,
+		"HYVault":           h.Yearn,
+		"HKyberNetwork":     h.Kyber,
+		"HBalancerExchange": h.BalancerExchange,
+		"HOneInchExchange":  h.OneInch,
+		"HFunds":            h.Funds,
+		"HERC20TokenIn":     h.ERC20TokenIn,
+		"Swapper":           h.Swapper,
+	}
+	addr, ok := names[strings.TrimSpace(reason[:i])]
+	return addr, ok
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// NonceManager hands out the nonces of an account locally, so that several transactions can be
+// in flight from the account without waiting for the node to see the previous ones.
+type NonceManager struct {
+	mu      sync.Mutex
+	conn    *ethclient.Client
+	account common.Address
+	next    uint64
+	synced  bool
+}
+
+// NewNonceManager creates a nonce manager for `account`, it starts from the pending nonce of the node.
+func NewNonceManager(conn *ethclient.Client, account common.Address) *NonceManager {
+	return &NonceManager{conn: conn, account: account}
+}
+
+// Next returns the nonce of the next transaction of the account.
+func (m *NonceManager) Next(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.synced {
+		nonce, err := m.conn.PendingNonceAt(ctx, m.account)
+		if err != nil {
+			return 0, err
+		}
+		m.next = nonce
+		m.synced = true
+	}
+	nonce := m.next
+	m.next++
+	return nonce, nil
+}
+
+// Release gives back a nonce whose transaction wasn't sent. It is only reused if no later nonce
+// was handed out in the meantime, otherwise the manager resyncs with the node on the next call.
+func (m *NonceManager) Release(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.synced && nonce+1 == m.next {
+		m.next = nonce
+		return
+	}
+	m.synced = false
+}
+
+// Reset makes the manager resync with the pending nonce of the node on the next call, e.g. after
+// transactions were sent from the account by another program.
+func (m *NonceManager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.synced = false
+}
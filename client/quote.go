@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/curve"
+	"github.com/rafaelescrich/go-defi-1/binding/kyber"
+	"github.com/rafaelescrich/go-defi-1/binding/yearn/yvault"
+)
+
+// ErrUnknownVenue is returned when a venue can't be quoted from a token pair alone.
+var ErrUnknownVenue = errors.New("unknown venue")
+
+// Venue is a protocol a swap can be quoted and executed on.
+type Venue string
+
+// The venues of the quoter.
+const (
+	VenueUniswap   Venue = "Uniswap"
+	VenueSushiswap Venue = "Sushiswap"
+	VenueKyber     Venue = "Kyberswap"
+	VenueBalancer  Venue = "Balancer"
+	VenueCurve     Venue = "Curve"
+	VenueYearn     Venue = "Yearn"
+)
+
+// PairVenues are the venues quoted from a token pair alone, see `Quoter.Quote`.
+var PairVenues = []Venue{VenueUniswap, VenueSushiswap, VenueKyber, VenueBalancer}
+
+// Quote is the output expected at the latest block for an input on a venue. The amounts are raw
+// amounts of the tokens, so the quotes of a pair compare across venues.
+type Quote struct {
+	Venue Venue
+	// Pool is the Curve pool of a Curve quote.
+	Pool   common.Address
+	Input  Amount
+	Output Amount
+}
+
+// Better tells if the quote gets more output than `other`, which may be nil.
+func (q *Quote) Better(other *Quote) bool {
+	return other == nil || q.Output.Int().Cmp(other.Output.Int()) > 0
+}
+
+func (q *Quote) String() string {
+	if q.Pool != (common.Address{}) {
+		return fmt.Sprintf("%s %s: %s for %s", q.Venue, q.Pool.Hex(), q.Input, q.Output)
+	}
+	return fmt.Sprintf("%s: %s for %s", q.Venue, q.Input, q.Output)
+}
+
+// Quoter quotes swaps and deposits with the view functions of the venues, without sending anything.
+type Quoter struct {
+	client *DefiClient
+}
+
+// Quoter returns a quoter.
+func (c *DefiClient) Quoter() *Quoter {
+	return &Quoter{client: c}
+}
+
+// Quote quotes a swap of `amount` of `from` to `to` on one of the `PairVenues`.
+func (q *Quoter) Quote(ctx context.Context, venue Venue, amount Size, from Token, to Token) (*Quote, error) {
+	switch venue {
+	case VenueUniswap:
+		return q.Uniswap(ctx, amount, from, to)
+	case VenueSushiswap:
+		return q.Sushiswap(ctx, amount, from, to)
+	case VenueKyber:
+		return q.Kyber(ctx, amount, from, to)
+	case VenueBalancer:
+		return q.Balancer(ctx, amount, from, to)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownVenue, venue)
+	}
+}
+
+// Uniswap quotes a swap through WETH with the getAmountsOut of the Uniswap router.
+func (q *Quoter) Uniswap(ctx context.Context, amount Size, from Token, to Token) (*Quote, error) {
+	return q.router(ctx, VenueUniswap, q.client.network.Contracts.UniswapRouter, amount, from, to)
+}
+
+// Sushiswap quotes a swap through WETH with the getAmountsOut of the Sushiswap router.
+func (q *Quoter) Sushiswap(ctx context.Context, amount Size, from Token, to Token) (*Quote, error) {
+	return q.router(ctx, VenueSushiswap, q.client.network.Contracts.SushiswapRouter, amount, from, to)
+}
+
+func (q *Quoter) router(ctx context.Context, venue Venue, routerAddr common.Address, amount Size, from Token, to Token) (*Quote, error) {
+	path := q.client.swapPath(to, from)
+	if err := q.client.checkPath(path); err != nil {
+		return nil, err
+	}
+	size, err := q.client.quoteSize(amount, from)
+	if err != nil {
+		return nil, err
+	}
+	output, err := q.client.routerQuote(routerAddr, size, q.client.pathAddrs(path))(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error quoting %s: %w", venue, err)
+	}
+	return newQuote(venue, size, from, output, to), nil
+}
+
+// Kyber quotes a swap with the expected rate of the Kyber network proxy.
+func (q *Quoter) Kyber(ctx context.Context, amount Size, from Token, to Token) (*Quote, error) {
+	size, err := q.client.quoteSize(amount, from)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := kyber.NewKyber(q.client.network.Contracts.KyberNetworkProxy, q.client.conn)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := proxy.GetExpectedRate(q.client.callOpts(ctx, nil), kyberAddr(from), kyberAddr(to), size)
+	if err != nil {
+		return nil, fmt.Errorf("Error quoting %s: %w", VenueKyber, err)
+	}
+	fromDecimals, err := q.client.Decimals(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	toDecimals, err := q.client.Decimals(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	// The rate is scaled by 1e18, in units of the tokens.
+	output := new(big.Int).Mul(size, rates.ExpectedRate)
+	output.Mul(output, pow10(int(toDecimals)))
+	output.Div(output, pow10(18+int(fromDecimals)))
+	return newQuote(VenueKyber, size, from, output, to), nil
+}
+
+// Balancer quotes a smart swap with the viewSplitExactIn of the Balancer exchange proxy.
+func (q *Quoter) Balancer(ctx context.Context, amount Size, from Token, to Token) (*Quote, error) {
+	size, err := q.client.quoteSize(amount, from)
+	if err != nil {
+		return nil, err
+	}
+	output, err := q.client.Balancer().balancerQuote(q.client.erc20Address(from), q.client.erc20Address(to), size)(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error quoting %s: %w", VenueBalancer, err)
+	}
+	return newQuote(VenueBalancer, size, from, output, to), nil
+}
+
+// Curve quotes an exchange of the coins `i` and `j` of the Curve pool at `pool` with get_dy,
+// `from` and `to` being the coins.
+func (q *Quoter) Curve(ctx context.Context, pool common.Address, i *big.Int, j *big.Int, amount Size, from Token, to Token) (*Quote, error) {
+	return q.curve(ctx, pool, i, j, amount, from, to, false)
+}
+
+// CurveUnderlying quotes an exchange of the underlying coins `i` and `j` of the Curve pool at `pool`
+// with get_dy_underlying, `from` and `to` being the underlying coins.
+func (q *Quoter) CurveUnderlying(ctx context.Context, pool common.Address, i *big.Int, j *big.Int, amount Size, from Token, to Token) (*Quote, error) {
+	return q.curve(ctx, pool, i, j, amount, from, to, true)
+}
+
+func (q *Quoter) curve(ctx context.Context, pool common.Address, i *big.Int, j *big.Int, amount Size, from Token, to Token, underlying bool) (*Quote, error) {
+	size, err := q.client.quoteSize(amount, from)
+	if err != nil {
+		return nil, err
+	}
+	curvePool, err := curve.NewCurve(pool, q.client.conn)
+	if err != nil {
+		return nil, err
+	}
+	var output *big.Int
+	if underlying {
+		output, err = curvePool.GetDyUnderlying(q.client.callOpts(ctx, nil), i, j, size)
+	} else {
+		output, err = curvePool.GetDy(q.client.callOpts(ctx, nil), i, j, size)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error quoting %s: %w", VenueCurve, err)
+	}
+	quote := newQuote(VenueCurve, size, from, output, to)
+	quote.Pool = pool
+	return quote, nil
+}
+
+// YearnDeposit quotes the vault shares a deposit of `amount` of `coin` in its Yearn vault gets.
+// The output token is the vault.
+func (q *Quoter) YearnDeposit(ctx context.Context, amount Size, coin Token) (*Quote, error) {
+	size, err := q.client.quoteSize(amount, coin)
+	if err != nil {
+		return nil, err
+	}
+	vault, price, err := q.yearnVault(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	shares := share(size, pow10(18), price)
+	return newQuote(VenueYearn, size, coin, shares, vault), nil
+}
+
+// YearnWithdraw quotes the amount of `coin` a withdrawal of `shares` of its Yearn vault gets,
+// before the withdrawal fee of the vault if any.
+func (q *Quoter) YearnWithdraw(ctx context.Context, shares Size, coin Token) (*Quote, error) {
+	vault, price, err := q.yearnVault(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	size, err := q.client.quoteSize(shares, vault)
+	if err != nil {
+		return nil, err
+	}
+	output := share(size, price, pow10(18))
+	return newQuote(VenueYearn, size, vault, output, coin), nil
+}
+
+// yearnVault returns the vault of `coin`, as a token, and its price per full share scaled by 1e18.
+func (q *Quoter) yearnVault(ctx context.Context, coin Token) (Token, *big.Int, error) {
+	vaultAddr := q.client.network.Contracts.YearnETHVault
+	if !coin.IsETH() {
+		var err error
+		vaultAddr, err = q.client.Yearn().vaultOf(ctx, coin)
+		if err != nil {
+			return Token{}, nil, err
+		}
+	}
+	// yWETH shares the view functions of the other vaults.
+	vault, err := yvault.NewYvault(vaultAddr, q.client.conn)
+	if err != nil {
+		return Token{}, nil, err
+	}
+	price, err := vault.GetPricePerFullShare(q.client.callOpts(ctx, nil))
+	if err != nil {
+		return Token{}, nil, fmt.Errorf("Error quoting %s: %w", VenueYearn, err)
+	}
+	if price.Sign() == 0 {
+		return Token{}, nil, fmt.Errorf("Error quoting %s: vault %s has no price per share", VenueYearn, vaultAddr.Hex())
+	}
+	vaultToken := Token{Address: vaultAddr, Symbol: "y" + coin.Symbol, Decimals: coin.Decimals, ChainID: coin.ChainID}
+	return vaultToken, price, nil
+}
+
+// quoteSize returns the raw size of a quote of `amount` of `token`.
+func (c *DefiClient) quoteSize(amount Size, token Token) (*big.Int, error) {
+	size, err := c.sizeOf(amount, c.erc20Address(token))
+	if err != nil {
+		return nil, err
+	}
+	if size == nil {
+		return nil, ErrNilAmount
+	}
+	return size, nil
+}
+
+func newQuote(venue Venue, input *big.Int, from Token, output *big.Int, to Token) *Quote {
+	return &Quote{Venue: venue, Input: RawAmount(input, from), Output: RawAmount(output, to)}
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/cToken"
+)
+
+// CompoundBlocksPerDay is the number of blocks a day the APYs compound over, at 13.15 seconds a
+// block as in the Compound docs, see https://compound.finance/docs#protocol-math
+const CompoundBlocksPerDay = 6570
+
+// ErrBadBlockRange is returned when a rate history is asked for an empty range or a zero step.
+var ErrBadBlockRange = errors.New("bad block range")
+
+// MarketRates are the interest rates and the use of a Compound market at a block.
+type MarketRates struct {
+	Market common.Address
+	Block  *big.Int
+	// SupplyRatePerBlock and BorrowRatePerBlock are scaled by 1e18.
+	SupplyRatePerBlock *big.Int
+	BorrowRatePerBlock *big.Int
+	// SupplyAPY and BorrowAPY are the rates compounded daily over a year, e.g. 0.05 for 5%.
+	SupplyAPY float64
+	BorrowAPY float64
+	// ExchangeRate is the underlying tokens per cToken as of the last accrual of the market,
+	// scaled by 1e(10 + decimals of the underlying).
+	ExchangeRate *big.Int
+	Cash         Amount
+	Borrows      Amount
+	Reserves     Amount
+	// Utilization is the part of the supply that is borrowed: borrows / (cash + borrows - reserves).
+	Utilization float64
+}
+
+func (r *MarketRates) String() string {
+	return fmt.Sprintf("%s at block %v: supply %.2f%%, borrow %.2f%%, utilization %.2f%%",
+		r.Market.Hex(), r.Block, r.SupplyAPY*100, r.BorrowAPY*100, r.Utilization*100)
+}
+
+// compoundAPY compounds a rate per block scaled by 1e18 daily over a year.
+func compoundAPY(ratePerBlock *big.Int) float64 {
+	rate, _ := new(big.Rat).SetFrac(ratePerBlock, pow10(18)).Float64()
+	return math.Pow(rate*CompoundBlocksPerDay+1, 365) - 1
+}
+
+// utilization returns borrows / (cash + borrows - reserves), 0 for an empty market.
+func utilization(cash *big.Int, borrows *big.Int, reserves *big.Int) float64 {
+	supply := new(big.Int).Add(cash, borrows)
+	supply.Sub(supply, reserves)
+	if supply.Sign() <= 0 {
+		return 0
+	}
+	used, _ := new(big.Rat).SetFrac(borrows, supply).Float64()
+	return used
+}
+
+// Rates reads the rates of the market of `coin` at `blockNum`, nil means the latest block.
+// Past blocks need an archive node.
+func (c *CompoundClient) Rates(ctx context.Context, coin Token, blockNum *big.Int) (*MarketRates, error) {
+	cTokenAddr, err := c.getPoolAddrFromCoin(coin)
+	if err != nil {
+		return nil, err
+	}
+	if blockNum == nil {
+		header, err := c.client.conn.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting latest block: %w", err)
+		}
+		blockNum = header.Number
+	}
+	cTokenContract, err := cToken.NewCToken(cTokenAddr, c.client.conn)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting cToken contract: %v", err)
+	}
+	opts := c.client.callOpts(ctx, blockNum)
+
+	supplyRate, err := cTokenContract.SupplyRatePerBlock(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting supply rate of %s: %w", coin, err)
+	}
+	borrowRate, err := cTokenContract.BorrowRatePerBlock(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting borrow rate of %s: %w", coin, err)
+	}
+	exchangeRate, err := cTokenContract.ExchangeRateStored(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting exchange rate of %s: %w", coin, err)
+	}
+	cash, err := cTokenContract.GetCash(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting cash of %s: %w", coin, err)
+	}
+	borrows, err := cTokenContract.TotalBorrows(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting borrows of %s: %w", coin, err)
+	}
+	reserves, err := cTokenContract.TotalReserves(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting reserves of %s: %w", coin, err)
+	}
+
+	return &MarketRates{
+		Market:             cTokenAddr,
+		Block:              blockNum,
+		SupplyRatePerBlock: supplyRate,
+		BorrowRatePerBlock: borrowRate,
+		SupplyAPY:          compoundAPY(supplyRate),
+		BorrowAPY:          compoundAPY(borrowRate),
+		ExchangeRate:       exchangeRate,
+		Cash:               RawAmount(cash, coin),
+		Borrows:            RawAmount(borrows, coin),
+		Reserves:           RawAmount(reserves, coin),
+		Utilization:        utilization(cash, borrows, reserves),
+	}, nil
+}
+
+// RateHistory samples the rates of the market of `coin` every `step` blocks from `from` up to
+// `to`, oldest first. It needs an archive node.
+func (c *CompoundClient) RateHistory(ctx context.Context, coin Token, from uint64, to uint64, step uint64) ([]*MarketRates, error) {
+	if step == 0 || from > to {
+		return nil, fmt.Errorf("%w: %d to %d every %d blocks", ErrBadBlockRange, from, to, step)
+	}
+	history := make([]*MarketRates, 0, (to-from)/step+1)
+	for block := from; ; block += step {
+		rates, err := c.Rates(ctx, coin, new(big.Int).SetUint64(block))
+		if err != nil {
+			return nil, fmt.Errorf("Error getting rates at block %d: %w", block, err)
+		}
+		history = append(history, rates)
+		if to-block < step {
+			break
+		}
+	}
+	return history, nil
+}
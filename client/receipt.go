@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rafaelescrich/go-defi-1/binding/aave/lendingpool"
+	"github.com/rafaelescrich/go-defi-1/binding/compound/cToken"
+	"github.com/rafaelescrich/go-defi-1/binding/erc20"
+	"github.com/rafaelescrich/go-defi-1/binding/yearn/yvault"
+)
+
+// ExecutionResult is the outcome of a combo mined on chain.
+type ExecutionResult struct {
+	TxHash      TxHash
+	BlockNumber *big.Int
+	BlockHash   common.Hash
+	GasUsed     uint64
+	// EffectiveGasPrice is the price per gas actually paid, for a dynamic fee transaction it is
+	// the base fee of the block plus the tip, up to the fee cap.
+	EffectiveGasPrice *big.Int
+	// Transfers are the ERC-20 transfers emitted during the call, cTokens and yTokens included.
+	Transfers []Transfer
+	// Events are the other events emitted by the protocols and tokens, e.g. a Compound Mint or an
+	// Aave Deposit, in the order of the logs.
+	Events []Event
+	// TokenDeltas is the net amount of each token received by the sender, keyed by token address,
+	// negative if the sender sent more than it got back. Ether moves aren't logged, so WETH
+	// unwrapped and sent back as ether isn't accounted for.
+	TokenDeltas map[common.Address]*big.Int
+}
+
+// Transfer is a decoded ERC-20 Transfer event.
+type Transfer struct {
+	Token common.Address
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// Event is a decoded protocol event.
+type Event struct {
+	// Protocol is the protocol whose ABI decoded the event: ERC20, Compound, Yearn or Aave.
+	Protocol string
+	// Address is the address of the contract that emitted the event.
+	Address common.Address
+	Name    string
+	// Args are the event arguments by name, indexed ones included.
+	Args map[string]interface{}
+	Log  *types.Log
+}
+
+// eventDecoder decodes the events of one protocol ABI.
+type eventDecoder struct {
+	protocol string
+	abi      abi.ABI
+}
+
+var (
+	eventDecodersOnce sync.Once
+	eventDecoders     []eventDecoder
+	transferEventID   common.Hash
+)
+
+// loadEventDecoders parses the ABIs of the bindings whose events are decoded.
+func loadEventDecoders() {
+	abis := []struct {
+		protocol string
+		abiJSON  string
+	}{
+		{"ERC20", erc20.Erc20ABI},
+		{"Compound", cToken.CTokenABI},
+		{"Yearn", yvault.YvaultABI},
+		{"Aave", lendingpool.LendingpoolABI},
+	}
+	for _, a := range abis {
+		parsed, err := abi.JSON(strings.NewReader(a.abiJSON))
+		if err != nil {
+			continue
+		}
+		eventDecoders = append(eventDecoders, eventDecoder{protocol: a.protocol, abi: parsed})
+		if a.protocol == "ERC20" {
+			transferEventID = parsed.Events["Transfer"].ID
+		}
+	}
+}
+
+// decodeLogs splits the logs of a receipt into ERC-20 transfers and other known events.
+// Logs that none of the ABIs know are skipped.
+func decodeLogs(logs []*types.Log) ([]Transfer, []Event) {
+	eventDecodersOnce.Do(loadEventDecoders)
+
+	transfers := make([]Transfer, 0)
+	events := make([]Event, 0)
+	for _, log := range logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		if log.Topics[0] == transferEventID && len(log.Topics) == 3 {
+			filterer, err := erc20.NewErc20Filterer(log.Address, nil)
+			if err != nil {
+				continue
+			}
+			transfer, err := filterer.ParseTransfer(*log)
+			if err != nil {
+				continue
+			}
+			transfers = append(transfers, Transfer{Token: log.Address, From: transfer.From, To: transfer.To, Value: transfer.Value})
+			continue
+		}
+		if event, ok := decodeEvent(log); ok {
+			events = append(events, event)
+		}
+	}
+	return transfers, events
+}
+
+// decodeEvent decodes a log with the first ABI that knows its event.
+func decodeEvent(log *types.Log) (Event, bool) {
+	for _, decoder := range eventDecoders {
+		ev, err := decoder.abi.EventByID(log.Topics[0])
+		if err != nil {
+			continue
+		}
+		args := make(map[string]interface{})
+		if err := decoder.abi.UnpackIntoMap(args, ev.Name, log.Data); err != nil {
+			continue
+		}
+		indexed := make(abi.Arguments, 0)
+		for _, input := range ev.Inputs {
+			if input.Indexed {
+				indexed = append(indexed, input)
+			}
+		}
+		if err := abi.ParseTopicsIntoMap(args, indexed, log.Topics[1:]); err != nil {
+			continue
+		}
+		return Event{Protocol: decoder.protocol, Address: log.Address, Name: ev.Name, Args: args, Log: log}, true
+	}
+	return Event{}, false
+}
+
+// tokenDeltas sums the transfers to and from `owner` by token.
+func tokenDeltas(transfers []Transfer, owner common.Address) map[common.Address]*big.Int {
+	deltas := make(map[common.Address]*big.Int)
+	for _, transfer := range transfers {
+		if transfer.From != owner && transfer.To != owner {
+			continue
+		}
+		delta, ok := deltas[transfer.Token]
+		if !ok {
+			delta = big.NewInt(0)
+			deltas[transfer.Token] = delta
+		}
+		if transfer.To == owner {
+			delta.Add(delta, transfer.Value)
+		}
+		if transfer.From == owner {
+			delta.Sub(delta, transfer.Value)
+		}
+	}
+	return deltas
+}
+
+// executionResult builds the result of a mined transaction from its receipt.
+func (c *DefiClient) executionResult(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) (*ExecutionResult, error) {
+	price, err := c.effectiveGasPrice(ctx, tx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	transfers, events := decodeLogs(receipt.Logs)
+	return &ExecutionResult{
+		TxHash:            TxHash(tx.Hash().Hex()),
+		BlockNumber:       receipt.BlockNumber,
+		BlockHash:         receipt.BlockHash,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: price,
+		Transfers:         transfers,
+		Events:            events,
+		TokenDeltas:       tokenDeltas(transfers, c.opts.From),
+	}, nil
+}
+
+// effectiveGasPrice returns the price per gas paid by a mined transaction.
+func (c *DefiClient) effectiveGasPrice(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) (*big.Int, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return tx.GasPrice(), nil
+	}
+	header, err := c.conn.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := tx.EffectiveGasTip(header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	return tip.Add(tip, header.BaseFee), nil
+}
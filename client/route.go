@@ -0,0 +1,418 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/huniswap"
+	"github.com/rafaelescrich/go-defi-1/binding/uniswap/factory"
+	"github.com/rafaelescrich/go-defi-1/binding/uniswap/pair"
+)
+
+// DefaultMaxHops is the default maximum number of pairs a route found by `BestRoute` goes through.
+const DefaultMaxHops = 3
+
+var (
+	// ErrBadPath is returned when a swap path can't be swapped along, e.g. it has a single token.
+	ErrBadPath = errors.New("bad swap path")
+	// ErrNoRoute is returned when no pair connects the tokens of a swap.
+	ErrNoRoute = errors.New("no route")
+)
+
+// defaultIntermediates are the symbols of the tokens the routes go through by default.
+var defaultIntermediates = []string{"WETH", "USDC", "DAI", "USDT"}
+
+// Route is a swap path on Uniswap or Sushiswap and the output expected from the reserves of its pairs.
+type Route struct {
+	Path      []Token
+	AmountIn  *big.Int
+	AmountOut *big.Int
+
+	// weth is the WETH of the network the route was found on.
+	weth common.Address
+}
+
+// Addresses returns the path of the route as the router takes it, ether being the WETH of the
+// network the route was found on.
+func (r *Route) Addresses() []common.Address {
+	addrs := make([]common.Address, len(r.Path))
+	for i, token := range r.Path {
+		addrs[i] = token.Address
+		if token.IsETH() {
+			addrs[i] = r.weth
+		}
+	}
+	return addrs
+}
+
+// swapPath returns the default path of a swap from `quoteCurrency` to `baseCurrency`, through the
+// WETH of the network.
+func (c *DefiClient) swapPath(baseCurrency Token, quoteCurrency Token) []Token {
+	switch {
+	case quoteCurrency.IsETH(), baseCurrency.IsETH():
+		return []Token{quoteCurrency, baseCurrency}
+	default:
+		return []Token{quoteCurrency, c.weth(), baseCurrency}
+	}
+}
+
+func (c *DefiClient) pathAddrs(path []Token) []common.Address {
+	addrs := make([]common.Address, len(path))
+	for i, token := range path {
+		addrs[i] = c.erc20Address(token)
+	}
+	return addrs
+}
+
+// checkPath checks that a swap can go along `path`: ether can only be its first or last token.
+func (c *DefiClient) checkPath(path []Token) error {
+	if len(path) < 2 {
+		return fmt.Errorf("%w: %d tokens", ErrBadPath, len(path))
+	}
+	for i, token := range path {
+		if token == (Token{}) {
+			return ErrUnsupportedCoin
+		}
+		if token.IsETH() && i > 0 && i < len(path)-1 {
+			return fmt.Errorf("%w: ether in the middle of the path", ErrBadPath)
+		}
+		if i > 0 && c.erc20Address(token) == c.erc20Address(path[i-1]) {
+			return fmt.Errorf("%w: %s twice in a row", ErrBadPath, token)
+		}
+	}
+	return nil
+}
+
+// swapPathData packs the handler call of an exact input swap along `path`, the method depending on
+// whether ether is swapped in or out.
+func (c *DefiClient) swapPathData(protocol string, size *big.Int, minOut *big.Int, path []Token) ([]byte, error) {
+	method := "swapExactTokensForTokens"
+	if err := c.checkPath(path); err != nil {
+		return nil, &ActionError{Protocol: protocol, Method: method, Arg: "path", Err: err}
+	}
+	switch {
+	case path[0].IsETH():
+		method = "swapExactETHForTokens"
+	case path[len(path)-1].IsETH():
+		method = "swapExactTokensForETH"
+	}
+	return packAction(protocol, huniswap.HuniswapABI, method, size, minOut, c.pathAddrs(path))
+}
+
+// swapPathActions creates the action of an exact input swap of `amount` along `path` with the
+// handler at `handler`. The slippage is quoted with the router at `router`.
+// Uniswap and Sushiswap share the handler interface.
+func (c *DefiClient) swapPathActions(ctx context.Context, protocol string, handler common.Address, router common.Address, amount Size, path []Token, options []SwapOption) *Actions {
+	if err := c.checkPath(path); err != nil {
+		return failedActions(protocol, "swap", "path", err)
+	}
+	input, output := path[0], path[len(path)-1]
+	size, err := c.sizeOf(amount, c.erc20Address(input))
+	if err != nil {
+		return failedActions(protocol, "swap", "size", err)
+	}
+	minOut, err := c.minOutput(ctx, newSwapOptions(options), c.erc20Address(output), c.routerQuote(router, size, c.pathAddrs(path)))
+	if err != nil {
+		return failedActions(protocol, "swap", "amountOutMin", err)
+	}
+	data, err := c.swapPathData(protocol, size, minOut, path)
+	if err != nil {
+		return failedActions(protocol, "swap", "", err)
+	}
+
+	swap := action{
+		handlerAddr:  handler,
+		data:         data,
+		ethersNeeded: big.NewInt(0),
+	}
+	if input.IsETH() {
+		swap.ethersNeeded = size
+	} else {
+		swap.approvalTokens = []common.Address{c.erc20Address(input)}
+		swap.approvalTokenAmounts = []*big.Int{size}
+	}
+	return &Actions{Actions: []action{swap}}
+}
+
+// SwapPathActions creates a swap of `amount` of the first token of `path` for its last token, along
+// the pairs of `path`, e.g. a route found by `BestRoute`. `options` protect it against slippage.
+func (c *UniswapClient) SwapPathActions(ctx context.Context, amount Size, path []Token, options ...SwapOption) *Actions {
+	if c.err != nil {
+		return failedActions("Uniswap", "swap", "", c.err)
+	}
+	return c.client.swapPathActions(ctx,
+		"Uniswap", c.client.network.Handlers.Uniswap, c.client.network.Contracts.UniswapRouter, amount, path, options)
+}
+
+// SwapPathActions creates a swap of `amount` of the first token of `path` for its last token, along
+// the pairs of `path`, e.g. a route found by `BestRoute`. `options` protect it against slippage.
+func (c *SushiswapClient) SwapPathActions(ctx context.Context, amount Size, path []Token, options ...SwapOption) *Actions {
+	return c.client.swapPathActions(ctx,
+		"Sushiswap", c.client.network.Handlers.Sushiswap, c.client.network.Contracts.SushiswapRouter, amount, path, options)
+}
+
+// swapExactOutputActions creates the action of a swap along `path` for exactly `amount` of its last
+// token with the handler at `handler`. The maximum input is quoted with the router at `router`, and
+// is what the action approves or sends along, the unspent input being returned by the proxy.
+func (c *DefiClient) swapExactOutputActions(ctx context.Context, protocol string, handler common.Address, router common.Address, amount Size, path []Token, options []SwapOption) *Actions {
+	if err := c.checkPath(path); err != nil {
+		return failedActions(protocol, "swap", "path", err)
+	}
+	input, output := path[0], path[len(path)-1]
+	size, err := c.sizeOf(amount, c.erc20Address(output))
+	if err != nil {
+		return failedActions(protocol, "swap", "size", err)
+	}
+	if size == nil {
+		return failedActions(protocol, "swap", "amountOut", ErrNilAmount)
+	}
+	maxIn, err := c.maxInput(ctx, newSwapOptions(options), c.erc20Address(input), c.routerQuoteIn(router, size, c.pathAddrs(path)))
+	if err != nil {
+		return failedActions(protocol, "swap", "amountInMax", err)
+	}
+
+	swap := action{
+		handlerAddr:  handler,
+		ethersNeeded: big.NewInt(0),
+	}
+	switch {
+	case input.IsETH():
+		swap.ethersNeeded = maxIn
+		swap.data, err = packAction(protocol, huniswap.HuniswapABI, "swapETHForExactTokens", maxIn, size, c.pathAddrs(path))
+	case output.IsETH():
+		swap.data, err = packAction(protocol, huniswap.HuniswapABI, "swapTokensForExactETH", size, maxIn, c.pathAddrs(path))
+	default:
+		swap.data, err = packAction(protocol, huniswap.HuniswapABI, "swapTokensForExactTokens", size, maxIn, c.pathAddrs(path))
+	}
+	if err != nil {
+		return failedActions(protocol, "swap", "", err)
+	}
+	if !input.IsETH() {
+		swap.approvalTokens = []common.Address{c.erc20Address(input)}
+		swap.approvalTokenAmounts = []*big.Int{maxIn}
+	}
+	return &Actions{Actions: []action{swap}}
+}
+
+// SwapExactOutputActions creates a swap of `quoteCurrency` for exactly `amount` of `baseCurrency`,
+// e.g. to repay a flash loan, through WETH. The input is at most the one quoted with getAmountsIn,
+// `options` change the maximum.
+func (c *UniswapClient) SwapExactOutputActions(ctx context.Context, amount Size, baseCurrency Token, quoteCurrency Token, options ...SwapOption) *Actions {
+	return c.SwapExactOutputPathActions(ctx, amount, c.client.swapPath(baseCurrency, quoteCurrency), options...)
+}
+
+// SwapExactOutputPathActions creates a swap of the first token of `path` for exactly `amount` of its
+// last token, along the pairs of `path`. The input is at most the one quoted with getAmountsIn,
+// `options` change the maximum.
+func (c *UniswapClient) SwapExactOutputPathActions(ctx context.Context, amount Size, path []Token, options ...SwapOption) *Actions {
+	if c.err != nil {
+		return failedActions("Uniswap", "swap", "", c.err)
+	}
+	return c.client.swapExactOutputActions(ctx,
+		"Uniswap", c.client.network.Handlers.Uniswap, c.client.network.Contracts.UniswapRouter, amount, path, options)
+}
+
+// SwapExactOutputActions creates a swap of `quoteCurrency` for exactly `amount` of `baseCurrency`,
+// e.g. to repay a flash loan, through WETH. The input is at most the one quoted with getAmountsIn,
+// `options` change the maximum.
+func (c *SushiswapClient) SwapExactOutputActions(ctx context.Context, amount Size, baseCurrency Token, quoteCurrency Token, options ...SwapOption) *Actions {
+	return c.SwapExactOutputPathActions(ctx, amount, c.client.swapPath(baseCurrency, quoteCurrency), options...)
+}
+
+// SwapExactOutputPathActions creates a swap of the first token of `path` for exactly `amount` of its
+// last token, along the pairs of `path`. The input is at most the one quoted with getAmountsIn,
+// `options` change the maximum.
+func (c *SushiswapClient) SwapExactOutputPathActions(ctx context.Context, amount Size, path []Token, options ...SwapOption) *Actions {
+	return c.client.swapExactOutputActions(ctx,
+		"Sushiswap", c.client.network.Handlers.Sushiswap, c.client.network.Contracts.SushiswapRouter, amount, path, options)
+}
+
+// BestRoute finds the path with the highest output for a swap of `amount` of `from` to `to`, going
+// through at most `maxHops` pairs, `DefaultMaxHops` if zero or less. The paths go through the given
+// intermediate tokens, by default WETH, USDC, DAI and USDT. The reserves are read at the latest block.
+func (c *UniswapClient) BestRoute(ctx context.Context, amount Size, from Token, to Token, maxHops int, intermediates ...Token) (*Route, error) {
+	return c.client.bestRoute(ctx, c.client.network.Contracts.UniswapFactory, amount, from, to, maxHops, intermediates)
+}
+
+// BestRoute finds the path with the highest output for a swap of `amount` of `from` to `to`, going
+// through at most `maxHops` pairs, `DefaultMaxHops` if zero or less. The paths go through the given
+// intermediate tokens, by default WETH, USDC, DAI and USDT. The reserves are read at the latest block.
+func (c *SushiswapClient) BestRoute(ctx context.Context, amount Size, from Token, to Token, maxHops int, intermediates ...Token) (*Route, error) {
+	return c.client.bestRoute(ctx, c.client.network.Contracts.SushiswapFactory, amount, from, to, maxHops, intermediates)
+}
+
+func (c *DefiClient) bestRoute(ctx context.Context, factoryAddr common.Address, amount Size, from Token, to Token, maxHops int, intermediates []Token) (*Route, error) {
+	if err := c.checkPath([]Token{from, to}); err != nil {
+		return nil, err
+	}
+	size, err := c.sizeOf(amount, c.erc20Address(from))
+	if err != nil {
+		return nil, err
+	}
+	if size == nil {
+		return nil, ErrNilAmount
+	}
+	if size.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %v to swap", ErrBadAmount, size)
+	}
+	if len(intermediates) == 0 {
+		for _, symbol := range defaultIntermediates {
+			if token, ok := c.tokens.BySymbol(symbol); ok {
+				intermediates = append(intermediates, token)
+			}
+		}
+	}
+	uniFactory, err := factory.NewFactory(factoryAddr, c.conn)
+	if err != nil {
+		return nil, err
+	}
+	reserves := &pairReserves{
+		client:  c,
+		ctx:     ctx,
+		factory: uniFactory,
+		pairs:   make(map[[2]common.Address][2]*big.Int),
+	}
+	return c.findRoute(size, from, to, maxHops, intermediates, reserves.of)
+}
+
+// reservesFunc returns the reserves of `tokenIn` and `tokenOut` in their pair, nil if there is no pair.
+type reservesFunc func(tokenIn common.Address, tokenOut common.Address) (*big.Int, *big.Int, error)
+
+// pairReserves reads the reserves of the pairs of a factory, once per pair.
+type pairReserves struct {
+	client  *DefiClient
+	ctx     context.Context
+	factory *factory.Factory
+	pairs   map[[2]common.Address][2]*big.Int
+}
+
+func (r *pairReserves) of(tokenIn common.Address, tokenOut common.Address) (*big.Int, *big.Int, error) {
+	// The pairs sort their tokens by address.
+	token0, token1 := tokenIn, tokenOut
+	if bytes.Compare(token1.Bytes(), token0.Bytes()) < 0 {
+		token0, token1 = token1, token0
+	}
+	key := [2]common.Address{token0, token1}
+	reserves, ok := r.pairs[key]
+	if !ok {
+		opts := r.client.callOpts(r.ctx, nil)
+		pairAddr, err := r.factory.GetPair(opts, token0, token1)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error getting pair: %w", err)
+		}
+		if pairAddr != (common.Address{}) {
+			uniPair, err := pair.NewPair(pairAddr, r.client.conn)
+			if err != nil {
+				return nil, nil, err
+			}
+			got, err := uniPair.GetReserves(opts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Error getting reserves of %s: %w", pairAddr.Hex(), err)
+			}
+			reserves = [2]*big.Int{got.Reserve0, got.Reserve1}
+		}
+		r.pairs[key] = reserves
+	}
+	if reserves[0] == nil {
+		return nil, nil, nil
+	}
+	if token0 == tokenIn {
+		return reserves[0], reserves[1], nil
+	}
+	return reserves[1], reserves[0], nil
+}
+
+// findRoute searches the paths from `from` to `to` through `intermediates` with at most `maxHops`
+// pairs, and returns the one with the highest output. Shorter paths win ties, they cost less gas.
+func (c *DefiClient) findRoute(size *big.Int, from Token, to Token, maxHops int, intermediates []Token, reserves reservesFunc) (*Route, error) {
+	if maxHops <= 0 {
+		maxHops = DefaultMaxHops
+	}
+	var candidates []Token
+	seen := map[common.Address]bool{c.erc20Address(from): true, c.erc20Address(to): true}
+	for _, token := range intermediates {
+		if !seen[c.erc20Address(token)] {
+			seen[c.erc20Address(token)] = true
+			candidates = append(candidates, token)
+		}
+	}
+
+	var best *Route
+	used := make([]bool, len(candidates))
+	path := []Token{from}
+	var search func(amountIn *big.Int) error
+	search = func(amountIn *big.Int) error {
+		last := path[len(path)-1]
+		next := append(make([]Token, 0, len(candidates)+1), to)
+		if len(path) < maxHops {
+			for i, token := range candidates {
+				if !used[i] {
+					next = append(next, token)
+				}
+			}
+		}
+		for _, token := range next {
+			reserveIn, reserveOut, err := reserves(c.erc20Address(last), c.erc20Address(token))
+			if err != nil {
+				return err
+			}
+			amountOut := getAmountOut(amountIn, reserveIn, reserveOut)
+			if amountOut == nil {
+				continue
+			}
+			if token == to {
+				cmp := 1
+				if best != nil {
+					cmp = amountOut.Cmp(best.AmountOut)
+				}
+				if cmp > 0 || cmp == 0 && len(path)+1 < len(best.Path) {
+					best = &Route{Path: append(append([]Token{}, path...), to), AmountIn: size, AmountOut: amountOut, weth: c.weth().Address}
+				}
+				continue
+			}
+			i := indexOf(candidates, token)
+			used[i] = true
+			path = append(path, token)
+			err = search(amountOut)
+			path = path[:len(path)-1]
+			used[i] = false
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := search(size); err != nil {
+		return nil, err
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w from %s to %s in %d hops", ErrNoRoute, from, to, maxHops)
+	}
+	return best, nil
+}
+
+func indexOf(tokens []Token, token Token) int {
+	for i := range tokens {
+		if tokens[i] == token {
+			return i
+		}
+	}
+	return -1
+}
+
+// getAmountOut returns the output of a swap of `amountIn` in a pair with the given reserves, as the
+// Uniswap V2 library computes it with the 0.3% fee. It is nil if the pair is missing or empty.
+func getAmountOut(amountIn *big.Int, reserveIn *big.Int, reserveOut *big.Int) *big.Int {
+	if reserveIn == nil || reserveOut == nil || reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		return nil
+	}
+	amountInWithFee := new(big.Int).Mul(amountIn, big.NewInt(997))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, big.NewInt(1000))
+	denominator.Add(denominator, amountInWithFee)
+	return numerator.Div(numerator, denominator)
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rafaelescrich/go-defi-1/binding/furucombo"
+)
+
+// SimulationResult is the outcome of running a combo through eth_call against the proxy.
+type SimulationResult struct {
+	// Success is false if the proxy call reverted.
+	Success bool
+	// GasUsed is the gas of the combo at the simulated block, read from the `debug_traceCall` of
+	// the combo when the client has an RPC client, see `WithRPCClient`, so that it is the total of
+	// the gas breakdown of `EstimateActionsGas`. Without it, the node estimates the gas at the latest
+	// block and GasUsed is 0 for a past block.
+	GasUsed uint64
+	// RevertReason is the decoded revert reason if the combo reverted.
+	RevertReason string
+	// RevertData is the raw revert data returned by the node, if any.
+	RevertData []byte
+	// Err locates the failing action if the combo reverted.
+	Err *ExecutionError
+	// BalanceDeltas are the balance changes of the sender caused by the combo, keyed by token
+	// address, ether is keyed by the zero address. They are read from a `debug_traceCall` of the
+	// combo: the ether it moves and the ERC20 Transfer events from and to the sender, including the
+	// tokens the proxy sends back. Tokens that move balances without a Transfer event are missed.
+	// BalanceDeltas is nil if the client has no RPC client to trace with, see `WithRPCClient`.
+	BalanceDeltas map[common.Address]*big.Int
+}
+
+// SimulateActions runs the handlers, datas and value of `actions` through eth_call against the
+// proxy, as the sender of the client, without sending a transaction.
+// `blockNum` is the block whose state is used, nil means the latest block. The funds injected
+// into the proxy are capped by the balances of the sender at that block too.
+// A revert is reported in the result, the returned error is only set if the simulation couldn't run.
+func (c *DefiClient) SimulateActions(ctx context.Context, actions *Actions, blockNum *big.Int) (*SimulationResult, error) {
+	combined, err := c.combine(ctx, actions, blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := c.batchExecMsg(combined)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(SimulationResult)
+	if _, err := c.conn.CallContract(ctx, msg, blockNum); err != nil {
+		reason, data, ok := decodeRevert(err)
+		if !ok {
+			return nil, err
+		}
+		result.RevertReason = reason
+		result.RevertData = data
+		result.Err = &ExecutionError{Message: reason, Data: data}
+		c.locateFailure(ctx, actions, blockNum, result.Err)
+		return result, nil
+	}
+	result.Success = true
+
+	if c.rpcClient != nil {
+		frame, err := c.traceCall(ctx, msg, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("Error tracing the combo: %w", err)
+		}
+		result.GasUsed = uint64(frame.GasUsed)
+		result.BalanceDeltas = balanceDeltas(frame, c.opts.From)
+	} else if blockNum == nil {
+		gas, err := c.conn.EstimateGas(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		result.GasUsed = gas
+	}
+	return result, nil
+}
+
+// batchExecMsg returns the call message of the proxy batchExec for the given combo.
+func (c *DefiClient) batchExecMsg(combined *combo) (ethereum.CallMsg, error) {
+	data, err := packAction("Furucombo", furucombo.FurucomboABI, "batchExec", combined.handlers, combined.datas)
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+	proxyAddr := c.network.Proxy
+	return ethereum.CallMsg{
+		From:  c.opts.From,
+		To:    &proxyAddr,
+		Value: combined.totalEthers,
+		Data:  data,
+	}, nil
+}
+
+// decodeRevert extracts the revert reason and the raw revert data from an eth_call error.
+// It returns false if the error isn't a revert, e.g. a connection failure.
+func decodeRevert(err error) (string, []byte, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := revertData(dataErr.ErrorData()); ok {
+			return decodeRevertData(data), data, true
+		}
+	}
+
+	// Nodes that don't return the revert data put the reason in the message:
+	// geth "execution reverted: reason", ganache "VM Exception while processing transaction: revert reason".
+	msg := err.Error()
+	for _, prefix := range []string{"execution reverted", "VM Exception while processing transaction: revert"} {
+		if i := strings.Index(msg, prefix); i >= 0 {
+			reason := strings.TrimPrefix(msg[i+len(prefix):], ":")
+			return strings.TrimSpace(reason), nil, true
+		}
+	}
+	return "", nil, false
+}
+
+// revertData finds the hex encoded revert data in the error data of a JSON-RPC error.
+// geth returns it as a string, ganache nests it under the transaction hash as `return`.
+func revertData(errorData interface{}) ([]byte, bool) {
+	switch data := errorData.(type) {
+	case string:
+		decoded, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+		if err != nil || len(decoded) == 0 {
+			return nil, false
+		}
+		return decoded, true
+	case map[string]interface{}:
+		if ret, ok := data["return"]; ok {
+			return revertData(ret)
+		}
+		for _, nested := range data {
+			if decoded, ok := revertData(nested); ok {
+				return decoded, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ReplayFailedTransaction replays a reverted combo transaction with eth_call on the state of the
+// block before it was mined, and returns the *ExecutionError locating the failing action.
+// `actions` must be the `Actions` that the transaction was built from.
+func (c *DefiClient) ReplayFailedTransaction(ctx context.Context, actions *Actions, txHash common.Hash) (*ExecutionError, error) {
+	tx, _, err := c.conn.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := c.conn.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status == 1 {
+		return nil, fmt.Errorf("transaction %s did not revert", txHash.Hex())
+	}
+	execErr := &ExecutionError{Index: -1, TxHash: txHash}
+
+	// The replay runs on the parent block, the transactions mined before it in the same block
+	// are not applied, so a failure caused by them may not reproduce.
+	parent := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	msg := ethereum.CallMsg{
+		From:  c.opts.From,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	_, err = c.conn.CallContract(ctx, msg, parent)
+	if err == nil {
+		execErr.Message = "tx receipt status is not 1, indicating a failure occurred"
+		return execErr, nil
+	}
+	reason, data, ok := decodeRevert(err)
+	if !ok {
+		return nil, err
+	}
+	execErr.Message = reason
+	execErr.Data = data
+	c.locateFailure(ctx, actions, parent, execErr)
+	return execErr, nil
+}
+
+// locateFailure fills in the index and handler of the failing action.
+// The handler is taken from the revert reason when a handler names itself in it, if that doesn't
+// identify a single action, growing prefixes of the combo are replayed until one reverts.
+func (c *DefiClient) locateFailure(ctx context.Context, actions *Actions, blockNum *big.Int, execErr *ExecutionError) {
+	execErr.Index = -1
+	if handler, ok := c.network.Handlers.handlerFromReason(execErr.Message); ok {
+		execErr.Handler = handler
+		candidates := make([]int, 0)
+		for i, action := range actions.Actions {
+			if action.handlerAddr == handler {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) == 1 {
+			execErr.Index = candidates[0]
+			return
+		}
+	}
+
+	for k := 1; k <= len(actions.Actions); k++ {
+		combined, err := c.combine(ctx, &Actions{Actions: actions.Actions[:k]}, blockNum)
+		if err != nil {
+			return
+		}
+		msg, err := c.batchExecMsg(combined)
+		if err != nil {
+			return
+		}
+		_, err = c.conn.CallContract(ctx, msg, blockNum)
+		if err == nil {
+			continue
+		}
+		if _, _, ok := decodeRevert(err); ok {
+			execErr.Index = k - 1
+			if execErr.Handler == (common.Address{}) {
+				execErr.Handler = actions.Actions[k-1].handlerAddr
+			}
+		}
+		return
+	}
+}
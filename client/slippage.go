@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/balancer"
+	"github.com/rafaelescrich/go-defi-1/binding/kyber"
+	"github.com/rafaelescrich/go-defi-1/binding/uniswap"
+)
+
+// MaxSlippageBps is the largest slippage tolerance, 100%.
+const MaxSlippageBps = 10000
+
+// balancerPools is the number of pools the Balancer swaps are split across.
+const balancerPools = 10
+
+// kyberETHAddr is the address Kyber stands for ether with.
+var kyberETHAddr = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// ErrBadSlippage is returned when a slippage tolerance is out of range.
+var ErrBadSlippage = errors.New("bad slippage")
+
+// SwapOption protects a swap against slippage. Without options an exact input swap accepts any
+// output, and an exact output swap pays up to the input quoted when the action is built.
+type SwapOption func(*swapOptions)
+
+type swapOptions struct {
+	min         Size
+	max         Size
+	slippageBps uint64
+	slippage    bool
+	deadline    time.Time
+}
+
+// WithMinOutput sets the minimum output of a swap, in the output token.
+func WithMinOutput(min Size) SwapOption {
+	return func(o *swapOptions) {
+		o.min = min
+	}
+}
+
+// WithMaxInput sets the maximum input of an exact output swap, in the input token.
+func WithMaxInput(max Size) SwapOption {
+	return func(o *swapOptions) {
+		o.max = max
+	}
+}
+
+// WithSlippage sets the slippage tolerance of a swap in basis points: the output is quoted on chain
+// when the action is built, with the context of the builder, and the swap reverts if it gets more
+// than `bps` less than the quote.
+// Along with `WithMinOutput` the higher of the two minimums is used. For an exact output swap the
+// input is quoted instead, and the swap reverts if it costs more than `bps` over the quote.
+// Along with `WithMaxInput` the lower of the two maximums is used.
+func WithSlippage(bps uint64) SwapOption {
+	return func(o *swapOptions) {
+		o.slippageBps = bps
+		o.slippage = true
+	}
+}
+
+func newSwapOptions(options []SwapOption) *swapOptions {
+	o := new(swapOptions)
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// check fails fast on a slippage tolerance out of range, before anything is quoted.
+func (o *swapOptions) check() error {
+	if o.slippage && o.slippageBps > MaxSlippageBps {
+		return fmt.Errorf("%w: %d bps is more than %d", ErrBadSlippage, o.slippageBps, MaxSlippageBps)
+	}
+	return nil
+}
+
+// minOutput returns the minimum output of a swap to `output`, quoting the expected output with
+// `quote` when a slippage tolerance is set.
+func (c *DefiClient) minOutput(ctx context.Context, o *swapOptions, output common.Address, quote func(ctx context.Context) (*big.Int, error)) (*big.Int, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	min, err := c.sizeOf(o.min, output)
+	if err != nil {
+		return nil, err
+	}
+	if min == nil {
+		min = big.NewInt(0)
+	}
+	if !o.slippage {
+		return min, nil
+	}
+	expected, err := quote(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting quote: %w", err)
+	}
+	quoted, err := applySlippage(expected, o.slippageBps)
+	if err != nil {
+		return nil, err
+	}
+	if quoted.Cmp(min) > 0 {
+		return quoted, nil
+	}
+	return min, nil
+}
+
+// maxInput returns the maximum input of an exact output swap from `input`, quoting the expected
+// input with `quote` unless only an explicit maximum is set.
+func (c *DefiClient) maxInput(ctx context.Context, o *swapOptions, input common.Address, quote func(ctx context.Context) (*big.Int, error)) (*big.Int, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	max, err := c.sizeOf(o.max, input)
+	if err != nil {
+		return nil, err
+	}
+	if max != nil && !o.slippage {
+		return max, nil
+	}
+	expected, err := quote(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting quote: %w", err)
+	}
+	quoted, err := addSlippage(expected, o.slippageBps)
+	if err != nil {
+		return nil, err
+	}
+	if max != nil && max.Cmp(quoted) < 0 {
+		return max, nil
+	}
+	return quoted, nil
+}
+
+// applySlippage returns `value` less `bps` basis points.
+func applySlippage(value *big.Int, bps uint64) (*big.Int, error) {
+	if bps > MaxSlippageBps {
+		return nil, fmt.Errorf("%w: %d bps is more than %d", ErrBadSlippage, bps, MaxSlippageBps)
+	}
+	min := new(big.Int).Mul(value, big.NewInt(int64(MaxSlippageBps-bps)))
+	return min.Div(min, big.NewInt(MaxSlippageBps)), nil
+}
+
+// addSlippage returns `value` plus `bps` basis points, rounded up.
+func addSlippage(value *big.Int, bps uint64) (*big.Int, error) {
+	if bps > MaxSlippageBps {
+		return nil, fmt.Errorf("%w: %d bps is more than %d", ErrBadSlippage, bps, MaxSlippageBps)
+	}
+	max := new(big.Int).Mul(value, big.NewInt(int64(MaxSlippageBps+bps)))
+	max.Add(max, big.NewInt(MaxSlippageBps-1))
+	return max.Div(max, big.NewInt(MaxSlippageBps)), nil
+}
+
+// routerQuote quotes the output of a swap of `size` along `path` with the router at `routerAddr`.
+func (c *DefiClient) routerQuote(routerAddr common.Address, size *big.Int, path []common.Address) func(context.Context) (*big.Int, error) {
+	return func(ctx context.Context) (*big.Int, error) {
+		if size == nil {
+			return nil, ErrNilAmount
+		}
+		router, err := uniswap.NewUniswap(routerAddr, c.conn)
+		if err != nil {
+			return nil, err
+		}
+		amounts, err := router.GetAmountsOut(c.callOpts(ctx, nil), size, path)
+		if err != nil {
+			return nil, err
+		}
+		return amounts[len(amounts)-1], nil
+	}
+}
+
+// routerQuoteIn quotes the input of a swap for an output of `size` along `path` with the router at `routerAddr`.
+func (c *DefiClient) routerQuoteIn(routerAddr common.Address, size *big.Int, path []common.Address) func(context.Context) (*big.Int, error) {
+	return func(ctx context.Context) (*big.Int, error) {
+		if size == nil {
+			return nil, ErrNilAmount
+		}
+		router, err := uniswap.NewUniswap(routerAddr, c.conn)
+		if err != nil {
+			return nil, err
+		}
+		amounts, err := router.GetAmountsIn(c.callOpts(ctx, nil), size, path)
+		if err != nil {
+			return nil, err
+		}
+		return amounts[0], nil
+	}
+}
+
+// kyberAddr returns the address Kyber knows a token by.
+func kyberAddr(token Token) common.Address {
+	if token.IsETH() {
+		return kyberETHAddr
+	}
+	return token.Address
+}
+
+// kyberMinRate returns the minimum rate of a Kyber swap of `size`, scaled by 1e18 as Kyber expects.
+func (c *KyberswapClient) kyberMinRate(ctx context.Context, o *swapOptions, size *big.Int, src Token, dest Token) (*big.Int, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	minRate := big.NewInt(0)
+	if o.min != nil {
+		min, err := c.client.sizeOf(o.min, c.client.erc20Address(dest))
+		if err != nil {
+			return nil, err
+		}
+		if min != nil && min.Sign() > 0 {
+			if size == nil || size.Sign() == 0 {
+				return nil, ErrNilAmount
+			}
+			srcDecimals, err := c.client.Decimals(ctx, src)
+			if err != nil {
+				return nil, err
+			}
+			destDecimals, err := c.client.Decimals(ctx, dest)
+			if err != nil {
+				return nil, err
+			}
+			// rate = min * 10^(18 + srcDecimals - destDecimals) / size, rounded up.
+			num := new(big.Int).Mul(min, pow10(18+int(srcDecimals)))
+			den := new(big.Int).Mul(size, pow10(int(destDecimals)))
+			minRate.Add(num, new(big.Int).Sub(den, big.NewInt(1)))
+			minRate.Div(minRate, den)
+		}
+	}
+	if !o.slippage {
+		return minRate, nil
+	}
+	if size == nil {
+		return nil, ErrNilAmount
+	}
+	proxy, err := kyber.NewKyber(c.client.network.Contracts.KyberNetworkProxy, c.client.conn)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := proxy.GetExpectedRate(c.client.callOpts(ctx, nil), kyberAddr(src), kyberAddr(dest), size)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting quote: %w", err)
+	}
+	quoted, err := applySlippage(rates.ExpectedRate, o.slippageBps)
+	if err != nil {
+		return nil, err
+	}
+	if quoted.Cmp(minRate) > 0 {
+		return quoted, nil
+	}
+	return minRate, nil
+}
+
+// balancerQuote quotes the output of a smart swap of `size` on Balancer.
+func (c *BalancerClient) balancerQuote(tokenIn common.Address, tokenOut common.Address, size *big.Int) func(context.Context) (*big.Int, error) {
+	return func(ctx context.Context) (*big.Int, error) {
+		if size == nil {
+			return nil, ErrNilAmount
+		}
+		proxy, err := balancer.NewBalancer(c.client.network.Contracts.BalancerExchange, c.client.conn)
+		if err != nil {
+			return nil, err
+		}
+		split, err := proxy.ViewSplitExactIn(c.client.callOpts(ctx, nil), tokenIn, tokenOut, size, big.NewInt(balancerPools))
+		if err != nil {
+			return nil, err
+		}
+		return split.TotalOutput, nil
+	}
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
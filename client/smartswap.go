@@ -0,0 +1,363 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rafaelescrich/go-defi-1/binding/curve"
+)
+
+// SmartSwapParts is the number of equal parts a smart swap divides its input in to split it across venues.
+const SmartSwapParts = 4
+
+// curveMaxCoins is the most coins a Curve pool has.
+const curveMaxCoins = 8
+
+// ErrNoVenue is returned when no venue quotes a smart swap.
+var ErrNoVenue = errors.New("no venue quotes the swap")
+
+// VenueError is why a venue was left out of a smart swap.
+type VenueError struct {
+	Venue Venue
+	// Pool is the Curve pool of a Curve venue.
+	Pool common.Address
+	Err  error
+}
+
+func (e *VenueError) Error() string {
+	if e.Pool != (common.Address{}) {
+		return fmt.Sprintf("%s %s: %v", e.Venue, e.Pool.Hex(), e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Venue, e.Err)
+}
+
+func (e *VenueError) Unwrap() error {
+	return e.Err
+}
+
+// SwapLeg is the part of a smart swap sent to one venue.
+type SwapLeg struct {
+	Venue Venue
+	// Pool is the Curve pool of a Curve leg.
+	Pool  common.Address
+	Input Amount
+	// Expected is the quoted output of the leg, the leg reverts under MinOutput.
+	Expected  Amount
+	MinOutput Amount
+}
+
+// SwapDecision is how a smart swap was routed, kept for auditing.
+type SwapDecision struct {
+	Input       Amount
+	To          Token
+	SlippageBps uint64
+	// Quotes are the quotes of the whole input on each venue, best first.
+	Quotes []*Quote
+	// Failures are the venues that couldn't be quoted.
+	Failures []*VenueError
+	// Legs are the venues the input is sent to, more than one if splitting it gets more output.
+	Legs []SwapLeg
+	// Expected is the quoted output of all the legs, MinOutput the sum of their minimums.
+	Expected  Amount
+	MinOutput Amount
+}
+
+// Split tells if the swap is split across venues.
+func (d *SwapDecision) Split() bool {
+	return len(d.Legs) > 1
+}
+
+func (d *SwapDecision) String() string {
+	legs := make([]string, len(d.Legs))
+	for i, leg := range d.Legs {
+		legs[i] = fmt.Sprintf("%s on %s", leg.Input, leg.Venue)
+	}
+	return fmt.Sprintf("%s to %s: %s, expecting %s, at least %s",
+		d.Input, d.To.Symbol, strings.Join(legs, " + "), d.Expected, d.MinOutput)
+}
+
+// smartVenue quotes and builds the swaps of a pair on one venue.
+type smartVenue struct {
+	venue Venue
+	pool  common.Address
+	quote func(ctx context.Context, size *big.Int) (*Quote, error)
+	build func(ctx context.Context, size *big.Int, minOut *big.Int) *Actions
+	// outputs are the quoted outputs of 1 to `SmartSwapParts` parts of the input.
+	outputs []*big.Int
+}
+
+// SmartSwapActions swaps `amount` of `from` to `to` on the venues the proxy has a handler for:
+// Uniswap, Sushiswap, Kyber, Balancer and the Curve pools of the network holding both tokens.
+// Each venue is quoted for parts of the input, and the input goes to the best venue or is split
+// across venues if that gets more output. Each leg reverts if it gets more than `slippageBps`
+// less than its quote. The returned decision records the quotes and the legs, also on failure.
+func (c *DefiClient) SmartSwapActions(ctx context.Context, amount Size, from Token, to Token, slippageBps uint64) (*Actions, *SwapDecision) {
+	decision := &SwapDecision{To: to, SlippageBps: slippageBps}
+	if err := c.checkPath([]Token{from, to}); err != nil {
+		return failedActions("SmartSwap", "swap", "tokens", err), decision
+	}
+	size, err := c.quoteSize(amount, from)
+	if err != nil {
+		return failedActions("SmartSwap", "swap", "size", err), decision
+	}
+	decision.Input = RawAmount(size, from)
+	if slippageBps > MaxSlippageBps {
+		return failedActions("SmartSwap", "swap", "slippage",
+			fmt.Errorf("%w: %d bps is more than %d", ErrBadSlippage, slippageBps, MaxSlippageBps)), decision
+	}
+
+	var venues []*smartVenue
+	for _, venue := range append(c.pairVenues(from, to), c.curveVenues(ctx, from, to, decision)...) {
+		if err := venue.quoteParts(ctx, size); err != nil {
+			decision.Failures = append(decision.Failures, &VenueError{Venue: venue.venue, Pool: venue.pool, Err: err})
+			continue
+		}
+		venues = append(venues, venue)
+		quote := newQuote(venue.venue, size, from, venue.outputs[SmartSwapParts-1], to)
+		quote.Pool = venue.pool
+		decision.Quotes = append(decision.Quotes, quote)
+	}
+	sort.SliceStable(decision.Quotes, func(i, j int) bool {
+		return decision.Quotes[i].Better(decision.Quotes[j])
+	})
+	if len(venues) == 0 {
+		return failedActions("SmartSwap", "swap", "", fmt.Errorf("%w: %s to %s", ErrNoVenue, from, to)), decision
+	}
+
+	legs, err := smartLegs(ctx, venues, splitParts(venues), size, from, to)
+	if err != nil {
+		return failedActions("SmartSwap", "swap", "", err), decision
+	}
+	decision.Legs = legs
+
+	actions := new(Actions)
+	expected, minOutput := big.NewInt(0), big.NewInt(0)
+	for i := range decision.Legs {
+		leg := &decision.Legs[i]
+		minOut, _ := applySlippage(leg.Expected.Int(), slippageBps)
+		leg.MinOutput = RawAmount(minOut, to)
+		expected.Add(expected, leg.Expected.Int())
+		minOutput.Add(minOutput, minOut)
+		if err := actions.Add(venueOf(venues, leg).build(ctx, leg.Input.Int(), minOut)); err != nil {
+			return actions, decision
+		}
+	}
+	decision.Expected = RawAmount(expected, to)
+	decision.MinOutput = RawAmount(minOutput, to)
+
+	// The legs inject their inputs separately, a single injection of the whole input replaces them.
+	if !from.IsETH() {
+		for i := range actions.Actions {
+			actions.Actions[i].approvalTokens = nil
+			actions.Actions[i].approvalTokenAmounts = nil
+		}
+		actions.Actions[0].approvalTokens = []common.Address{c.erc20Address(from)}
+		actions.Actions[0].approvalTokenAmounts = []*big.Int{size}
+	}
+	return actions, decision
+}
+
+// smartLegs splits `size` in the legs of `parts` of the venues. The parts round down, the rest of
+// the input goes to the first leg, which is quoted again for its whole input.
+func smartLegs(ctx context.Context, venues []*smartVenue, parts []int, size *big.Int, from Token, to Token) ([]SwapLeg, error) {
+	var legs []SwapLeg
+	assigned := big.NewInt(0)
+	for i, venue := range venues {
+		if parts[i] == 0 {
+			continue
+		}
+		legSize := new(big.Int).Mul(size, big.NewInt(int64(parts[i])))
+		legSize.Div(legSize, big.NewInt(SmartSwapParts))
+		assigned.Add(assigned, legSize)
+		legs = append(legs, SwapLeg{
+			Venue:    venue.venue,
+			Pool:     venue.pool,
+			Input:    RawAmount(legSize, from),
+			Expected: RawAmount(venue.outputs[parts[i]-1], to),
+		})
+	}
+	if rest := new(big.Int).Sub(size, assigned); rest.Sign() > 0 {
+		leg := &legs[0]
+		leg.Input = RawAmount(new(big.Int).Add(leg.Input.Int(), rest), from)
+		quote, err := venueOf(venues, leg).quote(ctx, leg.Input.Int())
+		if err != nil {
+			return nil, &VenueError{Venue: leg.Venue, Pool: leg.Pool, Err: err}
+		}
+		leg.Expected = RawAmount(quote.Output.Int(), to)
+	}
+	return legs, nil
+}
+
+func venueOf(venues []*smartVenue, leg *SwapLeg) *smartVenue {
+	for _, venue := range venues {
+		if venue.venue == leg.Venue && venue.pool == leg.Pool {
+			return venue
+		}
+	}
+	return nil
+}
+
+// quoteParts quotes 1 to `SmartSwapParts` parts of `size` on the venue.
+func (v *smartVenue) quoteParts(ctx context.Context, size *big.Int) error {
+	v.outputs = make([]*big.Int, SmartSwapParts)
+	for k := 1; k <= SmartSwapParts; k++ {
+		partSize := new(big.Int).Mul(size, big.NewInt(int64(k)))
+		partSize.Div(partSize, big.NewInt(SmartSwapParts))
+		quote, err := v.quote(ctx, partSize)
+		if err != nil {
+			return err
+		}
+		v.outputs[k-1] = quote.Output.Int()
+	}
+	if v.outputs[SmartSwapParts-1].Sign() <= 0 {
+		return errors.New("no output")
+	}
+	return nil
+}
+
+// splitParts returns how many parts of the input go to each venue to get the most output,
+// by dynamic programming over the venues. A single venue wins ties, a split costs more gas.
+func splitParts(venues []*smartVenue) []int {
+	// best[v][n] is the most output of n parts on the first v venues, choice[v][n] the parts of venue v-1.
+	best := make([][]*big.Int, len(venues)+1)
+	choice := make([][]int, len(venues)+1)
+	best[0] = make([]*big.Int, SmartSwapParts+1)
+	for n := 0; n <= SmartSwapParts; n++ {
+		best[0][n] = big.NewInt(-1)
+	}
+	best[0][0] = big.NewInt(0)
+	for v := 1; v <= len(venues); v++ {
+		best[v] = make([]*big.Int, SmartSwapParts+1)
+		choice[v] = make([]int, SmartSwapParts+1)
+		for n := 0; n <= SmartSwapParts; n++ {
+			best[v][n] = best[v-1][n]
+			for k := 1; k <= n; k++ {
+				if best[v-1][n-k].Sign() < 0 {
+					continue
+				}
+				output := new(big.Int).Add(best[v-1][n-k], venues[v-1].outputs[k-1])
+				// Ties go to the fewest venues: all the parts on one venue are tried last.
+				if output.Cmp(best[v][n]) > 0 || output.Cmp(best[v][n]) == 0 && k == n {
+					best[v][n] = output
+					choice[v][n] = k
+				}
+			}
+		}
+	}
+
+	parts := make([]int, len(venues))
+	n := SmartSwapParts
+	for v := len(venues); v > 0 && n > 0; v-- {
+		parts[v-1] = choice[v][n]
+		n -= choice[v][n]
+	}
+	return parts
+}
+
+// pairVenues are the venues quoted from the pair alone.
+func (c *DefiClient) pairVenues(from Token, to Token) []*smartVenue {
+	q := c.Quoter()
+	quote := func(venue Venue) func(context.Context, *big.Int) (*Quote, error) {
+		return func(ctx context.Context, size *big.Int) (*Quote, error) {
+			return q.Quote(ctx, venue, size, from, to)
+		}
+	}
+	return []*smartVenue{
+		{
+			venue: VenueUniswap,
+			quote: quote(VenueUniswap),
+			build: func(ctx context.Context, size *big.Int, minOut *big.Int) *Actions {
+				return c.Uniswap().SwapActions(ctx, size, to, from, WithMinOutput(minOut))
+			},
+		},
+		{
+			venue: VenueSushiswap,
+			quote: quote(VenueSushiswap),
+			build: func(ctx context.Context, size *big.Int, minOut *big.Int) *Actions {
+				return c.Sushiswap().SwapActions(ctx, size, to, from, WithMinOutput(minOut))
+			},
+		},
+		{
+			venue: VenueKyber,
+			quote: quote(VenueKyber),
+			build: func(ctx context.Context, size *big.Int, minOut *big.Int) *Actions {
+				return c.Kyberswap().SwapActions(ctx, size, to, from, WithMinOutput(minOut))
+			},
+		},
+		{
+			venue: VenueBalancer,
+			quote: quote(VenueBalancer),
+			build: func(ctx context.Context, size *big.Int, minOut *big.Int) *Actions {
+				return c.Balancer().Swap(ctx, from, to, size, WithMinOutput(minOut))
+			},
+		},
+	}
+}
+
+// curveVenues are the Curve pools of the network holding both tokens, found with `coins`.
+// The pools that can't be read are recorded in the failures of the decision.
+func (c *DefiClient) curveVenues(ctx context.Context, from Token, to Token, decision *SwapDecision) []*smartVenue {
+	if from.IsETH() || to.IsETH() {
+		return nil
+	}
+	names := make([]string, 0, len(c.network.Contracts.CurvePools))
+	for name := range c.network.Contracts.CurvePools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var venues []*smartVenue
+	for _, name := range names {
+		pool := c.network.Contracts.CurvePools[name].Pool
+		i, j, err := c.curveIndices(ctx, pool, from, to)
+		if err != nil {
+			decision.Failures = append(decision.Failures, &VenueError{Venue: VenueCurve, Pool: pool, Err: err})
+			continue
+		}
+		if i == nil || j == nil {
+			continue
+		}
+		venues = append(venues, &smartVenue{
+			venue: VenueCurve,
+			pool:  pool,
+			quote: func(ctx context.Context, size *big.Int) (*Quote, error) {
+				return c.Quoter().Curve(ctx, pool, i, j, size, from, to)
+			},
+			build: func(ctx context.Context, size *big.Int, minOut *big.Int) *Actions {
+				return c.Curve().ExchangeActions(pool, from.Address, to.Address, i, j, size, minOut)
+			},
+		})
+	}
+	return venues
+}
+
+// curveIndices returns the indices of `from` and `to` in the Curve pool at `pool`, nil if it doesn't hold them.
+func (c *DefiClient) curveIndices(ctx context.Context, pool common.Address, from Token, to Token) (*big.Int, *big.Int, error) {
+	curvePool, err := curve.NewCurve(pool, c.conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	var i, j *big.Int
+	for k := int64(0); k < curveMaxCoins; k++ {
+		coin, err := curvePool.Coins(c.callOpts(ctx, nil), big.NewInt(k))
+		if err != nil {
+			// Reading past the last coin reverts.
+			if _, _, ok := decodeRevert(err); ok || k > 0 {
+				break
+			}
+			return nil, nil, err
+		}
+		switch coin {
+		case from.Address:
+			i = big.NewInt(k)
+		case to.Address:
+			j = big.NewInt(k)
+		}
+	}
+	return i, j, nil
+}
@@ -0,0 +1,494 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultConfirmations is the default depth a mined combo is watched to for reorgs.
+	DefaultConfirmations = 12
+	// MinFeeBump is the minimum fee increase, in percent, that nodes accept to replace a pending transaction.
+	MinFeeBump = 10
+
+	submitPollInterval = time.Second
+	transferGas        = 21000
+)
+
+var (
+	// ErrReplaced is returned when the nonce of a submitted combo was used by another transaction.
+	ErrReplaced = errors.New("transaction replaced")
+	// ErrCancelled is returned when the cancellation of a submitted combo was mined instead of it.
+	ErrCancelled = errors.New("transaction cancelled")
+	// ErrNotPending is returned when bumping or cancelling a combo which isn't pending anymore.
+	ErrNotPending = errors.New("transaction is not pending")
+	// ErrBumpTooLow is returned when the fee bump is below `MinFeeBump`.
+	ErrBumpTooLow = fmt.Errorf("fee bump is below %d%%", MinFeeBump)
+)
+
+// WithConfirmations sets the depth a mined combo is watched to for reorgs.
+func WithConfirmations(confirmations uint64) Option {
+	return func(c *DefiClient) {
+		c.confirmations = confirmations
+	}
+}
+
+// TxStatus is the status of a submitted combo.
+type TxStatus int
+
+const (
+	// StatusPending means none of the transactions of the combo is mined.
+	StatusPending TxStatus = iota
+	// StatusMined means a transaction of the combo is mined.
+	StatusMined
+	// StatusReorged means the block the combo was mined in left the canonical chain, the combo is
+	// pending again until it is mined in another block.
+	StatusReorged
+	// StatusReplaced means the nonce of the combo was used by another transaction, either its
+	// cancellation or a transaction sent from elsewhere.
+	StatusReplaced
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusMined:
+		return "mined"
+	case StatusReorged:
+		return "reorged"
+	case StatusReplaced:
+		return "replaced"
+	default:
+		return fmt.Sprintf("TxStatus(%d)", int(s))
+	}
+}
+
+// StatusUpdate is a change of the status of a submitted combo.
+type StatusUpdate struct {
+	Status TxStatus
+	// TxHash is the transaction the update is about, e.g. the bumped transaction or the mined one.
+	TxHash TxHash
+	// BlockNumber is the block the transaction was mined in, nil unless it is mined.
+	BlockNumber *big.Int
+}
+
+// Submission is the handle of a combo submitted with `Submit`. It is tracked in the background
+// until it is mined deep enough, see `WithConfirmations`, or replaced. The tracking also ends when
+// the mining timeout expires since the last transaction was sent, or since it was mined.
+type Submission struct {
+	client  *DefiClient
+	actions *Actions
+	nonce   uint64
+
+	mu       sync.Mutex
+	fees     *Fees
+	txs      []*types.Transaction
+	cancel   *types.Transaction
+	status   TxStatus
+	receipt  *types.Receipt
+	deadline time.Time
+	result   *ExecutionResult
+	err      error
+	resolved bool
+	closed   bool
+
+	updates chan StatusUpdate
+	done    chan struct{}
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// Submit sends one transaction for all the Defi interactions, priced with `SuggestFees`, and
+// returns without waiting for it to be mined.
+func (c *DefiClient) Submit(ctx context.Context, actions *Actions) (*Submission, error) {
+	fees, err := c.SuggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.SubmitWithFees(ctx, actions, fees)
+}
+
+// SubmitWithFees sends one transaction for all the Defi interactions with given fees, and returns
+// without waiting for it to be mined. The nonce comes from the nonce manager of the client, so
+// several combos can be in flight at once.
+// If the estimation reverts, the combo isn't sent and an *ExecutionError is returned.
+func (c *DefiClient) SubmitWithFees(ctx context.Context, actions *Actions, fees *Fees) (*Submission, error) {
+	combined, err := c.combine(ctx, actions, nil)
+	if err != nil {
+		return nil, err
+	}
+	estimated, err := c.estimateCombo(ctx, combined)
+	if err != nil {
+		var execErr *ExecutionError
+		if errors.As(err, &execErr) {
+			c.locateFailure(ctx, actions, nil, execErr)
+		}
+		return nil, err
+	}
+	gasLimit, err := c.gasLimit(estimated)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := c.batchExecMsg(combined)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := c.nonces.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.signTx(nonce, msg, gasLimit, fees)
+	if err == nil {
+		err = c.conn.SendTransaction(ctx, tx)
+	}
+	if err != nil {
+		c.nonces.Release(nonce)
+		return nil, err
+	}
+
+	s := &Submission{
+		client:   c,
+		actions:  actions,
+		nonce:    nonce,
+		fees:     fees,
+		txs:      []*types.Transaction{tx},
+		deadline: c.trackingDeadline(),
+		updates:  make(chan StatusUpdate, 16),
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	s.notify(StatusUpdate{Status: StatusPending, TxHash: TxHash(tx.Hash().Hex())})
+	go s.track()
+	return s, nil
+}
+
+// signTx signs a transaction of the sender of the client.
+func (c *DefiClient) signTx(nonce uint64, msg ethereum.CallMsg, gas uint64, fees *Fees) (*types.Transaction, error) {
+	var inner types.TxData
+	if fees.IsDynamic() {
+		inner = &types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Gas:       gas,
+			To:        msg.To,
+			Value:     msg.Value,
+			Data:      msg.Data,
+		}
+	} else {
+		inner = &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: fees.GasPrice,
+			Gas:      gas,
+			To:       msg.To,
+			Value:    msg.Value,
+			Data:     msg.Data,
+		}
+	}
+	return c.opts.Signer(c.opts.From, types.NewTx(inner))
+}
+
+// trackingDeadline is the time a submission stops waiting for its transactions to be mined.
+func (c *DefiClient) trackingDeadline() time.Time {
+	if c.miningTimeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(c.miningTimeout)
+}
+
+// Nonce returns the nonce shared by all the transactions of the combo.
+func (s *Submission) Nonce() uint64 {
+	return s.nonce
+}
+
+// TxHash returns the hash of the mined transaction, or of the latest one sent if none is mined.
+func (s *Submission) TxHash() TxHash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.receipt != nil {
+		return TxHash(s.receipt.TxHash.Hex())
+	}
+	return TxHash(s.txs[len(s.txs)-1].Hash().Hex())
+}
+
+// Status returns the current status of the combo.
+func (s *Submission) Status() TxStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Updates returns the channel of the status updates, it is closed when the tracking ends.
+// Updates are dropped if the channel isn't drained, `Status` always has the latest one.
+func (s *Submission) Updates() <-chan StatusUpdate {
+	return s.updates
+}
+
+// Wait waits until the combo is mined or replaced, or the tracking ends.
+// A combo mined but reverted returns its result along with the *ExecutionError, a cancelled one
+// returns ErrCancelled and one replaced from elsewhere ErrReplaced.
+func (s *Submission) Wait(ctx context.Context) (*ExecutionResult, error) {
+	select {
+	case <-s.done:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.result, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Stop stops tracking the combo, it doesn't cancel it.
+func (s *Submission) Stop() {
+	s.stopped.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Bump re-signs the combo with the same nonce and its fees increased by `percent`, at least
+// `MinFeeBump`, so that it replaces the pending transaction.
+func (s *Submission) Bump(ctx context.Context, percent uint64) error {
+	if percent < MinFeeBump {
+		return ErrBumpTooLow
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != StatusPending && s.status != StatusReorged {
+		return ErrNotPending
+	}
+	latest := s.txs[len(s.txs)-1]
+	msg := ethereum.CallMsg{To: latest.To(), Value: latest.Value(), Data: latest.Data()}
+	return s.replace(ctx, msg, latest.Gas(), percent, func(tx *types.Transaction) {
+		s.txs = append(s.txs, tx)
+	})
+}
+
+// Cancel replaces the pending combo with a zero-value transfer from the sender to itself, with
+// the same nonce and fees increased by `MinFeeBump`. Once the transfer is mined the combo is
+// replaced and `Wait` returns ErrCancelled. Cancel can be called again to bump the transfer.
+func (s *Submission) Cancel(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != StatusPending && s.status != StatusReorged {
+		return ErrNotPending
+	}
+	from := s.client.opts.From
+	msg := ethereum.CallMsg{To: &from, Value: big.NewInt(0)}
+	return s.replace(ctx, msg, transferGas, MinFeeBump, func(tx *types.Transaction) {
+		s.cancel = tx
+	})
+}
+
+// replace signs and sends a transaction with the nonce of the combo and bumped fees.
+// It must be called with the lock held.
+func (s *Submission) replace(ctx context.Context, msg ethereum.CallMsg, gas uint64, percent uint64, sent func(*types.Transaction)) error {
+	fees := bumpFees(s.fees, percent)
+	tx, err := s.client.signTx(s.nonce, msg, gas, fees)
+	if err != nil {
+		return err
+	}
+	if err := s.client.conn.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	sent(tx)
+	s.fees = fees
+	s.deadline = s.client.trackingDeadline()
+	s.notifyLocked(StatusUpdate{Status: s.status, TxHash: TxHash(tx.Hash().Hex())})
+	return nil
+}
+
+// bumpFees increases the fees by `percent`, each fee grows by at least 1 wei.
+func bumpFees(fees *Fees, percent uint64) *Fees {
+	bump := func(fee *big.Int) *big.Int {
+		if fee == nil {
+			return nil
+		}
+		bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+percent))
+		bumped.Div(bumped, big.NewInt(100))
+		if bumped.Cmp(fee) <= 0 {
+			bumped.Add(fee, big.NewInt(1))
+		}
+		return bumped
+	}
+	return &Fees{GasPrice: bump(fees.GasPrice), GasFeeCap: bump(fees.GasFeeCap), GasTipCap: bump(fees.GasTipCap)}
+}
+
+// track polls the node until the combo is mined deep enough or replaced, or the deadline passes.
+func (s *Submission) track() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.updates)
+		s.mu.Unlock()
+	}()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(submitPollInterval)
+	defer ticker.Stop()
+	for {
+		if s.poll(ctx) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			s.resolve(nil, ctx.Err())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll checks the combo once, it returns true when the tracking is over.
+func (s *Submission) poll(ctx context.Context) bool {
+	s.mu.Lock()
+	receipt := s.receipt
+	deadline := s.deadline
+	candidates := make([]*types.Transaction, 0, len(s.txs)+1)
+	if s.cancel != nil {
+		candidates = append(candidates, s.cancel)
+	}
+	for i := len(s.txs) - 1; i >= 0; i-- {
+		candidates = append(candidates, s.txs[i])
+	}
+	s.mu.Unlock()
+
+	if receipt != nil {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return true
+		}
+		return s.watchMined(ctx, receipt)
+	}
+
+	// The nonce is read before the receipts, so a consumed nonce without any receipt means
+	// the combo was replaced from elsewhere.
+	nonce, nonceErr := s.client.conn.NonceAt(ctx, s.client.opts.From, nil)
+	for _, tx := range candidates {
+		receipt, err := s.client.conn.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			continue
+		}
+		s.mined(ctx, tx, receipt)
+		return false
+	}
+	if nonceErr == nil && nonce > s.nonce {
+		// The nonces handed out after the combo may have been used from elsewhere as well.
+		s.client.nonces.Reset()
+		s.mu.Lock()
+		s.status = StatusReplaced
+		s.notifyLocked(StatusUpdate{Status: StatusReplaced})
+		s.mu.Unlock()
+		s.resolve(nil, ErrReplaced)
+		return true
+	}
+	if !deadline.IsZero() && time.Now().After(deadline) {
+		// The transaction may have been dropped, leaving a gap the later nonces are stuck behind.
+		s.client.nonces.Reset()
+		s.resolve(nil, fmt.Errorf("waiting for tx %s to be mined: %w", s.TxHash(), context.DeadlineExceeded))
+		return true
+	}
+	return false
+}
+
+// mined records the receipt of a mined transaction of the combo, or of its cancellation.
+func (s *Submission) mined(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) {
+	s.mu.Lock()
+	cancelled := s.cancel != nil && tx.Hash() == s.cancel.Hash()
+	s.receipt = receipt
+	s.status = StatusMined
+	s.deadline = s.client.trackingDeadline()
+	if cancelled {
+		s.status = StatusReplaced
+	}
+	s.notifyLocked(StatusUpdate{Status: s.status, TxHash: TxHash(tx.Hash().Hex()), BlockNumber: receipt.BlockNumber})
+	s.mu.Unlock()
+
+	if cancelled {
+		s.resolve(nil, ErrCancelled)
+		return
+	}
+	result, err := s.client.executionResult(ctx, tx, receipt)
+	if err != nil {
+		s.resolve(nil, err)
+		return
+	}
+	if receipt.Status != 1 {
+		execErr, err := s.client.ReplayFailedTransaction(ctx, s.actions, tx.Hash())
+		if err != nil {
+			s.resolve(result, fmt.Errorf("tx receipt status is not 1, indicating a failure occurred: %v", err))
+			return
+		}
+		s.resolve(result, execErr)
+		return
+	}
+	s.resolve(result, nil)
+}
+
+// watchMined checks that the mined transaction is still in the canonical chain, it returns true
+// once it is deep enough.
+func (s *Submission) watchMined(ctx context.Context, receipt *types.Receipt) bool {
+	current, err := s.client.conn.TransactionReceipt(ctx, receipt.TxHash)
+	if errors.Is(err, ethereum.NotFound) || (err == nil && current.BlockHash != receipt.BlockHash) {
+		s.mu.Lock()
+		s.receipt = nil
+		s.status = StatusReorged
+		s.deadline = s.client.trackingDeadline()
+		s.notifyLocked(StatusUpdate{Status: StatusReorged, TxHash: TxHash(receipt.TxHash.Hex())})
+		s.mu.Unlock()
+		return false
+	}
+	if err != nil {
+		return false
+	}
+	head, err := s.client.conn.BlockNumber(ctx)
+	if err != nil {
+		return false
+	}
+	return head+1 >= receipt.BlockNumber.Uint64()+s.client.confirmations
+}
+
+// resolve sets the outcome returned by `Wait`, the first outcome wins.
+func (s *Submission) resolve(result *ExecutionResult, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resolved {
+		return
+	}
+	s.resolved = true
+	s.result = result
+	s.err = err
+	close(s.done)
+}
+
+func (s *Submission) notify(update StatusUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifyLocked(update)
+}
+
+// notifyLocked sends a status update without blocking, it must be called with the lock held.
+func (s *Submission) notifyLocked(update StatusUpdate) {
+	if s.closed {
+		return
+	}
+	select {
+	case s.updates <- update:
+	default:
+	}
+}
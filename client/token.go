@@ -0,0 +1,285 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rafaelescrich/go-defi-1/binding/erc20"
+)
+
+// MainnetChainID is the chain ID of the Ethereum mainnet.
+const MainnetChainID uint64 = 1
+
+// ErrUnknownToken is returned when a token isn't in the registry.
+var ErrUnknownToken = errors.New("unknown token")
+
+// Token is an ERC-20 token. Ether is the token with the zero address, the protocols that only
+// take ERC-20 tokens use the WETH of the network in its place.
+type Token struct {
+	Address  common.Address `json:"address" yaml:"address"`
+	Symbol   string         `json:"symbol" yaml:"symbol"`
+	Decimals uint8          `json:"decimals" yaml:"decimals"`
+	ChainID  uint64         `json:"chainId" yaml:"chainId"`
+}
+
+// IsETH returns true if the token is ether.
+func (t Token) IsETH() bool {
+	return t.Address == (common.Address{})
+}
+
+func (t Token) String() string {
+	return t.Symbol
+}
+
+// The tokens known on mainnet.
+var (
+	// ETH is ether.
+	ETH = Token{Symbol: "ETH", Decimals: 18, ChainID: MainnetChainID}
+	// WETH is wrapped ether.
+	WETH = mainnetToken("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", "WETH", 18)
+	// BAT is basic attention token.
+	BAT = mainnetToken("0x0d8775f648430679a709e98d2b0cb6250d2887ef", "BAT", 18)
+	// COMP is the governance token for Compound.
+	COMP = mainnetToken("0xc00e94cb662c3520282e6f5717214004a7f26888", "COMP", 18)
+	// DAI is the stable coin.
+	DAI = mainnetToken("0x6b175474e89094c44da98b954eedeac495271d0f", "DAI", 18)
+	// REP is Augur reputation token.
+	REP = mainnetToken("0x1985365e9f78359a9B6AD760e32412f4a445E862", "REP", 18)
+	// SAI is Single Collateral DAI.
+	SAI = mainnetToken("0x89d24A6b4CcB1B6fAA2625fE562bDD9a23260359", "SAI", 18)
+	// UNI is the governance token for Uniswap.
+	UNI = mainnetToken("0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984", "UNI", 18)
+	// USDC is the stable coin by Circle.
+	USDC = mainnetToken("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "USDC", 6)
+	// USDT is the stable coin.
+	USDT = mainnetToken("0xdac17f958d2ee523a2206206994597c13d831ec7", "USDT", 6)
+	// WBTC is wrapped BTC.
+	WBTC = mainnetToken("0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599", "WBTC", 8)
+	// ZRX is the utility token for 0x.
+	ZRX = mainnetToken("0xE41d2489571d322189246DaFA5ebDe1F4699F498", "ZRX", 18)
+	// BUSD is the Binance USD token.
+	BUSD = mainnetToken("0x4Fabb145d64652a948d72533023f6E7A623C7C53", "BUSD", 18)
+	// YFI is the yearn governance token.
+	YFI = mainnetToken("0x0bc529c00C6401aEF6D220BE8C6Ea1667F6Ad93e", "YFI", 18)
+	// AAVE is the Aave governance token.
+	AAVE = mainnetToken("0x7Fc66500c84A76Ad7e9c93437bFc5Ac33E2DDaE9", "AAVE", 18)
+
+	// cETH, cDAI and cUSDC are the tokens that user receive after supplying to Compound.
+	cETH  = mainnetToken("0x4ddc2d193948926d02f9b1fe9e1daa0718270ed5", "cETH", 8)
+	cDAI  = mainnetToken("0x5d3a536e4d6dbd6114cc1ead35777bab948e3643", "cDAI", 8)
+	cUSDC = mainnetToken("0x39aa39c021dfbae8fac545936693ac917d5e7563", "cUSDC", 8)
+	// yWETH is the token that user receive after deposit ether into Yearn.
+	yWETH = mainnetToken("0xe1237aA7f535b0CC33Fd973D66cBf830354D16c7", "yWETH", 18)
+)
+
+func mainnetToken(addr string, symbol string, decimals uint8) Token {
+	return Token{Address: common.HexToAddress(addr), Symbol: symbol, Decimals: decimals, ChainID: MainnetChainID}
+}
+
+// mainnetTokens are the tokens of the default registry.
+var mainnetTokens = []Token{
+	ETH, WETH, BAT, COMP, DAI, REP, SAI, UNI, USDC, USDT, WBTC, ZRX, BUSD, YFI, AAVE,
+	cETH, cDAI, cUSDC, yWETH,
+}
+
+// TokenRegistry resolves the tokens of one chain by symbol or address.
+// It is safe for concurrent use.
+type TokenRegistry struct {
+	mu        sync.RWMutex
+	chainID   uint64
+	bySymbol  map[string]Token
+	byAddress map[common.Address]Token
+}
+
+// NewTokenRegistry creates a registry of the tokens of the chain `chainID`, ether is always known.
+func NewTokenRegistry(chainID uint64, tokens ...Token) *TokenRegistry {
+	r := &TokenRegistry{
+		chainID:   chainID,
+		bySymbol:  make(map[string]Token),
+		byAddress: make(map[common.Address]Token),
+	}
+	eth := ETH
+	eth.ChainID = chainID
+	r.Add(eth)
+	r.Add(tokens...)
+	return r
+}
+
+// weth returns wrapped ether on the network of the client.
+func (c *DefiClient) weth() Token {
+	weth, _ := c.network.WETH()
+	return weth
+}
+
+// erc20Address returns the address of a token as an ERC-20, i.e. the WETH of the network for ether.
+func (c *DefiClient) erc20Address(t Token) common.Address {
+	if t.IsETH() {
+		return c.weth().Address
+	}
+	return t.Address
+}
+
+// DefaultTokenRegistry returns a registry of the mainnet tokens known by this package.
+func DefaultTokenRegistry() *TokenRegistry {
+	return NewTokenRegistry(MainnetChainID, mainnetTokens...)
+}
+
+// WithTokenRegistry sets the registry the client resolves tokens with.
+func WithTokenRegistry(registry *TokenRegistry) Option {
+	return func(c *DefiClient) {
+		c.tokens = registry
+	}
+}
+
+// ChainID returns the chain the registry holds the tokens of.
+func (r *TokenRegistry) ChainID() uint64 {
+	return r.chainID
+}
+
+// Add adds tokens to the registry, a token replaces a known one with the same symbol or address.
+// Tokens of other chains are ignored, a zero chain ID is taken as the chain of the registry.
+func (r *TokenRegistry) Add(tokens ...Token) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range tokens {
+		if token.ChainID == 0 {
+			token.ChainID = r.chainID
+		}
+		if token.ChainID != r.chainID {
+			continue
+		}
+		symbol := strings.ToUpper(token.Symbol)
+		// The replaced tokens aren't known by their other key anymore.
+		if old, ok := r.bySymbol[symbol]; ok {
+			delete(r.byAddress, old.Address)
+		}
+		if old, ok := r.byAddress[token.Address]; ok {
+			delete(r.bySymbol, strings.ToUpper(old.Symbol))
+		}
+		r.bySymbol[symbol] = token
+		r.byAddress[token.Address] = token
+	}
+}
+
+// BySymbol returns the token with the given symbol, the lookup is case insensitive.
+func (r *TokenRegistry) BySymbol(symbol string) (Token, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	token, ok := r.bySymbol[strings.ToUpper(symbol)]
+	return token, ok
+}
+
+// ByAddress returns the token at the given address.
+func (r *TokenRegistry) ByAddress(addr common.Address) (Token, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	token, ok := r.byAddress[addr]
+	return token, ok
+}
+
+// Resolve returns the token named by a symbol or a hex address.
+func (r *TokenRegistry) Resolve(symbolOrAddress string) (Token, error) {
+	if common.IsHexAddress(symbolOrAddress) {
+		if token, ok := r.ByAddress(common.HexToAddress(symbolOrAddress)); ok {
+			return token, nil
+		}
+	} else if token, ok := r.BySymbol(symbolOrAddress); ok {
+		return token, nil
+	}
+	return Token{}, fmt.Errorf("%w: %s", ErrUnknownToken, symbolOrAddress)
+}
+
+// Tokens returns all the tokens of the registry.
+func (r *TokenRegistry) Tokens() []Token {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tokens := make([]Token, 0, len(r.byAddress))
+	for _, token := range r.byAddress {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// Fetch reads the symbol and decimals of the token at `addr` on chain and adds it to the registry.
+// A token already in the registry is returned without any call.
+func (r *TokenRegistry) Fetch(ctx context.Context, conn *ethclient.Client, addr common.Address) (Token, error) {
+	if token, ok := r.ByAddress(addr); ok {
+		return token, nil
+	}
+	contract, err := erc20.NewErc20(addr, conn)
+	if err != nil {
+		return Token{}, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	symbol, err := contract.Symbol(opts)
+	if err != nil {
+		return Token{}, fmt.Errorf("Error getting symbol of %s: %w", addr.Hex(), err)
+	}
+	decimals, err := contract.Decimals(opts)
+	if err != nil {
+		return Token{}, fmt.Errorf("Error getting decimals of %s: %w", addr.Hex(), err)
+	}
+	token := Token{Address: addr, Symbol: symbol, Decimals: decimals, ChainID: r.chainID}
+	r.Add(token)
+	return token, nil
+}
+
+// tokenList is the format of the token lists, see https://tokenlists.org.
+type tokenList struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// LoadJSON adds the tokens read from a JSON array of tokens, or from a token list, i.e. an
+// object with a `tokens` array. Only the tokens of the chain of the registry are added.
+func (r *TokenRegistry) LoadJSON(reader io.Reader) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	var tokens []Token
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var list tokenList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		tokens = list.Tokens
+	} else if err := json.Unmarshal(data, &tokens); err != nil {
+		return err
+	}
+	r.Add(tokens...)
+	return nil
+}
+
+// LoadFile adds the tokens of a JSON file, see `LoadJSON`.
+func (r *TokenRegistry) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.LoadJSON(f)
+}
+
+// Tokens returns the token registry of the client.
+func (c *DefiClient) Tokens() *TokenRegistry {
+	return c.tokens
+}
+
+// Token resolves a token by symbol or hex address, an address unknown to the registry is
+// fetched on chain.
+func (c *DefiClient) Token(ctx context.Context, symbolOrAddress string) (Token, error) {
+	token, err := c.tokens.Resolve(symbolOrAddress)
+	if err == nil || !common.IsHexAddress(symbolOrAddress) {
+		return token, err
+	}
+	return c.tokens.Fetch(ctx, c.conn, common.HexToAddress(symbolOrAddress))
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNoTracer is returned when a call needs to be traced and the client has no RPC client to
+// trace it with, see `WithRPCClient`.
+var ErrNoTracer = errors.New("no RPC client to trace calls")
+
+// transferTopic is the topic of the ERC20 Transfer event.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// WithRPCClient sets the RPC client of the connection of the client. It is used for the call
+// ethclient has no method for: `debug_traceCall`, which needs a node with the debug namespace.
+func WithRPCClient(rpcClient *rpc.Client) Option {
+	return func(c *DefiClient) {
+		c.rpcClient = rpcClient
+	}
+}
+
+// callFrame is a call of a trace by the callTracer of geth.
+type callFrame struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Value   *hexutil.Big   `json:"value"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Error   string         `json:"error"`
+	Calls   []callFrame    `json:"calls"`
+	Logs    []callLog      `json:"logs"`
+}
+
+// callLog is a log emitted by a call, traced with the `withLog` option of the callTracer.
+type callLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// traceCall traces `msg` on the state of `blockNum` with the callTracer, nil means the latest block.
+func (c *DefiClient) traceCall(ctx context.Context, msg ethereum.CallMsg, blockNum *big.Int) (*callFrame, error) {
+	if c.rpcClient == nil {
+		return nil, ErrNoTracer
+	}
+	config := map[string]interface{}{
+		"tracer":       "callTracer",
+		"tracerConfig": map[string]interface{}{"withLog": true},
+	}
+	frame := new(callFrame)
+	if err := c.rpcClient.CallContext(ctx, frame, "debug_traceCall", callArg(msg), blockArg(blockNum), config); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// callArg is the JSON-RPC form of a call message, as ethclient sends it.
+func callArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	return arg
+}
+
+func blockArg(blockNum *big.Int) string {
+	if blockNum == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(blockNum)
+}
+
+// balanceDeltas sums the ether and the ERC20 Transfer events sent to and from `account` in the
+// calls of `frame` that didn't revert, keyed by token address, ether by the zero address.
+func balanceDeltas(frame *callFrame, account common.Address) map[common.Address]*big.Int {
+	deltas := make(map[common.Address]*big.Int)
+	add := func(token common.Address, amount *big.Int, sign int) {
+		delta, ok := deltas[token]
+		if !ok {
+			delta = big.NewInt(0)
+			deltas[token] = delta
+		}
+		if sign < 0 {
+			delta.Sub(delta, amount)
+		} else {
+			delta.Add(delta, amount)
+		}
+	}
+
+	var walk func(frame *callFrame)
+	walk = func(frame *callFrame) {
+		if frame.Error != "" {
+			return
+		}
+		// A delegate call carries the value of its caller, it doesn't move ether.
+		if frame.Type != "DELEGATECALL" && frame.Value != nil && frame.Value.ToInt().Sign() > 0 {
+			if frame.From == account {
+				add(common.Address{}, frame.Value.ToInt(), -1)
+			}
+			if frame.To == account {
+				add(common.Address{}, frame.Value.ToInt(), 1)
+			}
+		}
+		for _, log := range frame.Logs {
+			if len(log.Topics) != 3 || log.Topics[0] != transferTopic {
+				continue
+			}
+			amount := new(big.Int).SetBytes(log.Data)
+			if common.BytesToAddress(log.Topics[1].Bytes()) == account {
+				add(log.Address, amount, -1)
+			}
+			if common.BytesToAddress(log.Topics[2].Bytes()) == account {
+				add(log.Address, amount, 1)
+			}
+		}
+		for i := range frame.Calls {
+			walk(&frame.Calls[i])
+		}
+	}
+	walk(frame)
+
+	for token, delta := range deltas {
+		if delta.Sign() == 0 {
+			delete(deltas, token)
+		}
+	}
+	return deltas
+}
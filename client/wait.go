@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultMiningTimeout is the default maximum time to wait for a transaction to be mined.
+const DefaultMiningTimeout = 5 * time.Minute
+
+// WithMiningTimeout sets the maximum time to wait for a transaction to be mined, on top of the
+// deadline of the context passed to the call. Zero means no timeout besides the context.
+func WithMiningTimeout(timeout time.Duration) Option {
+	return func(c *DefiClient) {
+		c.miningTimeout = timeout
+	}
+}
+
+// waitMined waits for `tx` to be mined until the context is done or the mining timeout expires.
+func (c *DefiClient) waitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	if c.miningTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.miningTimeout)
+		defer cancel()
+	}
+	receipt, err := bind.WaitMined(ctx, c.conn, tx)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for tx %s to be mined: %w", tx.Hash().Hex(), err)
+	}
+	return receipt, nil
+}
+
+// callOpts returns the options of the read calls, pinned to `blockNum`, nil means the latest block.
+func (c *DefiClient) callOpts(ctx context.Context, blockNum *big.Int) *bind.CallOpts {
+	return &bind.CallOpts{From: c.opts.From, BlockNumber: blockNum, Context: ctx}
+}
@@ -0,0 +1,151 @@
+// Command gasbacktest scores gas oracle strategies against past blocks.
+//
+// For every block of the range, each strategy suggests the gas price of a transaction included
+// in the next block, and the suggestion is compared to the prices paid in that block.
+//
+//	gasbacktest -rpc http://127.0.0.1:8545 -from 11500000 -to 11500100 \
+//		-strategies percentile:20:50,feehistory:20:50,fixed:20000000000 -format csv
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func main() {
+	var (
+		rpcURL     = flag.String("rpc", "http://127.0.0.1:8545", "RPC URL of an archive node")
+		from       = flag.Uint64("from", 0, "first block the strategies suggest a price after")
+		to         = flag.Uint64("to", 0, "last block the strategies suggest a price after")
+		strategies = flag.String("strategies", "percentile:20:50,feehistory:20:50", "comma separated strategies: percentile:<blocks>:<percentile>, feehistory:<blocks>:<percentile>, fixed:<wei>")
+		format     = flag.String("format", "csv", "output format: csv or json")
+		out        = flag.String("out", "", "output file, stdout if empty")
+	)
+	flag.Parse()
+
+	if *to < *from {
+		log.Fatalf("Bad block range: %d > %d", *from, *to)
+	}
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("Unknown format: %v", *format)
+	}
+
+	ctx := context.Background()
+	conn, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to ETH: %v", err)
+	}
+	list, err := parseStrategies(conn, *strategies)
+	if err != nil {
+		log.Fatalf("Failed to parse strategies: %v", err)
+	}
+
+	scores, err := backtest(ctx, conn, list, *from, *to)
+	if err != nil {
+		log.Fatalf("Failed to backtest: %v", err)
+	}
+
+	if err := writeScores(*out, *format, scores); err != nil {
+		log.Fatalf("Failed to write results: %v", err)
+	}
+}
+
+// writeScores writes the scores in `format` to the file `path`, or to stdout if it is empty. The
+// file is closed before returning, so that a failed write doesn't leave it open.
+func writeScores(path string, format string, scores []score) (err error) {
+	w := io.Writer(os.Stdout)
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = f
+	}
+	if format == "json" {
+		return writeJSON(w, scores)
+	}
+	return writeCSV(w, scores)
+}
+
+// backtest scores each strategy on the blocks in [from, to].
+func backtest(ctx context.Context, conn *ethclient.Client, list []strategy, from uint64, to uint64) ([]score, error) {
+	scorers := make([]*scorer, len(list))
+	for i, s := range list {
+		scorers[i] = newScorer(s.name)
+	}
+
+	for number := from; number <= to; number++ {
+		blockNum := new(big.Int).SetUint64(number)
+		prices, err := blockPrices(ctx, conn, new(big.Int).Add(blockNum, big.NewInt(1)))
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %v", number+1, err)
+		}
+		if len(prices) == 0 {
+			log.Printf("Skipping empty block %d", number+1)
+			continue
+		}
+		for i, s := range list {
+			suggestion, err := s.oracle.SuggestGasPrice(ctx, blockNum)
+			if err != nil {
+				log.Printf("Strategy %s failed at block %d: %v", s.name, number, err)
+				scorers[i].errors++
+				continue
+			}
+			scorers[i].add(suggestion, prices)
+		}
+	}
+
+	scores := make([]score, len(scorers))
+	for i, s := range scorers {
+		scores[i] = s.score()
+	}
+	return scores, nil
+}
+
+func writeJSON(w io.Writer, scores []score) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(scores)
+}
+
+func writeCSV(w io.Writer, scores []score) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"strategy", "blocks", "errors", "avg_suggested_gwei", "avg_overpay_gwei",
+		"inclusion_probability", "percent_blocks_exceeded",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, s := range scores {
+		record := []string{
+			s.Strategy,
+			strconv.Itoa(s.Blocks),
+			strconv.Itoa(s.Errors),
+			strconv.FormatFloat(s.AvgSuggestedGwei, 'f', 4, 64),
+			strconv.FormatFloat(s.AvgOverpayGwei, 'f', 4, 64),
+			strconv.FormatFloat(s.InclusionProbability, 'f', 4, 64),
+			strconv.FormatFloat(s.PercentBlocksExceeded, 'f', 2, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
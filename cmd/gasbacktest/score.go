@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rafaelescrich/go-defi-1/client"
+)
+
+var gwei = big.NewFloat(1e9)
+
+// score is the backtest result of one strategy.
+type score struct {
+	Strategy string `json:"strategy"`
+	// Blocks is the number of blocks the strategy was scored on, empty blocks are skipped.
+	Blocks int `json:"blocks"`
+	// Errors is the number of blocks the oracle failed to suggest a price for.
+	Errors int `json:"errors"`
+	// AvgSuggestedGwei is the average suggested gas price.
+	AvgSuggestedGwei float64 `json:"avgSuggestedGwei"`
+	// AvgOverpayGwei is the average of the suggested price minus the lowest price of the next
+	// block, over the blocks where the suggestion was at least that lowest price.
+	AvgOverpayGwei float64 `json:"avgOverpayGwei"`
+	// InclusionProbability is a proxy of the chance to be included in the next block: the average
+	// share of its transactions that paid no more than the suggestion.
+	InclusionProbability float64 `json:"inclusionProbability"`
+	// PercentBlocksExceeded is the percent of blocks whose lowest price the suggestion reached.
+	PercentBlocksExceeded float64 `json:"percentBlocksExceeded"`
+}
+
+// scorer accumulates the suggestions of a strategy against the blocks they were made for.
+type scorer struct {
+	name      string
+	blocks    int
+	errors    int
+	exceeded  int
+	suggested *big.Int
+	overpay   *big.Int
+	included  float64
+}
+
+func newScorer(name string) *scorer {
+	return &scorer{name: name, suggested: big.NewInt(0), overpay: big.NewInt(0)}
+}
+
+// add scores a suggestion against the sorted effective gas prices of the next block.
+func (s *scorer) add(suggestion *big.Int, prices []*big.Int) {
+	if len(prices) == 0 {
+		return
+	}
+	s.blocks++
+	s.suggested.Add(s.suggested, suggestion)
+
+	if suggestion.Cmp(prices[0]) >= 0 {
+		s.exceeded++
+		s.overpay.Add(s.overpay, new(big.Int).Sub(suggestion, prices[0]))
+	}
+	outbid := sort.Search(len(prices), func(i int) bool {
+		return prices[i].Cmp(suggestion) > 0
+	})
+	s.included += float64(outbid) / float64(len(prices))
+}
+
+func (s *scorer) score() score {
+	result := score{Strategy: s.name, Blocks: s.blocks, Errors: s.errors}
+	if s.blocks == 0 {
+		return result
+	}
+	result.AvgSuggestedGwei = average(s.suggested, s.blocks)
+	if s.exceeded > 0 {
+		result.AvgOverpayGwei = average(s.overpay, s.exceeded)
+	}
+	result.InclusionProbability = s.included / float64(s.blocks)
+	result.PercentBlocksExceeded = 100 * float64(s.exceeded) / float64(s.blocks)
+	return result
+}
+
+// average returns the average in gwei of a sum of wei.
+func average(sum *big.Int, n int) float64 {
+	avg := new(big.Float).Quo(new(big.Float).SetInt(sum), big.NewFloat(float64(n)))
+	f, _ := avg.Quo(avg, gwei).Float64()
+	return f
+}
+
+// blockPrices returns the sorted effective gas prices paid in a block, as the oracles of the
+// client compute them.
+func blockPrices(ctx context.Context, conn *ethclient.Client, number *big.Int) ([]*big.Int, error) {
+	block, err := conn.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return client.EffectiveGasPrices(block), nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gweis returns amounts of gwei in wei.
+func gweis(amounts ...float64) []*big.Int {
+	wei := make([]*big.Int, len(amounts))
+	for i, amount := range amounts {
+		wei[i], _ = new(big.Float).Mul(big.NewFloat(amount), gwei).Int(nil)
+	}
+	return wei
+}
+
+func TestScore(t *testing.T) {
+	prices := gweis(1, 2, 3, 4)
+	tests := []struct {
+		name        string
+		suggestions []*big.Int
+		prices      []*big.Int
+		want        score
+	}{
+		{
+			name: "no blocks",
+			want: score{Strategy: "no blocks"},
+		},
+		{
+			name:        "empty block",
+			suggestions: gweis(1),
+			want:        score{Strategy: "empty block"},
+		},
+		{
+			name:        "lowest price",
+			suggestions: gweis(1),
+			prices:      prices,
+			want:        score{Strategy: "lowest price", Blocks: 1, AvgSuggestedGwei: 1, InclusionProbability: 0.25, PercentBlocksExceeded: 100},
+		},
+		{
+			name:        "above all",
+			suggestions: gweis(5),
+			prices:      prices,
+			want:        score{Strategy: "above all", Blocks: 1, AvgSuggestedGwei: 5, AvgOverpayGwei: 4, InclusionProbability: 1, PercentBlocksExceeded: 100},
+		},
+		{
+			name:        "below all",
+			suggestions: gweis(0.5),
+			prices:      prices,
+			want:        score{Strategy: "below all", Blocks: 1, AvgSuggestedGwei: 0.5},
+		},
+		{
+			name:        "ties",
+			suggestions: gweis(2),
+			prices:      gweis(1, 2, 2, 3),
+			want:        score{Strategy: "ties", Blocks: 1, AvgSuggestedGwei: 2, AvgOverpayGwei: 1, InclusionProbability: 0.75, PercentBlocksExceeded: 100},
+		},
+		{
+			// The overpay is averaged over the blocks the suggestion reached only.
+			name:        "mixed",
+			suggestions: gweis(5, 0.5, 3, 1),
+			prices:      prices,
+			want:        score{Strategy: "mixed", Blocks: 4, AvgSuggestedGwei: 2.375, AvgOverpayGwei: 2, InclusionProbability: 0.5, PercentBlocksExceeded: 75},
+		},
+	}
+	for _, test := range tests {
+		s := newScorer(test.name)
+		for _, suggestion := range test.suggestions {
+			s.add(suggestion, test.prices)
+		}
+		got := s.score()
+		if got.Strategy != test.want.Strategy || got.Blocks != test.want.Blocks || got.Errors != test.want.Errors ||
+			!near(got.AvgSuggestedGwei, test.want.AvgSuggestedGwei) ||
+			!near(got.AvgOverpayGwei, test.want.AvgOverpayGwei) ||
+			!near(got.InclusionProbability, test.want.InclusionProbability) ||
+			!near(got.PercentBlocksExceeded, test.want.PercentBlocksExceeded) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestAverage(t *testing.T) {
+	tests := []struct {
+		sum  *big.Int
+		n    int
+		want float64
+	}{
+		{big.NewInt(0), 1, 0},
+		{gweis(3)[0], 2, 1.5},
+		{big.NewInt(1), 1, 1e-9},
+		{gweis(1e6)[0], 4, 250000},
+	}
+	for _, test := range tests {
+		if got := average(test.sum, test.n); !near(got, test.want) {
+			t.Errorf("Expected an average of %v for %v over %d, got %v", test.want, test.sum, test.n, got)
+		}
+	}
+}
+
+func TestWriteScores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.csv")
+	scores := []score{{Strategy: "fixed:1", Blocks: 2, InclusionProbability: 0.5, PercentBlocksExceeded: 50}}
+	if err := writeScores(path, "csv", scores); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "fixed:1,2,0,0.0000,0.0000,0.5000,50.00\n"; !strings.HasSuffix(string(out), want) {
+		t.Errorf("Expected a row %q, got %q", want, out)
+	}
+	if err := writeScores(filepath.Join(path, "scores.csv"), "csv", scores); err == nil {
+		t.Errorf("Expected an error creating an output in a file")
+	}
+}
+
+func near(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rafaelescrich/go-defi-1/client"
+)
+
+// strategy is a named gas oracle under test.
+type strategy struct {
+	name   string
+	oracle client.GasOracle
+}
+
+// parseStrategies parses a comma separated list of strategies:
+//
+//	percentile:<blocks>:<percentile>  e.g. percentile:20:50
+//	feehistory:<blocks>:<percentile>  e.g. feehistory:10:60
+//	fixed:<wei>                       e.g. fixed:20000000000
+func parseStrategies(conn *ethclient.Client, list string) ([]strategy, error) {
+	strategies := make([]strategy, 0)
+	for _, spec := range strings.Split(list, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		oracle, err := parseStrategy(conn, spec)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %q: %v", spec, err)
+		}
+		strategies = append(strategies, strategy{name: spec, oracle: oracle})
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("no strategy given")
+	}
+	return strategies, nil
+}
+
+func parseStrategy(conn *ethclient.Client, spec string) (client.GasOracle, error) {
+	parts := strings.Split(spec, ":")
+	switch parts[0] {
+	case "percentile", "feehistory":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("want %s:<blocks>:<percentile>", parts[0])
+		}
+		blocks, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		percentile, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		if parts[0] == "percentile" {
+			return client.NewPercentileOracle(conn, blocks, percentile), nil
+		}
+		return client.NewFeeHistoryOracle(conn, blocks, percentile), nil
+	case "fixed":
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("want fixed:<wei>")
+		}
+		price, ok := new(big.Int).SetString(parts[1], 10)
+		if !ok {
+			return nil, fmt.Errorf("bad price %q", parts[1])
+		}
+		return &client.FixedOracle{Price: price}, nil
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", parts[0])
+	}
+}
@@ -1,13 +1,26 @@
 module github.com/rafaelescrich/go-defi-1
 
-go 1.13
+go 1.17
 
 require (
 	github.com/524119574/go_defi v0.0.0-20201109025938-37a42350115d
-	github.com/ethereum/go-ethereum v1.9.23
-	github.com/influxdata/influxdb v1.2.3-0.20180221223340-01288bdb0883
+	github.com/ethereum/go-ethereum v1.10.26
+	github.com/influxdata/influxdb v1.8.3
 	github.com/jinzhu/copier v0.0.0-20201025035756-632e723a6687
-	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
-	golang.org/x/text v0.3.3
-	golang.org/x/tools v0.0.0-20201105220310-78b158585360
+	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
+	golang.org/x/text v0.3.7
+	golang.org/x/tools v0.1.8-0.20211029000441-d6a9af8af023
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/deckarep/golang-set v1.8.0 // indirect
+	github.com/go-stack/stack v1.8.0 // indirect
+	github.com/google/uuid v1.2.0 // indirect
+	github.com/gorilla/websocket v1.4.2 // indirect
+	github.com/rjeczalik/notify v0.9.1 // indirect
+	github.com/shirou/gopsutil v3.21.4-0.20210419000835-c7a38de76ee5+incompatible // indirect
+	github.com/tklauser/go-sysconf v0.3.5 // indirect
+	github.com/tklauser/numcpus v0.2.2 // indirect
+	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
 )